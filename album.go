@@ -0,0 +1,175 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// defaultUploadConcurrency is the number of parallel uploads used by
+// [Client.UploadMany] when UploadManyOpts.Concurrency is 0.
+const defaultUploadConcurrency = 4
+
+// ErrTooManyAttachments is returned by [Client.SendAlbum] when more than
+// [MaxAttachmentsPerMessage] sources are given.
+var ErrTooManyAttachments = errors.New("too many attachments for a single message")
+
+// UploadSource describes a single file for [Client.UploadMany].
+// Exactly one of Reader, Path or URL should be set; they are checked in that order.
+type UploadSource struct {
+	// Type selects the upload kind. UploadImage uses [Client.UploadPhoto];
+	// other types use [Client.UploadMedia].
+	Type UploadType
+	// Filename is sent with Reader. Ignored for Path and URL.
+	Filename string
+	// Reader provides the file content.
+	Reader io.Reader
+	// Path is a local file path, or a file name within FS when FS is set.
+	Path string
+	// FS, if set, is the file system Path is opened from (e.g. an [embed.FS]).
+	FS fs.FS
+	// URL is a remote http or https URL.
+	URL string
+}
+
+// UploadResult is the outcome of uploading one [UploadSource].
+type UploadResult struct {
+	// Attachment is ready to be used in [NewMessageBody.Attachments].
+	// Only valid when Err is nil.
+	Attachment AttachmentRequest
+	// Err is the upload error, if any.
+	Err error
+}
+
+// UploadManyOpts holds optional parameters for [Client.UploadMany].
+type UploadManyOpts struct {
+	// Concurrency limits the number of parallel uploads. 0 uses the default (4).
+	Concurrency int
+}
+
+// UploadMany uploads several files in parallel and returns one result per
+// source, in the same order as sources. A failed upload does not stop the
+// others; check UploadResult.Err for each item.
+func (c *Client) UploadMany(ctx context.Context, sources []UploadSource, opts UploadManyOpts) []UploadResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultUploadConcurrency
+	}
+
+	results := make([]UploadResult, len(sources))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, src := range sources {
+		select {
+		case <-ctx.Done():
+			results[i].Err = timeoutError("UploadMany", ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			att, err := c.uploadSource(ctx, i, src)
+			results[i] = UploadResult{Attachment: att, Err: err}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// SendAlbum uploads all sources via [Client.UploadMany] and sends them as a
+// single message to a chat. Text, format and other fields are taken from body,
+// which may be nil; its attachments are replaced with the uploaded ones.
+//
+// The number of sources is validated before any upload starts. If any upload
+// fails, no message is sent and the per-item errors are returned joined.
+func (c *Client) SendAlbum(ctx context.Context, chatID int64, body *NewMessageBody, sources []UploadSource, opts UploadManyOpts) (*Message, error) {
+	if len(sources) > MaxAttachmentsPerMessage {
+		return nil, ErrTooManyAttachments
+	}
+
+	results := c.UploadMany(ctx, sources, opts)
+
+	attachments := make([]AttachmentRequest, 0, len(results))
+	var errs []error
+	for i, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("source %d: %w", i, r.Err))
+			continue
+		}
+		attachments = append(attachments, r.Attachment)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	msg := NewMessageBody{}
+	if body != nil {
+		msg = *body
+	}
+	msg.Attachments = attachments
+	return c.SendMessage(ctx, chatID, &msg)
+}
+
+// uploadSource uploads the i-th source and wraps the token in an attachment
+// request.
+func (c *Client) uploadSource(ctx context.Context, i int, src UploadSource) (AttachmentRequest, error) {
+	if src.Reader == nil && src.Path == "" && src.URL == "" {
+		return AttachmentRequest{}, validationError("UploadMany", fmt.Sprintf("sources[%d]", i), "must have a reader, path or URL")
+	}
+
+	if src.Type == UploadImage {
+		var tokens *PhotoTokens
+		var err error
+		switch {
+		case src.Reader != nil:
+			tokens, err = c.UploadPhoto(ctx, src.Filename, src.Reader)
+		case src.FS != nil:
+			tokens, err = c.UploadPhotoFromFS(ctx, src.FS, src.Path)
+		case src.Path != "":
+			tokens, err = c.UploadPhotoFromFile(ctx, src.Path)
+		default:
+			tokens, err = c.UploadPhotoFromURL(ctx, src.URL)
+		}
+		if err != nil {
+			return AttachmentRequest{}, err
+		}
+		return NewPhotoAttachment(PhotoAttachmentRequestPayload{Photos: tokens.Photos}), nil
+	}
+
+	var newAttachment func(UploadedInfo) AttachmentRequest
+	switch src.Type {
+	case UploadVideo:
+		newAttachment = NewVideoAttachment
+	case UploadAudio:
+		newAttachment = NewAudioAttachment
+	case UploadFile:
+		newAttachment = NewFileAttachment
+	default:
+		return AttachmentRequest{}, fetchError("UploadMany", 0, fmt.Sprintf("unsupported upload type %q", src.Type))
+	}
+
+	var info *UploadedInfo
+	var err error
+	switch {
+	case src.Reader != nil:
+		info, err = c.UploadMedia(ctx, src.Type, src.Filename, src.Reader)
+	case src.FS != nil:
+		info, err = c.UploadMediaFromFS(ctx, src.Type, src.FS, src.Path)
+	case src.Path != "":
+		info, err = c.UploadMediaFromFile(ctx, src.Type, src.Path)
+	default:
+		info, err = c.UploadMediaFromURL(ctx, src.Type, src.URL)
+	}
+	if err != nil {
+		return AttachmentRequest{}, err
+	}
+	return newAttachment(*info), nil
+}
@@ -0,0 +1,148 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// uploadHandler serves GetUploadURL and echoes the uploaded filename as the token.
+// Uploads of files named "fail*" return HTTP 500. Test helper.
+func uploadHandler(t *testing.T, next http.HandlerFunc) http.HandlerFunc {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/uploads":
+			writeJSON(t, w, UploadEndpoint{URL: "http://" + r.Host + "/do-upload?type=" + r.URL.Query().Get("type")})
+		case "/do-upload":
+			_, fh, err := r.FormFile("data")
+			if err != nil {
+				t.Errorf("form file: %v", err)
+				return
+			}
+			if strings.HasPrefix(fh.Filename, "fail") {
+				writeError(t, w, http.StatusInternalServerError, "upload failed")
+				return
+			}
+			if r.URL.Query().Get("type") == string(UploadImage) {
+				writeJSON(t, w, PhotoTokens{Photos: map[string]PhotoToken{"default": {Token: fh.Filename}}})
+				return
+			}
+			writeJSON(t, w, UploadedInfo{Token: fh.Filename})
+		default:
+			next(w, r)
+		}
+	}
+}
+
+func TestUploadMany(t *testing.T) {
+	c, _ := testClient(t, uploadHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+
+	sources := []UploadSource{
+		{Type: UploadImage, Filename: "a.jpg", Reader: strings.NewReader("a")},
+		{Type: UploadVideo, Filename: "b.mp4", Reader: strings.NewReader("b")},
+		{Type: UploadFile, Filename: "fail.txt", Reader: strings.NewReader("c")},
+		{Type: UploadAudio, Filename: "d.mp3", Reader: strings.NewReader("d")},
+		{Type: UploadFile},
+	}
+
+	results := c.UploadMany(context.Background(), sources, UploadManyOpts{Concurrency: 2})
+	if len(results) != len(sources) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(sources))
+	}
+
+	photo, ok := results[0].Attachment.Payload.(PhotoAttachmentRequestPayload)
+	if results[0].Err != nil || !ok || photo.Photos["default"].Token != "a.jpg" {
+		t.Errorf("results[0] = %+v, want photo a.jpg", results[0])
+	}
+	if results[1].Err != nil || results[1].Attachment.Type != "video" ||
+		results[1].Attachment.Payload.(UploadedInfo).Token != "b.mp4" {
+		t.Errorf("results[1] = %+v, want video b.mp4", results[1])
+	}
+	if results[2].Err == nil {
+		t.Error("results[2]: expected error")
+	}
+	if results[3].Err != nil || results[3].Attachment.Type != "audio" {
+		t.Errorf("results[3] = %+v, want audio", results[3])
+	}
+	var e *Error
+	if !errors.As(results[4].Err, &e) || e.Kind != ErrValidation || e.Field != "sources[4]" {
+		t.Errorf("results[4].Err = %v, want ErrValidation for sources[4]", results[4].Err)
+	}
+}
+
+func TestSendAlbum(t *testing.T) {
+	var sent NewMessageBody
+	c, _ := testClient(t, uploadHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			return
+		}
+		if r.URL.Query().Get("chat_id") != "42" {
+			t.Errorf("chat_id = %q, want 42", r.URL.Query().Get("chat_id"))
+		}
+		readJSON(t, r, &sent)
+		writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: "mid-1"}}})
+	}))
+
+	msg, err := c.SendAlbum(context.Background(), 42, &NewMessageBody{Text: Some("Album")}, []UploadSource{
+		{Type: UploadImage, Filename: "1.jpg", Reader: strings.NewReader("1")},
+		{Type: UploadImage, Filename: "2.jpg", Reader: strings.NewReader("2")},
+	}, UploadManyOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Body.MID != "mid-1" {
+		t.Errorf("MID = %q, want mid-1", msg.Body.MID)
+	}
+	if sent.Text.Value != "Album" {
+		t.Errorf("Text = %q, want Album", sent.Text.Value)
+	}
+	if len(sent.Attachments) != 2 {
+		t.Fatalf("len(Attachments) = %d, want 2", len(sent.Attachments))
+	}
+	for i, att := range sent.Attachments {
+		if att.Type != "image" {
+			t.Errorf("Attachments[%d].Type = %q, want image", i, att.Type)
+		}
+	}
+}
+
+func TestSendAlbumUploadError(t *testing.T) {
+	c, _ := testClient(t, uploadHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("message must not be sent, got request to %s", r.URL.Path)
+	}))
+
+	_, err := c.SendAlbum(context.Background(), 42, nil, []UploadSource{
+		{Type: UploadImage, Filename: "ok.jpg", Reader: strings.NewReader("1")},
+		{Type: UploadImage, Filename: "fail.jpg", Reader: strings.NewReader("2")},
+	}, UploadManyOpts{})
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, got %T (%v)", err, err)
+	}
+	if e.Kind != ErrAPI {
+		t.Errorf("Kind = %v, want ErrAPI", e.Kind)
+	}
+}
+
+func TestSendAlbumTooManyAttachments(t *testing.T) {
+	var requestCount atomic.Int32
+	c, _ := testClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		requestCount.Add(1)
+	})
+
+	sources := make([]UploadSource, MaxAttachmentsPerMessage+1)
+	_, err := c.SendAlbum(context.Background(), 42, nil, sources, UploadManyOpts{})
+	if !errors.Is(err, ErrTooManyAttachments) {
+		t.Errorf("err = %v, want ErrTooManyAttachments", err)
+	}
+	if requestCount.Load() != 0 {
+		t.Errorf("requests = %d, want 0", requestCount.Load())
+	}
+}
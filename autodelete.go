@@ -0,0 +1,323 @@
+package maxigo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// maxDeleteAttempts is the number of times [AutoDeleter] tries to delete
+	// a message before giving up. Rate-limited attempts are not counted.
+	maxDeleteAttempts = 10
+	// maxDeleteBackoff caps the delay between deletion attempts.
+	maxDeleteBackoff = time.Hour
+)
+
+// PendingDeletion is a message queued for deletion by an [AutoDeleter].
+type PendingDeletion struct {
+	// MID is the message ID.
+	MID string `json:"mid"`
+	// ChatID is the chat of the message, for reporting.
+	ChatID int64 `json:"chat_id,omitempty"`
+	// At is when the message should be deleted.
+	At time.Time `json:"at"`
+	// Attempts counts failed deletions so far, not counting rate limiting.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// DeletionStore persists the queue of an [AutoDeleter].
+// Implementations must be safe for concurrent use.
+type DeletionStore interface {
+	// Deletions returns all queued deletions.
+	Deletions(ctx context.Context) ([]PendingDeletion, error)
+	// SaveDeletion queues d, replacing a queued deletion with the same MID.
+	SaveDeletion(ctx context.Context, d PendingDeletion) error
+	// RemoveDeletion removes a queued deletion. Removing a missing MID is
+	// not an error.
+	RemoveDeletion(ctx context.Context, mid string) error
+}
+
+// MemoryDeletionStore is a [DeletionStore] kept in memory.
+type MemoryDeletionStore struct {
+	mu      sync.Mutex
+	pending map[string]PendingDeletion
+}
+
+// NewMemoryDeletionStore creates an empty in-memory store.
+func NewMemoryDeletionStore() *MemoryDeletionStore {
+	return &MemoryDeletionStore{pending: make(map[string]PendingDeletion)}
+}
+
+// Deletions implements [DeletionStore]. Deletions are ordered by time.
+func (s *MemoryDeletionStore) Deletions(context.Context) ([]PendingDeletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedDeletions(s.pending), nil
+}
+
+// SaveDeletion implements [DeletionStore].
+func (s *MemoryDeletionStore) SaveDeletion(_ context.Context, d PendingDeletion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[d.MID] = d
+	return nil
+}
+
+// RemoveDeletion implements [DeletionStore].
+func (s *MemoryDeletionStore) RemoveDeletion(_ context.Context, mid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, mid)
+	return nil
+}
+
+// FileDeletionStore is a [DeletionStore] that keeps the queue in one JSON
+// file, rewritten atomically on every change.
+type FileDeletionStore struct {
+	store *fileStore[string, PendingDeletion]
+}
+
+// OpenFileDeletionStore loads the queue stored at path. A missing file is
+// an empty queue; it is created on the first change.
+func OpenFileDeletionStore(path string) (*FileDeletionStore, error) {
+	store, err := openFileStore(path, "deletion store",
+		func(d PendingDeletion) string { return d.MID }, compareDeletions)
+	if err != nil {
+		return nil, err
+	}
+	return &FileDeletionStore{store: store}, nil
+}
+
+// Deletions implements [DeletionStore]. Deletions are ordered by time.
+func (s *FileDeletionStore) Deletions(context.Context) ([]PendingDeletion, error) {
+	return s.store.all(), nil
+}
+
+// SaveDeletion implements [DeletionStore].
+func (s *FileDeletionStore) SaveDeletion(_ context.Context, d PendingDeletion) error {
+	return s.store.put(d)
+}
+
+// RemoveDeletion implements [DeletionStore].
+func (s *FileDeletionStore) RemoveDeletion(_ context.Context, mid string) error {
+	return s.store.remove(mid)
+}
+
+func sortedDeletions(pending map[string]PendingDeletion) []PendingDeletion {
+	return slices.SortedFunc(maps.Values(pending), compareDeletions)
+}
+
+// compareDeletions orders deletions by time, then by MID.
+func compareDeletions(a, b PendingDeletion) int {
+	return cmp.Or(a.At.Compare(b.At), cmp.Compare(a.MID, b.MID))
+}
+
+// AutoDeleteOpts holds optional parameters for [Client.NewAutoDeleter].
+type AutoDeleteOpts struct {
+	// Store persists the deletion queue. nil uses a [MemoryDeletionStore],
+	// which loses queued deletions on restart.
+	Store DeletionStore
+	// PollInterval is how often due deletions are processed and the delay
+	// before the first retry of a failed deletion. The delay doubles with
+	// every further attempt, up to an hour. 0 uses the default (1s).
+	PollInterval time.Duration
+	// OnDelete, if set, is called after a message is deleted (err is nil),
+	// found already deleted (err is nil) or given up on after 10 failed
+	// attempts.
+	OnDelete func(d PendingDeletion, err error)
+}
+
+// AutoDeleter deletes bot messages after a delay, e.g. one-time codes or
+// error hints, and enforces retention limits with [AutoDeleter.Sweep].
+// Deletions are queued in a [DeletionStore], so with a persistent store they
+// survive restarts. Create one with [Client.NewAutoDeleter] and process the
+// queue with [AutoDeleter.Run].
+type AutoDeleter struct {
+	client *Client
+	opts   AutoDeleteOpts
+	now    func() time.Time
+
+	botMu sync.Mutex
+	botID int64
+}
+
+// NewAutoDeleter creates an auto-deleter that deletes messages with c.
+func (c *Client) NewAutoDeleter(opts AutoDeleteOpts) *AutoDeleter {
+	if opts.Store == nil {
+		opts.Store = NewMemoryDeletionStore()
+	}
+	opts.PollInterval = cmp.Or(opts.PollInterval, time.Second)
+	return &AutoDeleter{client: c, opts: opts, now: time.Now}
+}
+
+// SendTemporary sends body to a chat with [Client.SendMessage] and queues
+// the message for deletion after ttl.
+func (d *AutoDeleter) SendTemporary(ctx context.Context, chatID int64, body *NewMessageBody, ttl time.Duration) (*Message, error) {
+	msg, err := d.client.SendMessage(ctx, chatID, body)
+	if err != nil {
+		return nil, err
+	}
+	return msg, d.DeleteAfter(ctx, msg, ttl)
+}
+
+// DeleteAfter queues an already sent message for deletion after ttl.
+func (d *AutoDeleter) DeleteAfter(ctx context.Context, msg *Message, ttl time.Duration) error {
+	p := PendingDeletion{MID: msg.Body.MID, At: d.now().Add(ttl)}
+	if msg.Recipient.ChatID != nil {
+		p.ChatID = *msg.Recipient.ChatID
+	}
+	return d.enqueue(ctx, p)
+}
+
+// Sweep queues for immediate deletion all messages the bot sent to a chat
+// more than maxAge ago, and returns their number. Messages that are already
+// queued keep their schedule and are not counted. Call it periodically,
+// e.g. once a day, to enforce a retention policy; the messages are deleted
+// by [AutoDeleter.Run].
+func (d *AutoDeleter) Sweep(ctx context.Context, chatID int64, maxAge time.Duration) (int, error) {
+	botID, err := d.botUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := d.opts.Store.Deletions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("auto-delete: %w", err)
+	}
+	known := make(map[string]bool, len(pending))
+	for _, p := range pending {
+		known[p.MID] = true
+	}
+
+	now := d.now()
+	cutoff := now.Add(-maxAge).UnixMilli()
+	queued := 0
+	for msg, err := range d.client.MessagesBetween(ctx, chatID, cutoff, 0) {
+		if err != nil {
+			return queued, err
+		}
+		if msg.Sender == nil || msg.Sender.UserID != botID || msg.Timestamp > cutoff || known[msg.Body.MID] {
+			continue
+		}
+		if err := d.enqueue(ctx, PendingDeletion{MID: msg.Body.MID, ChatID: chatID, At: now}); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// Pending returns the queued deletions ordered by time.
+func (d *AutoDeleter) Pending(ctx context.Context) ([]PendingDeletion, error) {
+	return d.opts.Store.Deletions(ctx)
+}
+
+// Run deletes due messages every [AutoDeleteOpts.PollInterval] until ctx is
+// done. Messages that are already deleted count as deleted; other failures
+// are retried with exponential backoff, up to 10 attempts. Rate-limited
+// attempts are retried without counting. Store errors stop Run.
+func (d *AutoDeleter) Run(ctx context.Context) error {
+	t := time.NewTicker(d.opts.PollInterval)
+	defer t.Stop()
+	for {
+		if err := d.deleteDue(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (d *AutoDeleter) deleteDue(ctx context.Context) error {
+	pending, err := d.opts.Store.Deletions(ctx)
+	if err != nil {
+		return fmt.Errorf("auto-delete: %w", err)
+	}
+	now := d.now()
+	for _, p := range pending {
+		if p.At.After(now) || ctx.Err() != nil {
+			continue
+		}
+
+		result, err := d.client.DeleteMessage(ctx, p.MID)
+		if err == nil && !result.Success {
+			err = apiError("DeleteMessage", http.StatusOK, result.Message)
+		}
+		if isNotFound(err) {
+			err = nil
+		}
+
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			// Interrupted by shutdown: keep it queued for the next run.
+			continue
+		case isRateLimited(err) || p.Attempts+1 < maxDeleteAttempts:
+			p.At = now.Add(d.retryDelay(p.Attempts))
+			if !isRateLimited(err) {
+				p.Attempts++
+			}
+			if serr := d.opts.Store.SaveDeletion(ctx, p); serr != nil {
+				return fmt.Errorf("auto-delete: %w", serr)
+			}
+			continue
+		}
+		if serr := d.opts.Store.RemoveDeletion(ctx, p.MID); serr != nil {
+			return fmt.Errorf("auto-delete: %w", serr)
+		}
+		if d.opts.OnDelete != nil {
+			d.opts.OnDelete(p, err)
+		}
+	}
+	return nil
+}
+
+// retryDelay returns the delay before retrying a deletion that failed
+// attempts times before: PollInterval, doubled per earlier attempt.
+func (d *AutoDeleter) retryDelay(attempts int) time.Duration {
+	delay := d.opts.PollInterval
+	for range attempts {
+		if delay >= maxDeleteBackoff/2 {
+			return maxDeleteBackoff
+		}
+		delay *= 2
+	}
+	return min(delay, maxDeleteBackoff)
+}
+
+// isRateLimited reports whether err is an API error with HTTP status 429.
+func isRateLimited(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == ErrAPI && e.StatusCode == http.StatusTooManyRequests
+}
+
+func (d *AutoDeleter) enqueue(ctx context.Context, p PendingDeletion) error {
+	if err := d.opts.Store.SaveDeletion(ctx, p); err != nil {
+		return fmt.Errorf("auto-delete: %w", err)
+	}
+	return nil
+}
+
+// botUserID returns the bot's user ID, fetched once with [Client.GetBot].
+func (d *AutoDeleter) botUserID(ctx context.Context) (int64, error) {
+	d.botMu.Lock()
+	defer d.botMu.Unlock()
+	if d.botID == 0 {
+		bot, err := d.client.GetBot(ctx)
+		if err != nil {
+			return 0, err
+		}
+		d.botID = bot.UserID
+	}
+	return d.botID, nil
+}
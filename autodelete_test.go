@@ -0,0 +1,348 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// deleteServer is a fake API that sends messages, lists a fixed history and
+// deletes messages, failing deletions of mids in failing.
+type deleteServer struct {
+	mu       sync.Mutex
+	next     int
+	messages map[string]bool
+	history  []Message
+	failing  map[string]bool
+	limited  int // deletions answered with 429 before failing applies
+	deletes  int
+}
+
+func newDeleteServer(t *testing.T) (*Client, *deleteServer) {
+	t.Helper()
+	s := &deleteServer{messages: make(map[string]bool), failing: make(map[string]bool)}
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch {
+		case r.URL.Path == "/me":
+			writeJSON(t, w, BotInfo{UserWithPhoto: UserWithPhoto{User: User{UserID: 100, IsBot: true}}})
+		case r.Method == http.MethodGet && r.URL.Path == "/messages":
+			writeJSON(t, w, MessageList{Messages: s.history})
+		case r.Method == http.MethodPost:
+			s.next++
+			mid := fmt.Sprintf("mid.%d", s.next)
+			s.messages[mid] = true
+			chatID := int64(7)
+			writeJSON(t, w, sendMessageResult{Message: Message{Recipient: Recipient{ChatID: &chatID}, Body: MessageBody{MID: mid}}})
+		case r.Method == http.MethodDelete:
+			s.deletes++
+			mid := r.URL.Query().Get("message_id")
+			switch {
+			case s.limited > 0:
+				s.limited--
+				writeError(t, w, http.StatusTooManyRequests, `{"code":"too.many.requests","message":"Slow down"}`)
+			case s.failing[mid]:
+				writeError(t, w, http.StatusInternalServerError, `{"code":"internal","message":"boom"}`)
+			case !s.messages[mid]:
+				writeError(t, w, http.StatusNotFound, `{"code":"not.found","message":"Message not found"}`)
+			default:
+				delete(s.messages, mid)
+				writeJSON(t, w, SimpleQueryResult{Success: true})
+			}
+		}
+	})
+	return c, s
+}
+
+func (s *deleteServer) exists(mid string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages[mid]
+}
+
+func TestAutoDeleterSendTemporary(t *testing.T) {
+	c, srv := newDeleteServer(t)
+	var deleted []string
+	d := c.NewAutoDeleter(AutoDeleteOpts{OnDelete: func(p PendingDeletion, err error) {
+		if err != nil {
+			t.Errorf("OnDelete(%s) err = %v", p.MID, err)
+		}
+		deleted = append(deleted, p.MID)
+	}})
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	msg, err := d.SendTemporary(ctx, 7, &NewMessageBody{Text: Some("code: 1234")}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, _ := d.Pending(ctx)
+	if len(pending) != 1 || pending[0].MID != msg.Body.MID || pending[0].ChatID != 7 || !pending[0].At.Equal(now.Add(time.Minute)) {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	if err := d.deleteDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.exists(msg.Body.MID) {
+		t.Fatal("message deleted before ttl")
+	}
+
+	now = now.Add(time.Minute)
+	if err := d.deleteDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if srv.exists(msg.Body.MID) || len(deleted) != 1 {
+		t.Errorf("exists = %v, deleted = %v; want deleted", srv.exists(msg.Body.MID), deleted)
+	}
+	if pending, _ := d.Pending(ctx); len(pending) != 0 {
+		t.Errorf("pending = %+v, want empty", pending)
+	}
+}
+
+func TestAutoDeleterAlreadyDeleted(t *testing.T) {
+	c, _ := newDeleteServer(t)
+	var errs []error
+	d := c.NewAutoDeleter(AutoDeleteOpts{OnDelete: func(_ PendingDeletion, err error) { errs = append(errs, err) }})
+	ctx := context.Background()
+
+	if err := d.DeleteAfter(ctx, &Message{Body: MessageBody{MID: "gone"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.deleteDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0] != nil {
+		t.Errorf("OnDelete errs = %v, want one nil", errs)
+	}
+	if pending, _ := d.Pending(ctx); len(pending) != 0 {
+		t.Errorf("pending = %+v, want empty", pending)
+	}
+}
+
+func TestAutoDeleterRetries(t *testing.T) {
+	c, srv := newDeleteServer(t)
+	srv.failing["mid.x"] = true
+	var errs []error
+	d := c.NewAutoDeleter(AutoDeleteOpts{
+		PollInterval: time.Second,
+		OnDelete:     func(_ PendingDeletion, err error) { errs = append(errs, err) },
+	})
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := d.DeleteAfter(ctx, &Message{Body: MessageBody{MID: "mid.x"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	delay := time.Second
+	for i := range maxDeleteAttempts {
+		if err := d.deleteDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		pending, _ := d.Pending(ctx)
+		if i == maxDeleteAttempts-1 {
+			break
+		}
+		if len(pending) != 1 || pending[0].Attempts != i+1 || !pending[0].At.Equal(now.Add(delay)) {
+			t.Fatalf("attempt %d: pending = %+v, want retry in %v", i+1, pending, delay)
+		}
+		// A retry is not due before the backoff delay.
+		now = now.Add(delay - time.Millisecond)
+		if err := d.deleteDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if srv.deletes != i+1 {
+			t.Fatalf("deletes = %d before the delay passed, want %d", srv.deletes, i+1)
+		}
+		now = now.Add(time.Millisecond)
+		delay = min(2*delay, maxDeleteBackoff)
+	}
+
+	if srv.deletes != maxDeleteAttempts {
+		t.Errorf("deletes = %d, want %d", srv.deletes, maxDeleteAttempts)
+	}
+	var e *Error
+	if len(errs) != 1 || !errors.As(errs[0], &e) || e.StatusCode != http.StatusInternalServerError {
+		t.Errorf("OnDelete errs = %v, want one 500 error", errs)
+	}
+	if pending, _ := d.Pending(ctx); len(pending) != 0 {
+		t.Errorf("pending = %+v, want given up", pending)
+	}
+}
+
+func TestAutoDeleterRateLimited(t *testing.T) {
+	c, srv := newDeleteServer(t)
+	srv.messages["mid.x"] = true
+	srv.limited = 2 * maxDeleteAttempts
+	d := c.NewAutoDeleter(AutoDeleteOpts{PollInterval: time.Second})
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := d.DeleteAfter(ctx, &Message{Body: MessageBody{MID: "mid.x"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 * maxDeleteAttempts {
+		if err := d.deleteDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		pending, _ := d.Pending(ctx)
+		if len(pending) != 1 || pending[0].Attempts != 0 || !pending[0].At.Equal(now.Add(time.Second)) {
+			t.Fatalf("pending = %+v, want retry in 1s without counting", pending)
+		}
+		now = now.Add(time.Second)
+	}
+	if err := d.deleteDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if srv.exists("mid.x") {
+		t.Error("message was not deleted after rate limiting ended")
+	}
+}
+
+func TestAutoDeleterRetryDelay(t *testing.T) {
+	d := (&Client{}).NewAutoDeleter(AutoDeleteOpts{PollInterval: time.Minute})
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{5, 32 * time.Minute},
+		{6, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := d.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestAutoDeleterSweep(t *testing.T) {
+	c, srv := newDeleteServer(t)
+	d := c.NewAutoDeleter(AutoDeleteOpts{})
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	bot, user := &User{UserID: 100}, &User{UserID: 5}
+	at := func(age time.Duration) int64 { return now.Add(-age).UnixMilli() }
+	srv.history = []Message{
+		{Sender: bot, Timestamp: at(2 * 24 * time.Hour), Body: MessageBody{MID: "bot.old"}},
+		{Sender: user, Timestamp: at(3 * 24 * time.Hour), Body: MessageBody{MID: "user.old"}},
+		{Sender: bot, Timestamp: at(8 * 24 * time.Hour), Body: MessageBody{MID: "bot.older"}},
+		{Timestamp: at(9 * 24 * time.Hour), Body: MessageBody{MID: "system"}},
+	}
+
+	n, err := d.Sweep(ctx, 7, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, _ := d.Pending(ctx)
+	if n != 2 || len(pending) != 2 || pending[0].MID != "bot.old" || pending[1].MID != "bot.older" {
+		t.Errorf("Sweep = %d, pending = %+v; want the two old bot messages", n, pending)
+	}
+	for _, p := range pending {
+		if p.ChatID != 7 || !p.At.Equal(now) {
+			t.Errorf("pending = %+v, want chat 7 due now", p)
+		}
+	}
+
+	// Queued messages keep their schedule and attempts.
+	retry := PendingDeletion{MID: "bot.old", ChatID: 7, At: now.Add(time.Minute), Attempts: 3}
+	if err := d.opts.Store.SaveDeletion(ctx, retry); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Second)
+	if n, err := d.Sweep(ctx, 7, 24*time.Hour); err != nil || n != 0 {
+		t.Errorf("second Sweep = %d, %v; want 0", n, err)
+	}
+	pending, _ = d.Pending(ctx)
+	if len(pending) != 2 || pending[1] != retry {
+		t.Errorf("pending = %+v, want %+v kept", pending, retry)
+	}
+}
+
+func TestFileDeletionStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deletions.json")
+	c, srv := newDeleteServer(t)
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	store, err := OpenFileDeletionStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := c.NewAutoDeleter(AutoDeleteOpts{Store: store})
+	d.now = func() time.Time { return now }
+	msg, err := d.SendTemporary(ctx, 7, &NewMessageBody{Text: Some("hint")}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart: a new store and deleter see the queued deletion.
+	store, err = OpenFileDeletionStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d = c.NewAutoDeleter(AutoDeleteOpts{Store: store})
+	now = now.Add(time.Hour)
+	d.now = func() time.Time { return now }
+	if err := d.deleteDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if srv.exists(msg.Body.MID) {
+		t.Error("message was not deleted after restart")
+	}
+
+	store, err = OpenFileDeletionStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := store.Deletions(ctx); len(pending) != 0 {
+		t.Errorf("pending = %+v, want none", pending)
+	}
+}
+
+func TestAutoDeleterRun(t *testing.T) {
+	c, srv := newDeleteServer(t)
+	deleted := make(chan error, 1)
+	d := c.NewAutoDeleter(AutoDeleteOpts{
+		PollInterval: 5 * time.Millisecond,
+		OnDelete:     func(_ PendingDeletion, err error) { deleted <- err },
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msg, err := d.SendTemporary(ctx, 7, &NewMessageBody{Text: Some("bye")}, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+
+	select {
+	case err := <-deleted:
+		if err != nil {
+			t.Errorf("delete: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was not deleted")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+	if srv.exists(msg.Body.MID) {
+		t.Error("message still exists")
+	}
+}
@@ -19,6 +19,10 @@ func (c *Client) GetBot(ctx context.Context) (*BotInfo, error) {
 // Only filled fields will be updated; the rest remain unchanged.
 // Corresponds to PATCH /me.
 func (c *Client) EditBot(ctx context.Context, patch *BotPatch) (*BotInfo, error) {
+	if err := c.validateRequest("EditBot", func() *Error { return patch.validate() }); err != nil {
+		return nil, err
+	}
+
 	var result BotInfo
 	if err := c.do(ctx, "EditBot", http.MethodPatch, "/me", nil, patch, &result); err != nil {
 		return nil, err
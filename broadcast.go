@@ -0,0 +1,393 @@
+package maxigo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BroadcastRecipient is a user or chat to send a broadcast message to.
+// Exactly one of UserID and ChatID should be set; UserID takes precedence.
+type BroadcastRecipient struct {
+	UserID int64
+	ChatID int64
+}
+
+// Key returns a stable identifier of the recipient ("user:ID" or "chat:ID"),
+// used in [BroadcastStore].
+func (r BroadcastRecipient) Key() string {
+	if r.UserID != 0 {
+		return "user:" + strconv.FormatInt(r.UserID, 10)
+	}
+	return "chat:" + strconv.FormatInt(r.ChatID, 10)
+}
+
+// DeliveryStatus is the outcome of sending a broadcast to one recipient.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is recorded before sending. A recipient left pending by
+	// a crash is not sent to again, so that nobody gets the message twice.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySent means the message was sent.
+	DeliverySent DeliveryStatus = "sent"
+	// DeliveryBlocked means the user blocked the bot or the bot may not write
+	// to the chat (HTTP 403).
+	DeliveryBlocked DeliveryStatus = "blocked"
+	// DeliveryNotFound means the user or chat does not exist (HTTP 404).
+	DeliveryNotFound DeliveryStatus = "not_found"
+	// DeliveryRateLimited means the API rejected the request with HTTP 429.
+	DeliveryRateLimited DeliveryStatus = "rate_limited"
+	// DeliveryFailed covers all other errors.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// final reports whether a recipient with this status is skipped when a
+// broadcast is resumed. Rate-limited and failed recipients are retried.
+func (s DeliveryStatus) final() bool {
+	switch s {
+	case DeliveryPending, DeliverySent, DeliveryBlocked, DeliveryNotFound:
+		return true
+	}
+	return false
+}
+
+// BroadcastStore records delivery statuses so that an interrupted broadcast
+// can be resumed. Implementations must be safe for concurrent use.
+type BroadcastStore interface {
+	// Status returns the recorded status for a recipient key, or "" if none.
+	Status(ctx context.Context, key string) (DeliveryStatus, error)
+	// SetStatus records the status for a recipient key.
+	SetStatus(ctx context.Context, key string, status DeliveryStatus) error
+}
+
+// BroadcastOpts holds optional parameters for [Client.Broadcast].
+type BroadcastOpts struct {
+	// RatePerSecond limits the number of messages sent per second.
+	// 0 uses the default (20).
+	RatePerSecond float64
+	// Concurrency limits the number of parallel requests. 0 uses the default (4).
+	Concurrency int
+	// Store, if set, records statuses and skips recipients that already have
+	// a final status, making the broadcast resumable.
+	Store BroadcastStore
+	// OnResult, if set, is called after each recipient is processed.
+	// It may be called concurrently.
+	OnResult func(BroadcastResult)
+}
+
+// BroadcastResult is the outcome for one recipient.
+type BroadcastResult struct {
+	Recipient BroadcastRecipient
+	Status    DeliveryStatus
+	// Message is the sent message. Only set when Status is DeliverySent.
+	Message *Message
+	// Err is the send error. Nil when Status is DeliverySent.
+	Err error
+}
+
+// BroadcastReport summarizes a broadcast.
+type BroadcastReport struct {
+	// Counts is the number of recipients per status in this run.
+	Counts map[DeliveryStatus]int
+	// Skipped is the number of recipients skipped because the store already
+	// had a final status for them or they were listed more than once.
+	Skipped int
+	// Failures lists recipients that were not sent to in this run.
+	Failures []BroadcastResult
+	// Duration is the time the broadcast took.
+	Duration time.Duration
+}
+
+// Sent returns the number of messages sent in this run.
+func (r *BroadcastReport) Sent() int {
+	return r.Counts[DeliverySent]
+}
+
+// defaultBroadcastRate is the default message rate of [Client.Broadcast].
+const defaultBroadcastRate = 20
+
+// Broadcast sends body to every recipient. See [Client.BroadcastFunc].
+func (c *Client) Broadcast(ctx context.Context, recipients iter.Seq[BroadcastRecipient], body *NewMessageBody, opts BroadcastOpts) (*BroadcastReport, error) {
+	return c.BroadcastFunc(ctx, recipients, func(BroadcastRecipient) (*NewMessageBody, error) {
+		return body, nil
+	}, opts)
+}
+
+// BroadcastFunc sends a message built by render to every recipient, using
+// [Client.SendMessageToUser] for users and [Client.SendMessage] for chats.
+// Messages are sent at opts.RatePerSecond; failures are classified into
+// [DeliveryStatus] values and do not stop the broadcast. If render returns an
+// error, the recipient is reported as failed and nothing is sent.
+//
+// Recipients listed more than once get the message once. With opts.Store,
+// every recipient is marked [DeliveryPending] before sending, so a broadcast
+// resumed after a crash never sends a message twice.
+//
+// The returned error is non-nil if ctx is done or the store fails; the report
+// covers the recipients processed until then.
+func (c *Client) BroadcastFunc(ctx context.Context, recipients iter.Seq[BroadcastRecipient], render func(BroadcastRecipient) (*NewMessageBody, error), opts BroadcastOpts) (*BroadcastReport, error) {
+	start := time.Now()
+	rate := opts.RatePerSecond
+	if rate <= 0 {
+		rate = defaultBroadcastRate
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	limiter := newRateLimiter(rate)
+
+	report := &BroadcastReport{Counts: make(map[DeliveryStatus]int)}
+	var mu sync.Mutex
+	var storeErr error
+	record := func(res BroadcastResult) {
+		mu.Lock()
+		report.Counts[res.Status]++
+		if res.Status != DeliverySent {
+			report.Failures = append(report.Failures, res)
+		}
+		mu.Unlock()
+		if opts.OnResult != nil {
+			opts.OnResult(res)
+		}
+	}
+	failStore := func(err error) {
+		mu.Lock()
+		if storeErr == nil {
+			storeErr = fmt.Errorf("broadcast store: %w", err)
+		}
+		mu.Unlock()
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	seen := make(map[string]bool)
+	for r := range recipients {
+		if ctx.Err() != nil {
+			break
+		}
+		key := r.Key()
+		if seen[key] {
+			report.Skipped++
+			continue
+		}
+		if opts.Store != nil {
+			status, err := opts.Store.Status(ctx, key)
+			if err != nil {
+				failStore(err)
+				break
+			}
+			if status.final() {
+				report.Skipped++
+				continue
+			}
+		}
+
+		if err := limiter.Wait(ctx); err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		// Mark the recipient before spawning, so that a duplicate cannot
+		// pass the checks above while the send is in flight.
+		seen[key] = true
+		if opts.Store != nil {
+			if err := opts.Store.SetStatus(ctx, key, DeliveryPending); err != nil {
+				<-sem
+				failStore(err)
+				break
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := c.broadcastOne(ctx, r, render)
+			if opts.Store != nil {
+				if err := opts.Store.SetStatus(ctx, key, res.Status); err != nil {
+					failStore(err)
+					cancel()
+				}
+			}
+			record(res)
+		}()
+	}
+	wg.Wait()
+
+	report.Duration = time.Since(start)
+	if storeErr != nil {
+		return report, storeErr
+	}
+	return report, parent.Err()
+}
+
+func (c *Client) broadcastOne(ctx context.Context, r BroadcastRecipient, render func(BroadcastRecipient) (*NewMessageBody, error)) BroadcastResult {
+	res := BroadcastResult{Recipient: r}
+	body, err := render(r)
+	if err != nil {
+		res.Status, res.Err = DeliveryFailed, err
+		return res
+	}
+
+	var msg *Message
+	if r.UserID != 0 {
+		msg, err = c.SendMessageToUser(ctx, r.UserID, body)
+	} else {
+		msg, err = c.SendMessage(ctx, r.ChatID, body)
+	}
+	if err != nil {
+		res.Status, res.Err = classifyDelivery(err), err
+		return res
+	}
+	res.Status, res.Message = DeliverySent, msg
+	return res
+}
+
+// classifyDelivery maps a send error to a delivery status.
+func classifyDelivery(err error) DeliveryStatus {
+	var e *Error
+	if !errors.As(err, &e) || e.Kind != ErrAPI {
+		return DeliveryFailed
+	}
+	switch e.StatusCode {
+	case http.StatusForbidden:
+		return DeliveryBlocked
+	case http.StatusNotFound:
+		return DeliveryNotFound
+	case http.StatusTooManyRequests:
+		return DeliveryRateLimited
+	}
+	return DeliveryFailed
+}
+
+// MemoryBroadcastStore is a [BroadcastStore] kept in memory. It lets a
+// broadcast be retried within one process, e.g. after a context timeout.
+type MemoryBroadcastStore struct {
+	mu       sync.Mutex
+	statuses map[string]DeliveryStatus
+}
+
+// NewMemoryBroadcastStore creates an empty in-memory store.
+func NewMemoryBroadcastStore() *MemoryBroadcastStore {
+	return &MemoryBroadcastStore{statuses: make(map[string]DeliveryStatus)}
+}
+
+// Status implements [BroadcastStore].
+func (s *MemoryBroadcastStore) Status(_ context.Context, key string) (DeliveryStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statuses[key], nil
+}
+
+// SetStatus implements [BroadcastStore].
+func (s *MemoryBroadcastStore) SetStatus(_ context.Context, key string, status DeliveryStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[key] = status
+	return nil
+}
+
+// FileBroadcastStore is a [BroadcastStore] backed by an append-only log file
+// with one "key status" line per change. Reopening the file restores the
+// latest status of every recipient.
+//
+// Lines that cannot be parsed are skipped. A last line torn by a crash is
+// skipped too, so a recipient whose pending status was recorded before it
+// stays pending and is not sent to again.
+type FileBroadcastStore struct {
+	MemoryBroadcastStore
+	f *os.File
+}
+
+// OpenFileBroadcastStore opens or creates a store at path.
+// Call [FileBroadcastStore.Close] when the broadcast is finished.
+func OpenFileBroadcastStore(path string) (*FileBroadcastStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open broadcast store: %w", err)
+	}
+
+	s := &FileBroadcastStore{f: f}
+	s.statuses = make(map[string]DeliveryStatus)
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			if line != "" {
+				// The last write was torn: end the line so that the next
+				// record starts on its own line.
+				if err := s.appendLine(""); err != nil {
+					_ = f.Close()
+					return nil, fmt.Errorf("repair broadcast store: %w", err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("read broadcast store: %w", err)
+		}
+		key, status, ok := strings.Cut(strings.TrimSuffix(line, "\n"), " ")
+		if ok && key != "" && knownDeliveryStatuses[DeliveryStatus(status)] {
+			s.statuses[key] = DeliveryStatus(status)
+		}
+	}
+	return s, nil
+}
+
+// knownDeliveryStatuses lists the statuses a [FileBroadcastStore] accepts
+// when it loads its file.
+var knownDeliveryStatuses = map[DeliveryStatus]bool{
+	DeliveryPending:     true,
+	DeliverySent:        true,
+	DeliveryBlocked:     true,
+	DeliveryNotFound:    true,
+	DeliveryRateLimited: true,
+	DeliveryFailed:      true,
+}
+
+// appendLine writes line and a newline to the file and syncs it.
+func (s *FileBroadcastStore) appendLine(line string) error {
+	if _, err := s.f.WriteString(line + "\n"); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+// SetStatus implements [BroadcastStore]. The change is written and synced
+// to the file before it is recorded in memory.
+func (s *FileBroadcastStore) SetStatus(_ context.Context, key string, status DeliveryStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.appendLine(key + " " + string(status)); err != nil {
+		return err
+	}
+	s.statuses[key] = status
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *FileBroadcastStore) Close() error {
+	return s.f.Close()
+}
@@ -0,0 +1,293 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func broadcastServer(t *testing.T) (*Client, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var sent []string
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body NewMessageBody
+		readJSON(t, r, &body)
+		target := r.URL.Query().Get("user_id")
+		if target == "" {
+			target = "chat" + r.URL.Query().Get("chat_id")
+		}
+
+		switch target {
+		case "2":
+			writeError(t, w, http.StatusForbidden, `{"code":"chat.denied","message":"Bot is blocked"}`)
+			return
+		case "3":
+			writeError(t, w, http.StatusNotFound, `{"code":"not.found","message":"User not found"}`)
+			return
+		case "4":
+			writeError(t, w, http.StatusTooManyRequests, `{"code":"too.many.requests","message":"Slow down"}`)
+			return
+		case "5":
+			writeError(t, w, http.StatusInternalServerError, `{"code":"internal","message":"Oops"}`)
+			return
+		}
+		mu.Lock()
+		sent = append(sent, target+"="+body.Text.Value)
+		mu.Unlock()
+		writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: "mid-" + target}}})
+	})
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		s := slices.Clone(sent)
+		slices.Sort(s)
+		return s
+	}
+}
+
+func recipients(rs ...BroadcastRecipient) iter.Seq[BroadcastRecipient] {
+	return slices.Values(rs)
+}
+
+func TestBroadcast(t *testing.T) {
+	c, sent := broadcastServer(t)
+
+	var mu sync.Mutex
+	var results []BroadcastResult
+	report, err := c.Broadcast(context.Background(),
+		recipients(
+			BroadcastRecipient{UserID: 1},
+			BroadcastRecipient{UserID: 2},
+			BroadcastRecipient{UserID: 3},
+			BroadcastRecipient{UserID: 4},
+			BroadcastRecipient{UserID: 5},
+			BroadcastRecipient{ChatID: 7},
+		),
+		&NewMessageBody{Text: Some("news")},
+		BroadcastOpts{RatePerSecond: 1000, OnResult: func(r BroadcastResult) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := sent(); !slices.Equal(got, []string{"1=news", "chat7=news"}) {
+		t.Errorf("sent = %v", got)
+	}
+	want := map[DeliveryStatus]int{
+		DeliverySent:        2,
+		DeliveryBlocked:     1,
+		DeliveryNotFound:    1,
+		DeliveryRateLimited: 1,
+		DeliveryFailed:      1,
+	}
+	for status, n := range want {
+		if report.Counts[status] != n {
+			t.Errorf("Counts[%s] = %d, want %d", status, report.Counts[status], n)
+		}
+	}
+	if report.Sent() != 2 || len(report.Failures) != 4 || len(results) != 6 {
+		t.Errorf("report = %+v, results = %d", report, len(results))
+	}
+	for _, f := range report.Failures {
+		var e *Error
+		if !errors.As(f.Err, &e) || e.Kind != ErrAPI {
+			t.Errorf("%+v: err = %v, want API error", f.Recipient, f.Err)
+		}
+	}
+}
+
+func TestBroadcastFunc(t *testing.T) {
+	c, sent := broadcastServer(t)
+	errRender := errors.New("no template")
+
+	report, err := c.BroadcastFunc(context.Background(),
+		recipients(BroadcastRecipient{UserID: 1}, BroadcastRecipient{UserID: 6}, BroadcastRecipient{UserID: 8}),
+		func(r BroadcastRecipient) (*NewMessageBody, error) {
+			if r.UserID == 8 {
+				return nil, errRender
+			}
+			return &NewMessageBody{Text: Some("hi " + r.Key())}, nil
+		},
+		BroadcastOpts{RatePerSecond: 1000},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := sent(); !slices.Equal(got, []string{"1=hi user:1", "6=hi user:6"}) {
+		t.Errorf("sent = %v", got)
+	}
+	if len(report.Failures) != 1 || !errors.Is(report.Failures[0].Err, errRender) {
+		t.Errorf("Failures = %+v, want render error", report.Failures)
+	}
+}
+
+func TestBroadcastDuplicates(t *testing.T) {
+	tests := []struct {
+		name  string
+		store BroadcastStore
+	}{
+		{"no store", nil},
+		{"memory store", NewMemoryBroadcastStore()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, sent := broadcastServer(t)
+			report, err := c.Broadcast(context.Background(),
+				recipients(
+					BroadcastRecipient{UserID: 1},
+					BroadcastRecipient{UserID: 1},
+					BroadcastRecipient{ChatID: 7},
+					BroadcastRecipient{UserID: 1},
+				),
+				&NewMessageBody{Text: Some("once")},
+				BroadcastOpts{RatePerSecond: 1000, Concurrency: 4, Store: tt.store},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := sent(); !slices.Equal(got, []string{"1=once", "chat7=once"}) {
+				t.Errorf("sent = %v", got)
+			}
+			if report.Skipped != 2 {
+				t.Errorf("Skipped = %d, want 2", report.Skipped)
+			}
+		})
+	}
+}
+
+func TestBroadcastResume(t *testing.T) {
+	c, sent := broadcastServer(t)
+	path := filepath.Join(t.TempDir(), "broadcast.log")
+
+	store, err := OpenFileBroadcastStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A previous run crashed while sending to user 6.
+	for key, status := range map[string]DeliveryStatus{
+		"user:1": DeliverySent,
+		"user:2": DeliveryBlocked,
+		"user:4": DeliveryRateLimited,
+		"user:6": DeliveryPending,
+	} {
+		if err := store.SetStatus(context.Background(), key, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = OpenFileBroadcastStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var rs []BroadcastRecipient
+	for _, id := range []int64{1, 2, 4, 6, 9} {
+		rs = append(rs, BroadcastRecipient{UserID: id})
+	}
+	report, err := c.Broadcast(context.Background(), recipients(rs...), &NewMessageBody{Text: Some("x")},
+		BroadcastOpts{RatePerSecond: 1000, Store: store})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := sent(); !slices.Equal(got, []string{"9=x"}) {
+		t.Errorf("sent = %v, want only user 9", got)
+	}
+	if report.Skipped != 3 || report.Counts[DeliveryRateLimited] != 1 {
+		t.Errorf("report = %+v, want 3 skipped and user 4 retried", report)
+	}
+	if status, _ := store.Status(context.Background(), "user:9"); status != DeliverySent {
+		t.Errorf("user:9 status = %q, want sent", status)
+	}
+}
+
+func TestFileBroadcastStoreDamagedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broadcast.log")
+	// A malformed line, an unknown status, and a crash while recording the
+	// outcome for user 3, whose pending status was already written.
+	log := "user:1 sent\ngarbage\nuser:2 bogus\n user:5\nuser:3 pending\nuser:3 "
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := OpenFileBroadcastStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for key, want := range map[string]DeliveryStatus{
+		"user:1":  DeliverySent,
+		"user:2":  "",
+		"user:3":  DeliveryPending,
+		"garbage": "",
+		"":        "",
+	} {
+		if got, _ := store.Status(ctx, key); got != want {
+			t.Errorf("Status(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	if err := store.SetStatus(ctx, "user:4", DeliverySent); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = OpenFileBroadcastStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if got, _ := store.Status(ctx, "user:4"); got != DeliverySent {
+		t.Errorf("user:4 status after reopen = %q, want sent", got)
+	}
+}
+
+func TestBroadcastCanceled(t *testing.T) {
+	c, sent := broadcastServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := c.Broadcast(ctx, recipients(BroadcastRecipient{UserID: 1}), &NewMessageBody{Text: Some("x")}, BroadcastOpts{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if report.Sent() != 0 || len(sent()) != 0 {
+		t.Errorf("nothing should be sent, got %v", sent())
+	}
+}
+
+type failingStore struct{ MemoryBroadcastStore }
+
+func (s *failingStore) SetStatus(context.Context, string, DeliveryStatus) error {
+	return errors.New("disk full")
+}
+
+func TestBroadcastStoreError(t *testing.T) {
+	c, sent := broadcastServer(t)
+	store := &failingStore{}
+
+	_, err := c.Broadcast(context.Background(), recipients(BroadcastRecipient{UserID: 1}), &NewMessageBody{Text: Some("x")},
+		BroadcastOpts{RatePerSecond: 1000, Store: store})
+	if err == nil {
+		t.Fatal("expected store error")
+	}
+	if len(sent()) != 0 {
+		t.Errorf("message sent without pending mark: %v", sent())
+	}
+}
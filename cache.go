@@ -0,0 +1,194 @@
+package maxigo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// cacheKind identifies the cached API result for a chat.
+type cacheKind uint8
+
+const (
+	cacheChat       cacheKind = iota // GetChat
+	cacheMembership                  // GetMembership
+	cacheAdmins                      // GetAdmins
+)
+
+type cacheKey struct {
+	chatID int64
+	kind   cacheKind
+}
+
+// cacheItem holds a cached result as JSON, so that every read decodes a
+// fresh copy that shares nothing with the cache or with other callers.
+type cacheItem struct {
+	value   []byte
+	expires time.Time
+}
+
+// Cache holds results of [Client.GetChat], [Client.GetMembership] and
+// [Client.GetAdmins] for a fixed TTL. Enable it with [WithCache].
+//
+// Entries of a chat are dropped when the client edits the chat or its admins
+// and when an update reports a change. Updates received with
+// [Client.GetUpdates] are observed automatically; pass webhook updates to
+// [Cache.Observe]. A nil
+// *Cache is valid and caches nothing. All methods are safe for concurrent use.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	items map[cacheKey]cacheItem
+	// gens counts invalidations per chat. A response is only stored if no
+	// invalidation happened while its request was in flight.
+	gens map[int64]uint64
+}
+
+func newCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:   ttl,
+		now:   time.Now,
+		items: make(map[cacheKey]cacheItem),
+		gens:  make(map[int64]uint64),
+	}
+}
+
+// Observe invalidates cached entries affected by an update. It accepts the
+// update types from types.go, by value or pointer, and raw update JSON
+// (json.RawMessage or []byte) as returned in [UpdateList]. Other updates
+// are ignored.
+//
+// Chat title changes, users and the bot joining or leaving a chat drop all
+// cached entries of that chat.
+func (c *Cache) Observe(update any) error {
+	if c == nil {
+		return nil
+	}
+	switch u := update.(type) {
+	case json.RawMessage:
+		return c.observeRaw(u)
+	case []byte:
+		return c.observeRaw(u)
+	case ChatTitleChangedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case *ChatTitleChangedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case UserAddedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case *UserAddedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case UserRemovedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case *UserRemovedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case BotAddedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case *BotAddedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case BotRemovedUpdate:
+		c.InvalidateChat(u.ChatID)
+	case *BotRemovedUpdate:
+		c.InvalidateChat(u.ChatID)
+	}
+	return nil
+}
+
+func (c *Cache) observeRaw(raw []byte) error {
+	var u struct {
+		UpdateType UpdateType `json:"update_type"`
+		ChatID     int64      `json:"chat_id"`
+	}
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return fmt.Errorf("decode update: %w", err)
+	}
+	switch u.UpdateType {
+	case UpdateChatTitleChanged, UpdateUserAdded, UpdateUserRemoved, UpdateBotAdded, UpdateBotRemoved:
+		c.InvalidateChat(u.ChatID)
+	}
+	return nil
+}
+
+// InvalidateChat drops all cached entries of a chat.
+func (c *Cache) InvalidateChat(chatID int64) {
+	c.invalidate(chatID, cacheChat, cacheMembership, cacheAdmins)
+}
+
+// Clear drops all cached entries.
+func (c *Cache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.items {
+		c.gens[key.chatID]++
+	}
+	clear(c.items)
+}
+
+func (c *Cache) invalidate(chatID int64, kinds ...cacheKind) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gens[chatID]++
+	for _, kind := range kinds {
+		delete(c.items, cacheKey{chatID, kind})
+	}
+}
+
+// generation returns the invalidation counter of a chat, to be passed to
+// cachePut after the request.
+func (c *Cache) generation(chatID int64) uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gens[chatID]
+}
+
+// cacheGet returns a copy of a cached value that has not expired.
+func cacheGet[T any](c *Cache, chatID int64, kind cacheKind) (*T, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	key := cacheKey{chatID, kind}
+	item, ok := c.items[key]
+	if ok && !c.now().Before(item.expires) {
+		delete(c.items, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	var v T
+	if err := json.Unmarshal(item.value, &v); err != nil {
+		return nil, false
+	}
+	return &v, true
+}
+
+// cachePut stores a copy of v unless the chat was invalidated after gen was
+// taken.
+func cachePut[T any](c *Cache, chatID int64, kind cacheKind, gen uint64, v *T) {
+	if c == nil {
+		return
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gens[chatID] != gen {
+		return
+	}
+	c.items[cacheKey{chatID, kind}] = cacheItem{value: value, expires: c.now().Add(c.ttl)}
+}
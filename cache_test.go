@@ -0,0 +1,263 @@
+package maxigo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheServer counts GET requests per path and answers them with a fixed
+// chat, membership or admin list. Other methods get an edited chat.
+func cacheServer(t *testing.T, opts ...Option) (*Client, func(path string) int) {
+	t.Helper()
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	c, _ := testClientWithOpts(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(t, w, Chat{ChatID: 5, Title: strPtr("edited")})
+			return
+		}
+		mu.Lock()
+		counts[r.URL.Path]++
+		mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/members/me"):
+			writeJSON(t, w, ChatMember{IsAdmin: true})
+		case strings.HasSuffix(r.URL.Path, "/members/admins"):
+			writeJSON(t, w, ChatAdminsList{Admins: []ChatAdmin{{UserID: 1}}})
+		case r.URL.Path == "/updates":
+			writeJSON(t, w, UpdateList{Updates: []json.RawMessage{
+				json.RawMessage(`{"update_type":"message_created","timestamp":1,"message":{}}`),
+				json.RawMessage(`{"update_type":"chat_title_changed","timestamp":2,"chat_id":5,"title":"New"}`),
+			}})
+		default:
+			writeJSON(t, w, Chat{ChatID: 5, Title: strPtr("Team"), Participants: map[string]int64{"1": 10}})
+		}
+	}, opts...)
+	return c, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[path]
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c, count := cacheServer(t)
+	for range 2 {
+		if _, err := c.GetChat(context.Background(), 5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count("/chats/5") != 2 {
+		t.Errorf("requests = %d, want 2 without cache", count("/chats/5"))
+	}
+	if c.Cache() != nil || c.Cache().Observe(UserAddedUpdate{ChatID: 5}) != nil {
+		t.Error("nil cache should be usable")
+	}
+}
+
+func TestCacheHitAndExpiry(t *testing.T) {
+	c, count := cacheServer(t, WithCache(time.Minute))
+	now := time.Now()
+	c.Cache().now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 3 {
+		chat, err := c.GetChat(ctx, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *chat.Title != "Team" {
+			t.Errorf("Title = %q", *chat.Title)
+		}
+		if _, err := c.GetMembership(ctx, 5); err != nil {
+			t.Fatal(err)
+		}
+		admins, err := c.GetAdmins(ctx, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		admins.Admins[0].UserID = 99 // must not change the cached list
+	}
+	for _, path := range []string{"/chats/5", "/chats/5/members/me", "/chats/5/members/admins"} {
+		if count(path) != 1 {
+			t.Errorf("%s requests = %d, want 1", path, count(path))
+		}
+	}
+	if admins, _ := c.GetAdmins(ctx, 5); admins.Admins[0].UserID != 1 {
+		t.Error("cached admin list was modified by the caller")
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := c.GetChat(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if count("/chats/5") != 2 {
+		t.Errorf("requests = %d, want 2 after TTL", count("/chats/5"))
+	}
+}
+
+func TestCacheObserve(t *testing.T) {
+	raw := func(s string) json.RawMessage { return json.RawMessage(s) }
+	tests := []struct {
+		name       string
+		update     any
+		invalidate bool
+	}{
+		{"title changed", ChatTitleChangedUpdate{ChatID: 5, Title: "New"}, true},
+		{"user added ptr", &UserAddedUpdate{ChatID: 5}, true},
+		{"user removed", UserRemovedUpdate{ChatID: 5}, true},
+		{"bot added", &BotAddedUpdate{ChatID: 5}, true},
+		{"bot removed", BotRemovedUpdate{ChatID: 5}, true},
+		{"raw title", raw(`{"update_type":"chat_title_changed","chat_id":5,"title":"x"}`), true},
+		{"raw user removed", []byte(`{"update_type":"user_removed","chat_id":5}`), true},
+		{"other chat", UserAddedUpdate{ChatID: 6}, false},
+		{"message", MessageCreatedUpdate{}, false},
+		{"raw message", raw(`{"update_type":"message_created","message":{}}`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, count := cacheServer(t, WithCache(time.Minute))
+			ctx := context.Background()
+			if _, err := c.GetChat(ctx, 5); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Cache().Observe(tt.update); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.GetChat(ctx, 5); err != nil {
+				t.Fatal(err)
+			}
+			want := 1
+			if tt.invalidate {
+				want = 2
+			}
+			if count("/chats/5") != want {
+				t.Errorf("requests = %d, want %d", count("/chats/5"), want)
+			}
+		})
+	}
+
+	c, _ := cacheServer(t, WithCache(time.Minute))
+	if err := c.Cache().Observe(json.RawMessage(`{`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestCacheObservesGetUpdates(t *testing.T) {
+	c, count := cacheServer(t, WithCache(time.Minute))
+	ctx := context.Background()
+	if _, err := c.GetChat(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetUpdates(ctx, GetUpdatesOpts{Timeout: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetChat(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if count("/chats/5") != 2 {
+		t.Errorf("requests = %d, want 2 after chat_title_changed", count("/chats/5"))
+	}
+}
+
+func TestCacheReturnsCopies(t *testing.T) {
+	c, _ := cacheServer(t, WithCache(time.Minute))
+	ctx := context.Background()
+
+	first, err := c.GetChat(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*first.Title = "changed"
+	first.Participants["2"] = 20
+	for range 2 {
+		chat, err := c.GetChat(ctx, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *chat.Title != "Team" || len(chat.Participants) != 1 {
+			t.Errorf("cached chat was modified by the caller: %q, %v", *chat.Title, chat.Participants)
+		}
+		*chat.Title = "changed again"
+		chat.Participants["3"] = 30
+	}
+}
+
+func TestCacheInvalidatedByEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(c *Client) error
+		path string
+	}{
+		{"EditChat", func(c *Client) error {
+			_, err := c.EditChat(context.Background(), 5, &ChatPatch{Title: Some("edited")})
+			return err
+		}, "/chats/5"},
+		{"SetAdmins", func(c *Client) error {
+			_, err := c.SetAdmins(context.Background(), 5, &ChatAdminsList{Admins: []ChatAdmin{{UserID: 2}}})
+			return err
+		}, "/chats/5/members/admins"},
+		{"RemoveAdmin", func(c *Client) error {
+			_, err := c.RemoveAdmin(context.Background(), 5, 1)
+			return err
+		}, "/chats/5/members/admins"},
+		{"PinMessage", func(c *Client) error {
+			_, err := c.PinMessage(context.Background(), 5, &PinMessageBody{MessageID: "mid.1"})
+			return err
+		}, "/chats/5"},
+		{"UnpinMessage", func(c *Client) error {
+			_, err := c.UnpinMessage(context.Background(), 5)
+			return err
+		}, "/chats/5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, count := cacheServer(t, WithCache(time.Minute))
+			ctx := context.Background()
+			fetch := func() {
+				if _, err := c.GetChat(ctx, 5); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := c.GetAdmins(ctx, 5); err != nil {
+					t.Fatal(err)
+				}
+			}
+			fetch()
+			if err := tt.edit(c); err != nil {
+				t.Fatal(err)
+			}
+			fetch()
+			if count(tt.path) != 2 {
+				t.Errorf("%s requests = %d, want 2", tt.path, count(tt.path))
+			}
+		})
+	}
+}
+
+func TestCacheStaleResponseNotStored(t *testing.T) {
+	c, count := cacheServer(t, WithCache(time.Minute))
+	gen := c.cache.generation(5)
+	c.Cache().InvalidateChat(5) // invalidated while the request was in flight
+	cachePut(c.cache, 5, cacheChat, gen, &Chat{ChatID: 5, Title: strPtr("stale")})
+
+	chat, err := c.GetChat(context.Background(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *chat.Title != "Team" || count("/chats/5") != 1 {
+		t.Errorf("got %q from cache, want fresh request", *chat.Title)
+	}
+
+	c.Cache().Clear()
+	if _, err := c.GetChat(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+	if count("/chats/5") != 2 {
+		t.Errorf("requests = %d, want 2 after Clear", count("/chats/5"))
+	}
+}
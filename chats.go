@@ -10,13 +10,20 @@ import (
 )
 
 // GetChat returns info about a chat by its ID.
+// The result is cached if [WithCache] is enabled.
 // Corresponds to GET /chats/{chatId}.
 func (c *Client) GetChat(ctx context.Context, chatID int64) (*Chat, error) {
+	if chat, ok := cacheGet[Chat](c.cache, chatID, cacheChat); ok {
+		return chat, nil
+	}
+	gen := c.cache.generation(chatID)
+
 	var result Chat
 	path := fmt.Sprintf("/chats/%d", chatID)
 	if err := c.do(ctx, "GetChat", http.MethodGet, path, nil, nil, &result); err != nil {
 		return nil, err
 	}
+	cachePut(c.cache, chatID, cacheChat, gen, &result)
 	return &result, nil
 }
 
@@ -60,9 +67,15 @@ func (c *Client) GetChats(ctx context.Context, opts GetChatsOpts) (*ChatList, er
 // EditChat edits a chat's info (title, icon, pin).
 // Corresponds to PATCH /chats/{chatId}.
 func (c *Client) EditChat(ctx context.Context, chatID int64, patch *ChatPatch) (*Chat, error) {
+	if err := c.validateRequest("EditChat", func() *Error { return patch.validate() }); err != nil {
+		return nil, err
+	}
+
 	var result Chat
 	path := fmt.Sprintf("/chats/%d", chatID)
-	if err := c.do(ctx, "EditChat", http.MethodPatch, path, nil, patch, &result); err != nil {
+	err := c.do(ctx, "EditChat", http.MethodPatch, path, nil, patch, &result)
+	c.cache.InvalidateChat(chatID)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -73,7 +86,9 @@ func (c *Client) EditChat(ctx context.Context, chatID int64, patch *ChatPatch) (
 func (c *Client) DeleteChat(ctx context.Context, chatID int64) (*SimpleQueryResult, error) {
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d", chatID)
-	if err := c.do(ctx, "DeleteChat", http.MethodDelete, path, nil, nil, &result); err != nil {
+	err := c.do(ctx, "DeleteChat", http.MethodDelete, path, nil, nil, &result)
+	c.cache.InvalidateChat(chatID)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -117,13 +132,20 @@ func (c *Client) GetMembers(ctx context.Context, chatID int64, opts GetMembersOp
 
 // GetAdmins returns all administrators of a chat.
 // The bot must be an administrator in the chat.
+// The result is cached if [WithCache] is enabled.
 // Corresponds to GET /chats/{chatId}/members/admins.
 func (c *Client) GetAdmins(ctx context.Context, chatID int64) (*ChatAdminsList, error) {
+	if admins, ok := cacheGet[ChatAdminsList](c.cache, chatID, cacheAdmins); ok {
+		return admins, nil
+	}
+	gen := c.cache.generation(chatID)
+
 	var result ChatAdminsList
 	path := fmt.Sprintf("/chats/%d/members/admins", chatID)
 	if err := c.do(ctx, "GetAdmins", http.MethodGet, path, nil, nil, &result); err != nil {
 		return nil, err
 	}
+	cachePut(c.cache, chatID, cacheAdmins, gen, &result)
 	return &result, nil
 }
 
@@ -133,7 +155,9 @@ func (c *Client) AddMembers(ctx context.Context, chatID int64, userIDs []int64)
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d/members", chatID)
 	body := UserIDsList{UserIDs: userIDs}
-	if err := c.do(ctx, "AddMembers", http.MethodPost, path, nil, body, &result); err != nil {
+	err := c.do(ctx, "AddMembers", http.MethodPost, path, nil, body, &result)
+	c.cache.InvalidateChat(chatID)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -151,7 +175,9 @@ func (c *Client) RemoveMember(ctx context.Context, chatID int64, userID int64, b
 
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d/members", chatID)
-	if err := c.do(ctx, "RemoveMember", http.MethodDelete, path, q, nil, &result); err != nil {
+	err := c.do(ctx, "RemoveMember", http.MethodDelete, path, q, nil, &result)
+	c.cache.InvalidateChat(chatID)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -160,9 +186,15 @@ func (c *Client) RemoveMember(ctx context.Context, chatID int64, userID int64, b
 // SetAdmins sets chat administrators with specified permissions.
 // Corresponds to POST /chats/{chatId}/members/admins.
 func (c *Client) SetAdmins(ctx context.Context, chatID int64, admins *ChatAdminsList) (*SimpleQueryResult, error) {
+	if err := c.validateRequest("SetAdmins", func() *Error { return admins.validate() }); err != nil {
+		return nil, err
+	}
+
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d/members/admins", chatID)
-	if err := c.do(ctx, "SetAdmins", http.MethodPost, path, nil, admins, &result); err != nil {
+	err := c.do(ctx, "SetAdmins", http.MethodPost, path, nil, admins, &result)
+	c.cache.invalidate(chatID, cacheAdmins, cacheMembership)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -173,7 +205,9 @@ func (c *Client) SetAdmins(ctx context.Context, chatID int64, admins *ChatAdmins
 func (c *Client) RemoveAdmin(ctx context.Context, chatID int64, userID int64) (*SimpleQueryResult, error) {
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d/members/admins/%d", chatID, userID)
-	if err := c.do(ctx, "RemoveAdmin", http.MethodDelete, path, nil, nil, &result); err != nil {
+	err := c.do(ctx, "RemoveAdmin", http.MethodDelete, path, nil, nil, &result)
+	c.cache.invalidate(chatID, cacheAdmins, cacheMembership)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -192,13 +226,20 @@ func (c *Client) SendAction(ctx context.Context, chatID int64, action SenderActi
 }
 
 // GetMembership returns the bot's membership info for a chat.
+// The result is cached if [WithCache] is enabled.
 // Corresponds to GET /chats/{chatId}/members/me.
 func (c *Client) GetMembership(ctx context.Context, chatID int64) (*ChatMember, error) {
+	if member, ok := cacheGet[ChatMember](c.cache, chatID, cacheMembership); ok {
+		return member, nil
+	}
+	gen := c.cache.generation(chatID)
+
 	var result ChatMember
 	path := fmt.Sprintf("/chats/%d/members/me", chatID)
 	if err := c.do(ctx, "GetMembership", http.MethodGet, path, nil, nil, &result); err != nil {
 		return nil, err
 	}
+	cachePut(c.cache, chatID, cacheMembership, gen, &result)
 	return &result, nil
 }
 
@@ -207,7 +248,9 @@ func (c *Client) GetMembership(ctx context.Context, chatID int64) (*ChatMember,
 func (c *Client) LeaveChat(ctx context.Context, chatID int64) (*SimpleQueryResult, error) {
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d/members/me", chatID)
-	if err := c.do(ctx, "LeaveChat", http.MethodDelete, path, nil, nil, &result); err != nil {
+	err := c.do(ctx, "LeaveChat", http.MethodDelete, path, nil, nil, &result)
+	c.cache.InvalidateChat(chatID)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -218,7 +261,9 @@ func (c *Client) LeaveChat(ctx context.Context, chatID int64) (*SimpleQueryResul
 func (c *Client) PinMessage(ctx context.Context, chatID int64, body *PinMessageBody) (*SimpleQueryResult, error) {
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d/pin", chatID)
-	if err := c.do(ctx, "PinMessage", http.MethodPut, path, nil, body, &result); err != nil {
+	err := c.do(ctx, "PinMessage", http.MethodPut, path, nil, body, &result)
+	c.cache.invalidate(chatID, cacheChat)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -229,7 +274,9 @@ func (c *Client) PinMessage(ctx context.Context, chatID int64, body *PinMessageB
 func (c *Client) UnpinMessage(ctx context.Context, chatID int64) (*SimpleQueryResult, error) {
 	var result SimpleQueryResult
 	path := fmt.Sprintf("/chats/%d/pin", chatID)
-	if err := c.do(ctx, "UnpinMessage", http.MethodDelete, path, nil, nil, &result); err != nil {
+	err := c.do(ctx, "UnpinMessage", http.MethodDelete, path, nil, nil, &result)
+	c.cache.invalidate(chatID, cacheChat)
+	if err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -29,6 +29,9 @@ type Client struct {
 	token          string
 	timeout        time.Duration
 	retryIntervals []time.Duration // nil means retry disabled (default)
+	imageOpts      *ImageOpts      // nil means photo uploads are sent as-is (default)
+	validate       bool            // run Validate on request bodies before sending
+	cache          *Cache          // nil means chat info is not cached (default)
 }
 
 // New creates a new Max Bot API client with the given token.
@@ -57,6 +60,13 @@ func New(token string, opts ...Option) (*Client, error) {
 	return c, nil
 }
 
+// Cache returns the client's chat cache, or nil if [WithCache] was not used.
+// Updates from [Client.GetUpdates] keep it fresh automatically; pass webhook
+// updates to [Cache.Observe].
+func (c *Client) Cache() *Cache {
+	return c.cache
+}
+
 // do performs an HTTP request and decodes the JSON response into result.
 // If result is nil, the response body is discarded.
 // If the context has no deadline, the client's default timeout is applied.
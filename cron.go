@@ -0,0 +1,190 @@
+package maxigo
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron is wrapped by errors returned from [ParseCron].
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// CronSchedule is a parsed five-field cron expression. Create one with
+// [ParseCron].
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a day field starting with "*". As in standard cron, when
+	// both day fields are restricted a time matches if either one matches.
+	domAny, dowAny bool
+}
+
+type cronField struct {
+	min, max int
+	names    []string // names[i] is the name of value min+i
+}
+
+var (
+	cronMinute = cronField{min: 0, max: 59}
+	cronHour   = cronField{min: 0, max: 23}
+	cronDom    = cronField{min: 1, max: 31}
+	cronMonth  = cronField{min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec",
+	}}
+	// Day of week 7 is an alias of 0 (Sunday).
+	cronDow = cronField{min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// ParseCron parses a standard cron expression with five space-separated
+// fields: minute, hour, day of month, month and day of week. Each field is
+// "*", a value, a range "a-b", a step "*/n" or "a-b/n", or a comma-separated
+// list of those. Months and weekdays also accept three-letter English names.
+//
+//	ParseCron("0 9 * * mon-fri") // 09:00 every weekday
+//	ParseCron("*/15 * * * *")    // every 15 minutes
+//
+// The expression has no time zone; [CronSchedule.Next] uses the location of
+// the time it is given.
+func ParseCron(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q: want 5 fields, got %d", ErrInvalidCron, expr, len(fields))
+	}
+
+	var s CronSchedule
+	var err error
+	for i, p := range []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&s.minute, cronMinute},
+		{&s.hour, cronHour},
+		{&s.dom, cronDom},
+		{&s.month, cronMonth},
+		{&s.dow, cronDow},
+	} {
+		if *p.bits, err = p.field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCron, expr, err)
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+func (f cronField) parse(s string) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("bad range %q", part)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %q out of range %d-%d", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// cronSearchLimit bounds the search in [CronSchedule.Next] for expressions
+// that never match, such as "0 0 30 2 *".
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// Next returns the first time after t that matches the schedule, in t's
+// location, or the zero time if there is none within five years. Local times
+// skipped by a daylight saving transition do not match.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(cronSearchLimit)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		// Jumps to local midnight may land before t when midnight falls into
+		// a daylight saving gap; advancing to the next hour always moves on.
+		nextHour := t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		if s.month&(1<<int(t.Month())) == 0 {
+			t = cronLater(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc), nextHour)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = cronLater(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc), nextHour)
+			continue
+		}
+		if s.hour&(1<<t.Hour()) == 0 {
+			t = nextHour
+			continue
+		}
+		if s.minute&(1<<t.Minute()) == 0 {
+			// Jump to the next matching minute of this hour, if any.
+			rest := s.minute >> t.Minute()
+			if rest == 0 {
+				t = nextHour
+			} else {
+				t = t.Add(time.Duration(bits.TrailingZeros64(rest)) * time.Minute)
+			}
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// cronLater returns next if it is after t, and fallback otherwise.
+func cronLater(t, next, fallback time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return fallback
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
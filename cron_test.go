@@ -0,0 +1,62 @@
+package maxigo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	date := func(loc *time.Location, y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", date(time.UTC, 2026, 1, 1, 10, 0).Add(30 * time.Second), date(time.UTC, 2026, 1, 1, 10, 1)},
+		{"step", "*/15 * * * *", date(time.UTC, 2026, 1, 1, 10, 16), date(time.UTC, 2026, 1, 1, 10, 30)},
+		{"next hour", "5 * * * *", date(time.UTC, 2026, 1, 1, 10, 6), date(time.UTC, 2026, 1, 1, 11, 5)},
+		// 2026-10-16 is a Friday.
+		{"weekday morning", "0 9 * * mon-fri", date(moscow, 2026, 10, 16, 9, 0), date(moscow, 2026, 10, 19, 9, 0)},
+		{"weekday same day", "0 9 * * 1-5", date(moscow, 2026, 10, 19, 8, 59), date(moscow, 2026, 10, 19, 9, 0)},
+		{"sunday as 7", "0 0 * * 7", date(time.UTC, 2026, 10, 16, 0, 0), date(time.UTC, 2026, 10, 18, 0, 0)},
+		{"list and names", "30 8,20 1 jan,JUL *", date(time.UTC, 2026, 1, 1, 9, 0), date(time.UTC, 2026, 1, 1, 20, 30)},
+		{"dom or dow", "0 0 13 * fri", date(time.UTC, 2026, 3, 1, 0, 0), date(time.UTC, 2026, 3, 6, 0, 0)},
+		{"leap day", "0 0 29 2 *", date(time.UTC, 2026, 3, 1, 0, 0), date(time.UTC, 2028, 2, 29, 0, 0)},
+		{"never", "0 0 30 2 *", date(time.UTC, 2026, 1, 1, 0, 0), time.Time{}},
+		// 2026-03-08 02:30 does not exist in New York.
+		{"dst gap", "30 2 * * *", date(newYork, 2026, 3, 7, 3, 0), date(newYork, 2026, 3, 9, 2, 30)},
+		{"dst overlap", "30 1 * * *", date(newYork, 2026, 10, 31, 2, 0), date(newYork, 2026, 11, 1, 1, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "x * * * *", "* * * * foo"} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("ParseCron(%q) err = %v, want ErrInvalidCron", expr, err)
+		}
+	}
+}
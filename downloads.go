@@ -0,0 +1,147 @@
+package maxigo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// DownloadOpts holds optional parameters for [Client.DownloadAttachmentWithOpts].
+type DownloadOpts struct {
+	// MaxSize limits the number of bytes written to the destination.
+	// Larger content fails with [ErrFetch] after MaxSize bytes were written.
+	// 0 uses the default limit of 50 MB.
+	MaxSize int64
+	// MaxVideoHeight selects the video resolution: the best MP4 rendition
+	// with a height at or under this value is downloaded. 0 picks the best
+	// available rendition.
+	MaxVideoHeight int
+}
+
+// DownloadAttachment downloads the content of a received attachment into w
+// and returns the number of bytes written.
+//
+// Supported attachments are [*PhotoAttachment], [*VideoAttachment],
+// [*AudioAttachment] and [*FileAttachment]. For videos, playback URLs are
+// resolved via [Client.GetVideoDetails] and the best available MP4 rendition
+// is used. Downloads larger than 50 MB, failed video lookups and non-HTTP
+// URLs fail with [ErrFetch].
+//
+// Use [Client.DownloadAttachmentWithOpts] to change the size limit or video resolution.
+func (c *Client) DownloadAttachment(ctx context.Context, att Attachment, w io.Writer) (int64, error) {
+	return c.DownloadAttachmentWithOpts(ctx, att, w, DownloadOpts{})
+}
+
+// DownloadAttachmentWithOpts is like [Client.DownloadAttachment] but accepts
+// a size limit and a preferred video resolution.
+//
+// If the context has no deadline, the client's default timeout is applied
+// to the whole download.
+func (c *Client) DownloadAttachmentWithOpts(ctx context.Context, att Attachment, w io.Writer, opts DownloadOpts) (int64, error) {
+	const op = "DownloadAttachment"
+
+	ctx, cancel := c.ensureTimeout(ctx)
+	defer cancel()
+
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = maxFetchSize
+	}
+
+	rawURL, err := c.attachmentURL(ctx, att, opts.MaxVideoHeight)
+	if err != nil {
+		return 0, err
+	}
+	if rawURL == "" {
+		return 0, fetchError(op, 0, fmt.Sprintf("%s attachment has no download URL", att.GetType()))
+	}
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return 0, fetchError(op, 0, fmt.Sprintf("unsupported download URL %q: only http and https are allowed", rawURL))
+	}
+
+	// Read one byte past the limit to detect oversized content.
+	body, _, err := c.fetchURL(ctx, op, rawURL, maxSize+1)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = body.Close() }()
+
+	n, err := io.Copy(w, io.LimitReader(body, maxSize))
+	if err == nil {
+		// Probe for content past the limit; it is never written to w.
+		var probe [1]byte
+		var extra int
+		if extra, err = io.ReadFull(body, probe[:]); extra > 0 {
+			return n, fetchError(op, 0, fmt.Sprintf("attachment exceeds size limit of %d bytes", maxSize))
+		}
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return n, timeoutError(op, ctx.Err())
+		}
+		return n, &Error{Kind: ErrFetch, Op: op, Message: err.Error(), Err: err}
+	}
+	return n, nil
+}
+
+// attachmentURL returns the URL to download the attachment content from.
+func (c *Client) attachmentURL(ctx context.Context, att Attachment, maxVideoHeight int) (string, error) {
+	switch a := att.(type) {
+	case *PhotoAttachment:
+		return a.Payload.URL, nil
+	case *AudioAttachment:
+		return a.Payload.URL, nil
+	case *FileAttachment:
+		return a.Payload.URL, nil
+	case *VideoAttachment:
+		details, err := c.GetVideoDetails(ctx, a.Payload.Token)
+		if err != nil {
+			e := fetchError("DownloadAttachment", 0, "get video details: "+err.Error())
+			e.Err = err
+			return "", e
+		}
+		return pickVideoURL(details.URLs, maxVideoHeight), nil
+	case nil:
+		return "", fetchError("DownloadAttachment", 0, "attachment is nil")
+	default:
+		return "", fetchError("DownloadAttachment", 0, fmt.Sprintf("unsupported attachment type %q", att.GetType()))
+	}
+}
+
+// pickVideoURL returns the best MP4 rendition with a height at or under
+// maxHeight (0 means no limit). If every rendition is taller than maxHeight,
+// the smallest one is returned. Returns "" if no MP4 rendition is available.
+func pickVideoURL(urls *VideoURLs, maxHeight int) string {
+	if urls == nil {
+		return ""
+	}
+
+	// Ordered from best to worst.
+	renditions := []struct {
+		height int
+		url    *string
+	}{
+		{1080, urls.MP41080},
+		{720, urls.MP4720},
+		{480, urls.MP4480},
+		{360, urls.MP4360},
+		{240, urls.MP4240},
+		{144, urls.MP4144},
+	}
+
+	var smallest string
+	for _, r := range renditions {
+		if r.url == nil || *r.url == "" {
+			continue
+		}
+		if maxHeight <= 0 || r.height <= maxHeight {
+			return *r.url
+		}
+		smallest = *r.url
+	}
+	return smallest
+}
@@ -0,0 +1,194 @@
+package maxigo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDownloadAttachmentPhoto(t *testing.T) {
+	c, srv := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/photo.jpg" {
+			t.Errorf("path = %q, want /files/photo.jpg", r.URL.Path)
+		}
+		_, _ = w.Write([]byte("image bytes"))
+	})
+
+	att := &PhotoAttachment{
+		AttachmentType: AttachmentType{Type: "image"},
+		Payload:        PhotoAttachmentPayload{URL: srv.URL + "/files/photo.jpg"},
+	}
+
+	var buf bytes.Buffer
+	n, err := c.DownloadAttachment(context.Background(), att, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != int64(len("image bytes")) {
+		t.Errorf("n = %d, want %d", n, len("image bytes"))
+	}
+	if buf.String() != "image bytes" {
+		t.Errorf("content = %q, want %q", buf.String(), "image bytes")
+	}
+}
+
+func TestDownloadAttachmentVideo(t *testing.T) {
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/videos/vid-token":
+			writeJSON(t, w, VideoAttachmentDetails{
+				Token: "vid-token",
+				URLs: &VideoURLs{
+					MP41080: strPtr("http://" + r.Host + "/v/1080.mp4"),
+					MP4720:  strPtr("http://" + r.Host + "/v/720.mp4"),
+					MP4360:  strPtr("http://" + r.Host + "/v/360.mp4"),
+				},
+			})
+		case "/v/720.mp4":
+			_, _ = w.Write([]byte("720p"))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	att := &VideoAttachment{
+		AttachmentType: AttachmentType{Type: "video"},
+		Payload:        MediaAttachmentPayload{Token: "vid-token"},
+	}
+
+	var buf bytes.Buffer
+	_, err := c.DownloadAttachmentWithOpts(context.Background(), att, &buf, DownloadOpts{MaxVideoHeight: 800})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "720p" {
+		t.Errorf("content = %q, want %q", buf.String(), "720p")
+	}
+}
+
+func TestDownloadAttachmentTooLarge(t *testing.T) {
+	c, srv := testClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	})
+
+	att := &FileAttachment{
+		AttachmentType: AttachmentType{Type: "file"},
+		Payload:        FileAttachmentPayload{URL: srv.URL + "/big.bin"},
+	}
+
+	var buf bytes.Buffer
+	n, err := c.DownloadAttachmentWithOpts(context.Background(), att, &buf, DownloadOpts{MaxSize: 10})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if n != 10 || buf.Len() != 10 {
+		t.Errorf("written = %d (n = %d), want 10", buf.Len(), n)
+	}
+
+	// Content of exactly MaxSize bytes fits.
+	buf.Reset()
+	if n, err := c.DownloadAttachmentWithOpts(context.Background(), att, &buf, DownloadOpts{MaxSize: 100}); err != nil || n != 100 {
+		t.Errorf("MaxSize 100: n = %d, err = %v", n, err)
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if e.Kind != ErrFetch {
+		t.Errorf("Kind = %v, want ErrFetch", e.Kind)
+	}
+}
+
+func TestDownloadAttachmentFetchError(t *testing.T) {
+	c, srv := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	att := &AudioAttachment{
+		AttachmentType: AttachmentType{Type: "audio"},
+		Payload:        MediaAttachmentPayload{URL: srv.URL + "/gone.mp3"},
+	}
+
+	_, err := c.DownloadAttachment(context.Background(), att, &bytes.Buffer{})
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if e.Kind != ErrFetch {
+		t.Errorf("Kind = %v, want ErrFetch", e.Kind)
+	}
+	if e.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want 404", e.StatusCode)
+	}
+}
+
+func TestDownloadAttachmentUnsupported(t *testing.T) {
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/videos/") {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		http.NotFound(w, r)
+	})
+
+	tests := []struct {
+		name string
+		att  Attachment
+	}{
+		{"location", &LocationAttachment{AttachmentType: AttachmentType{Type: "location"}}},
+		{"file scheme", &FileAttachment{
+			AttachmentType: AttachmentType{Type: "file"},
+			Payload:        FileAttachmentPayload{URL: "file:///etc/passwd"},
+		}},
+		{"video details", &VideoAttachment{
+			AttachmentType: AttachmentType{Type: "video"},
+			Payload:        MediaAttachmentPayload{Token: "gone"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.DownloadAttachment(context.Background(), tt.att, &bytes.Buffer{})
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *Error, got %T", err)
+			}
+			if e.Kind != ErrFetch {
+				t.Errorf("Kind = %v, want ErrFetch", e.Kind)
+			}
+		})
+	}
+}
+
+func TestPickVideoURL(t *testing.T) {
+	urls := &VideoURLs{
+		MP41080: strPtr("1080"),
+		MP4480:  strPtr("480"),
+		MP4240:  strPtr("240"),
+		HLS:     strPtr("hls"),
+	}
+
+	tests := []struct {
+		name      string
+		urls      *VideoURLs
+		maxHeight int
+		want      string
+	}{
+		{"no limit picks best", urls, 0, "1080"},
+		{"exact match", urls, 480, "480"},
+		{"between renditions", urls, 720, "480"},
+		{"below all falls back to smallest", urls, 100, "240"},
+		{"nil URLs", nil, 0, ""},
+		{"HLS only", &VideoURLs{HLS: strPtr("hls")}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickVideoURL(tt.urls, tt.maxHeight); got != tt.want {
+				t.Errorf("pickVideoURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -24,11 +24,15 @@ const (
 	ErrNetwork
 	// ErrTimeout indicates the request timed out or the context deadline was exceeded.
 	ErrTimeout
-	// ErrDecode indicates a JSON marshal or unmarshal failure.
+	// ErrDecode indicates a JSON marshal or unmarshal failure, or an image
+	// that could not be decoded when [WithImagePreprocessing] is enabled.
 	ErrDecode
 	// ErrFetch indicates a failure when downloading from an external URL
-	// (used by UploadPhotoFromURL and UploadMediaFromURL).
+	// (used by UploadPhotoFromURL, UploadMediaFromURL and DownloadAttachment).
 	ErrFetch
+	// ErrValidation indicates a request that failed offline validation
+	// (see [WithValidation]). The invalid field is reported in Error.Field.
+	ErrValidation
 )
 
 // String returns a human-readable name for the error kind.
@@ -44,6 +48,8 @@ func (k ErrorKind) String() string {
 		return "decode"
 	case ErrFetch:
 		return "fetch"
+	case ErrValidation:
+		return "validation"
 	default:
 		return "unknown"
 	}
@@ -71,6 +77,9 @@ type Error struct {
 	Message string
 	// Op is the client operation that failed (e.g. "SendMessage", "GetChat").
 	Op string
+	// Field is the path of the invalid field (e.g. "attachments[0].payload.buttons[1][0].url").
+	// Only set when Kind is ErrValidation; empty if the request as a whole is invalid.
+	Field string
 	// Err is the underlying error, if any.
 	Err error
 }
@@ -80,6 +89,9 @@ func (e *Error) Error() string {
 	if e.Kind == ErrAPI || e.Kind == ErrFetch {
 		return fmt.Sprintf("%s: %s error %d: %s", e.Op, e.Kind, e.StatusCode, e.Message)
 	}
+	if e.Kind == ErrValidation && e.Field != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", e.Op, e.Kind, e.Field, e.Message)
+	}
 	if e.Message != "" {
 		return fmt.Sprintf("%s: %s: %s", e.Op, e.Kind, e.Message)
 	}
@@ -142,6 +154,15 @@ func fetchError(op string, statusCode int, message string) *Error {
 	}
 }
 
+func validationError(op, field, message string) *Error {
+	return &Error{
+		Kind:    ErrValidation,
+		Message: message,
+		Op:      op,
+		Field:   field,
+	}
+}
+
 // isRetryable reports whether the error is a retryable API error.
 // An error is retryable if it is an [*Error] with Kind [ErrAPI] and either:
 //   - the HTTP status code is 429 (Too Many Requests), or
@@ -158,3 +179,10 @@ func isRetryable(err error) bool {
 	return strings.Contains(e.Message, "not.ready") ||
 		strings.Contains(e.Message, "not.processed")
 }
+
+// isNotFound reports whether err is an API error with HTTP status 404,
+// e.g. for a message that was already deleted.
+func isNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == ErrAPI && e.StatusCode == http.StatusNotFound
+}
@@ -17,6 +17,7 @@ func TestErrorKindString(t *testing.T) {
 		{ErrTimeout, "timeout"},
 		{ErrDecode, "decode"},
 		{ErrFetch, "fetch"},
+		{ErrValidation, "validation"},
 		{ErrorKind(99), "unknown"},
 	}
 	for _, tt := range tests {
@@ -52,6 +53,16 @@ func TestErrorError(t *testing.T) {
 			err:  decodeError("SendMessage", fmt.Errorf("unexpected EOF")),
 			want: "SendMessage: decode: unexpected EOF",
 		},
+		{
+			name: "validation error with field",
+			err:  validationError("SendMessage", "text", "must not be empty"),
+			want: "SendMessage: validation: text: must not be empty",
+		},
+		{
+			name: "validation error without field",
+			err:  validationError("EditChat", "", "nothing to update"),
+			want: "EditChat: validation: nothing to update",
+		},
 		{
 			name: "error without message",
 			err:  &Error{Kind: ErrNetwork, Op: "GetBot"},
@@ -140,4 +140,20 @@ func ExampleNewInlineKeyboardAttachment() {
 	// Output: inline_keyboard
 }
 
+func ExampleNewTextBuilder() {
+	body, err := maxigo.NewTextBuilder(maxigo.FormatHTML).
+		Text("Hello, ").
+		Mention("<Alice>", 42).
+		Text("! Order ").
+		Bold("#123").
+		Text(" is ready.").
+		Build()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(body.Text.Value)
+	// Output: Hello, <a href="max://user/42">&lt;Alice&gt;</a>! Order <b>#123</b> is ready.
+}
+
 func strPtr(s string) *string { return &s }
@@ -0,0 +1,361 @@
+package maxigo
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat selects the output format of [Client.ExportChat].
+type ExportFormat int
+
+const (
+	// ExportJSONL writes one raw [Message] JSON object per line.
+	ExportJSONL ExportFormat = iota
+	// ExportHTML writes a self-contained HTML transcript.
+	ExportHTML
+)
+
+// exportCheckpointEvery is the number of messages between checkpoint saves.
+const exportCheckpointEvery = messagesPageSize
+
+// ExportOpts holds optional parameters for [Client.ExportChat].
+type ExportOpts struct {
+	// Format is the output format (default ExportJSONL).
+	Format ExportFormat
+	// From and To limit the exported range as in [Client.MessagesBetween].
+	From, To int64
+	// Checkpoint is the path of a checkpoint file. If the file exists, the
+	// export resumes after the last message recorded in it; open w in append
+	// mode in that case. The checkpoint is saved every 100 messages and when
+	// the export stops. Empty disables checkpoints.
+	Checkpoint string
+	// OnAttachment is called for every attachment of every exported message,
+	// e.g. to save it with [Client.DownloadAttachment]. In HTML transcripts a
+	// non-empty return value replaces the attachment URL (e.g. a local path).
+	// An error stops the export.
+	OnAttachment func(ctx context.Context, msg *Message, att Attachment) (string, error)
+	// Location is used for times in HTML transcripts (default UTC).
+	Location *time.Location
+}
+
+// exportCheckpoint is the state saved in ExportOpts.Checkpoint.
+type exportCheckpoint struct {
+	ChatID int64 `json:"chat_id"`
+	// Timestamp of the last exported message.
+	Timestamp int64 `json:"timestamp"`
+	// MIDs of exported messages with that timestamp, skipped on resume.
+	MIDs  []string `json:"mids"`
+	Count int      `json:"count"`
+	// Header is set once the HTML transcript header was written.
+	Header bool `json:"header,omitempty"`
+	Done   bool `json:"done"`
+}
+
+// ExportChat writes the history of a chat to w from the newest to the oldest
+// message, using [Client.MessagesBetween]. It returns the number of messages
+// written by this call.
+//
+// With a checkpoint, an interrupted export can be resumed by calling
+// ExportChat again with the same options; a completed export writes nothing.
+// Messages written after the last checkpoint save may be written again on
+// resume. If w has a Flush() error method (like [bufio.Writer]), it is
+// flushed before every checkpoint save.
+func (c *Client) ExportChat(ctx context.Context, chatID int64, w io.Writer, opts ExportOpts) (int, error) {
+	cp, err := loadExportCheckpoint(opts.Checkpoint)
+	if err != nil {
+		return 0, err
+	}
+	if cp == nil {
+		cp = &exportCheckpoint{ChatID: chatID}
+	}
+	if cp.ChatID != chatID {
+		return 0, fmt.Errorf("export: checkpoint %s is for chat %d, not %d", opts.Checkpoint, cp.ChatID, chatID)
+	}
+	if cp.Done {
+		return 0, nil
+	}
+
+	from := opts.From
+	skip := make(map[string]bool)
+	if cp.Count > 0 {
+		from = cp.Timestamp
+		for _, mid := range cp.MIDs {
+			skip[mid] = true
+		}
+	}
+
+	e := &exporter{ctx: ctx, w: w, opts: opts}
+	save := func() error {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return fmt.Errorf("export: %w", err)
+			}
+		}
+		return saveExportCheckpoint(opts.Checkpoint, cp)
+	}
+
+	if opts.Format == ExportHTML && !cp.Header && cp.Count == 0 {
+		if err := e.writeString(htmlTranscriptHeader(chatID)); err != nil {
+			return 0, err
+		}
+		cp.Header = true
+		if err := save(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := 0
+	for msg, err := range c.MessagesBetween(ctx, chatID, from, opts.To) {
+		if err != nil {
+			return n, errors.Join(err, save())
+		}
+		if skip[msg.Body.MID] {
+			continue
+		}
+
+		if err := e.write(&msg); err != nil {
+			return n, errors.Join(err, save())
+		}
+
+		if msg.Timestamp != cp.Timestamp {
+			cp.Timestamp = msg.Timestamp
+			cp.MIDs = cp.MIDs[:0]
+		}
+		cp.MIDs = append(cp.MIDs, msg.Body.MID)
+		cp.Count++
+		n++
+
+		if n%exportCheckpointEvery == 0 {
+			if err := save(); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	if opts.Format == ExportHTML {
+		if err := e.writeString(htmlTranscriptFooter); err != nil {
+			return n, errors.Join(err, save())
+		}
+	}
+	cp.Done = true
+	return n, save()
+}
+
+type exporter struct {
+	ctx  context.Context
+	w    io.Writer
+	opts ExportOpts
+}
+
+func (e *exporter) writeString(s string) error {
+	if _, err := io.WriteString(e.w, s); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	return nil
+}
+
+func (e *exporter) write(msg *Message) error {
+	atts, err := msg.Body.ParseAttachments()
+	if err != nil {
+		return fmt.Errorf("export: message %s: %w", msg.Body.MID, err)
+	}
+	hrefs := make([]string, len(atts))
+	if e.opts.OnAttachment != nil {
+		for i, att := range atts {
+			if hrefs[i], err = e.opts.OnAttachment(e.ctx, msg, att); err != nil {
+				return fmt.Errorf("export: message %s: %w", msg.Body.MID, err)
+			}
+		}
+	}
+
+	if e.opts.Format != ExportHTML {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("export: message %s: %w", msg.Body.MID, err)
+		}
+		return e.writeString(string(data) + "\n")
+	}
+	return e.writeString(e.renderHTML(msg, atts, hrefs))
+}
+
+func (e *exporter) renderHTML(msg *Message, atts []Attachment, hrefs []string) string {
+	loc := e.opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	ts := time.UnixMilli(msg.Timestamp).In(loc)
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="message" id="` + EscapeHTML(msg.Body.MID) + `">` + "\n")
+	sb.WriteString(`<div class="meta"><span class="sender">` + EscapeHTML(senderName(msg.Sender)) + `</span> `)
+	sb.WriteString(`<time datetime="` + ts.Format(time.RFC3339) + `">` + ts.Format("2006-01-02 15:04") + "</time></div>\n")
+
+	if msg.Link != nil {
+		kind := "Reply to"
+		if msg.Link.Type == LinkForward {
+			kind = "Forwarded from"
+		}
+		sb.WriteString(`<blockquote class="link">` + kind + " " + EscapeHTML(senderName(msg.Link.Sender)))
+		if text := msg.Link.Message.Render(FormatHTML); text != "" {
+			sb.WriteString(": " + strings.ReplaceAll(text, "\n", "<br>"))
+		}
+		sb.WriteString("</blockquote>\n")
+	}
+	if text := msg.Body.Render(FormatHTML); text != "" {
+		sb.WriteString(`<div class="text">` + strings.ReplaceAll(text, "\n", "<br>\n") + "</div>\n")
+	}
+	for i, att := range atts {
+		if s := renderAttachmentHTML(att, hrefs[i]); s != "" {
+			sb.WriteString(`<div class="attachment">` + s + "</div>\n")
+		}
+	}
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// renderAttachmentHTML renders a parsed attachment. href, if set, replaces
+// the attachment's own URL.
+func renderAttachmentHTML(att Attachment, href string) string {
+	link := func(url, text string) string {
+		url = cmp.Or(href, url)
+		if url == "" {
+			return EscapeHTML(text)
+		}
+		return `<a href="` + EscapeHTML(url) + `">` + EscapeHTML(text) + "</a>"
+	}
+	img := func(url, alt string) string {
+		url = cmp.Or(href, url)
+		if url == "" {
+			return "[" + EscapeHTML(alt) + "]"
+		}
+		return `<img src="` + EscapeHTML(url) + `" alt="` + EscapeHTML(alt) + `">`
+	}
+
+	switch a := att.(type) {
+	case *PhotoAttachment:
+		return img(a.Payload.URL, "photo")
+	case *StickerAttachment:
+		return img(a.Payload.URL, "sticker")
+	case *VideoAttachment:
+		return link(a.Payload.URL, "Video")
+	case *AudioAttachment:
+		s := link(a.Payload.URL, "Audio")
+		if a.Transcription != nil {
+			s += ": " + EscapeHTML(*a.Transcription)
+		}
+		return s
+	case *FileAttachment:
+		return link(a.Payload.URL, cmp.Or(a.Filename, "File")) + " (" + strconv.FormatInt(a.Size, 10) + " bytes)"
+	case *ContactAttachment:
+		name := "Contact"
+		if a.Payload.MaxInfo != nil {
+			name = senderName(a.Payload.MaxInfo)
+		}
+		if phone := a.Payload.Phone(); phone != "" {
+			name += ", " + phone
+		}
+		return "Contact: " + EscapeHTML(name)
+	case *ShareAttachment:
+		title := "Link"
+		if a.Title != nil {
+			title = *a.Title
+		}
+		return link(a.Payload.URL.Value, title)
+	case *LocationAttachment:
+		return fmt.Sprintf("Location: %.6f, %.6f", a.Latitude, a.Longitude)
+	}
+	return ""
+}
+
+func senderName(u *User) string {
+	if u == nil {
+		return "Unknown"
+	}
+	name := u.FirstName
+	if u.LastName != nil && *u.LastName != "" {
+		name += " " + *u.LastName
+	}
+	return name
+}
+
+func htmlTranscriptHeader(chatID int64) string {
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Chat ` + strconv.FormatInt(chatID, 10) + `</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: auto; }
+.message { border-bottom: 1px solid #ddd; padding: .5em 0; }
+.meta { color: #666; font-size: .9em; }
+.sender { font-weight: bold; }
+.link { color: #555; border-left: 3px solid #ccc; margin: .3em 0; padding-left: .5em; }
+.attachment img { max-width: 100%; }
+</style>
+</head>
+<body>
+`
+}
+
+const htmlTranscriptFooter = "</body>\n</html>\n"
+
+func loadExportCheckpoint(path string) (*exportCheckpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("export: read checkpoint: %w", err)
+	}
+	var cp exportCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("export: parse checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// saveExportCheckpoint writes the checkpoint atomically.
+func saveExportCheckpoint(path string, cp *exportCheckpoint) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("export: save checkpoint: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("export: save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so readers never see a partially written file. The data is
+// synced before the rename, so a crash cannot leave an empty file behind.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err := errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
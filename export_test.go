@@ -0,0 +1,192 @@
+package maxigo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func exportHistory(n int) []Message {
+	history := make([]Message, n)
+	for i := range history {
+		history[i] = Message{
+			Sender:    &User{UserID: 1, FirstName: "Ann"},
+			Timestamp: int64(1_700_000_000_000 - i/2*1000),
+			Body:      MessageBody{MID: fmt.Sprintf("m%d", i), Text: strPtr(fmt.Sprintf("text %d", i))},
+		}
+	}
+	return history
+}
+
+func TestExportChatJSONL(t *testing.T) {
+	c, _ := historyServer(t, exportHistory(5))
+
+	var buf bytes.Buffer
+	n, err := c.ExportChat(context.Background(), 1, &buf, ExportOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("n = %d, want 5", n)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("lines = %d, want 5", len(lines))
+	}
+	var msg Message
+	if err := json.Unmarshal([]byte(lines[4]), &msg); err != nil {
+		t.Fatalf("line is not a message: %v", err)
+	}
+	if msg.Body.MID != "m4" || *msg.Body.Text != "text 4" {
+		t.Errorf("last message = %+v, want m4", msg.Body)
+	}
+}
+
+func TestExportChatHTML(t *testing.T) {
+	history := []Message{{
+		Sender:    &User{UserID: 1, FirstName: "Ann", LastName: strPtr("Lee")},
+		Timestamp: 1_700_000_000_000,
+		Body: MessageBody{
+			MID:    "m0",
+			Text:   strPtr("hi <all>\nsee this"),
+			Markup: []MarkupElement{{Type: MarkupStrong, From: 0, Length: 2}},
+			Attachments: []json.RawMessage{
+				json.RawMessage(`{"type":"image","payload":{"url":"https://cdn.example/p.jpg"}}`),
+				json.RawMessage(`{"type":"file","payload":{"url":"https://cdn.example/f"},"filename":"report.pdf","size":10}`),
+			},
+		},
+	}}
+	c, _ := historyServer(t, history)
+
+	var hooked []string
+	var buf bytes.Buffer
+	_, err := c.ExportChat(context.Background(), 1, &buf, ExportOpts{
+		Format: ExportHTML,
+		OnAttachment: func(ctx context.Context, msg *Message, att Attachment) (string, error) {
+			hooked = append(hooked, att.GetType())
+			if att.GetType() == "file" {
+				return "files/report.pdf", nil
+			}
+			return "", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<span class="sender">Ann Lee</span>`,
+		`datetime="2023-11-14T22:13:20Z"`,
+		"<b>hi</b> &lt;all&gt;<br>\nsee this",
+		`<img src="https://cdn.example/p.jpg" alt="photo">`,
+		`<a href="files/report.pdf">report.pdf</a> (10 bytes)`,
+		"</html>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Join(hooked, ",") != "image,file" {
+		t.Errorf("hook calls = %v, want [image file]", hooked)
+	}
+}
+
+func TestExportChatHTMLResumeBeforeFirstMessage(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "export.json")
+	fail := true
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			writeError(t, w, http.StatusServiceUnavailable, `{"code":"internal","message":"unavailable"}`)
+			return
+		}
+		writeJSON(t, w, MessageList{Messages: exportHistory(3)})
+	})
+
+	var buf bytes.Buffer
+	opts := ExportOpts{Format: ExportHTML, Checkpoint: checkpoint}
+	if n, err := c.ExportChat(context.Background(), 1, &buf, opts); err == nil || n != 0 {
+		t.Fatalf("first run: n = %d, err = %v, want 0 and an error", n, err)
+	}
+	fail = false
+	if n, err := c.ExportChat(context.Background(), 1, &buf, opts); err != nil || n != 3 {
+		t.Fatalf("resume: n = %d, err = %v", n, err)
+	}
+	if got := strings.Count(buf.String(), "<!DOCTYPE html>"); got != 1 {
+		t.Errorf("header written %d times, want 1", got)
+	}
+}
+
+func TestExportChatResume(t *testing.T) {
+	history := exportHistory(250)
+	checkpoint := filepath.Join(t.TempDir(), "export.json")
+
+	// The first run fails on the second page.
+	requests := 0
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 2 {
+			writeError(t, w, http.StatusServiceUnavailable, `{"code":"internal","message":"unavailable"}`)
+			return
+		}
+		from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		var page []Message
+		for _, m := range history {
+			if (from == 0 || m.Timestamp <= from) && len(page) < messagesPageSize {
+				page = append(page, m)
+			}
+		}
+		writeJSON(t, w, MessageList{Messages: page})
+	})
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	n, err := c.ExportChat(context.Background(), 1, bw, ExportOpts{Checkpoint: checkpoint})
+	if err == nil {
+		t.Fatal("expected error from the first run")
+	}
+	if n != messagesPageSize {
+		t.Errorf("first run n = %d, want %d", n, messagesPageSize)
+	}
+
+	n, err = c.ExportChat(context.Background(), 1, bw, ExportOpts{Checkpoint: checkpoint})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if n != 150 {
+		t.Errorf("resume n = %d, want 150", n)
+	}
+
+	seen := make(map[string]bool)
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var msg Message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("bad line: %v", err)
+		}
+		if seen[msg.Body.MID] {
+			t.Fatalf("duplicate message %s", msg.Body.MID)
+		}
+		seen[msg.Body.MID] = true
+	}
+	if len(seen) != 250 {
+		t.Errorf("exported %d messages, want 250", len(seen))
+	}
+
+	n, err = c.ExportChat(context.Background(), 1, bw, ExportOpts{Checkpoint: checkpoint})
+	if err != nil || n != 0 {
+		t.Errorf("completed export: n = %d, err = %v, want 0, nil", n, err)
+	}
+
+	if _, err := c.ExportChat(context.Background(), 2, bw, ExportOpts{Checkpoint: checkpoint}); err == nil {
+		t.Error("expected error for a checkpoint of another chat")
+	}
+}
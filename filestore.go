@@ -0,0 +1,112 @@
+package maxigo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+)
+
+// fileStore is a map of values persisted as a JSON array in one file,
+// rewritten atomically on every change. It backs [FileJobStore],
+// [FileMessageStore] and [FileDeletionStore]. A change that cannot be
+// written is rolled back.
+type fileStore[K comparable, V any] struct {
+	path  string
+	name  string           // for errors, e.g. "job store"
+	key   func(V) K        // key of a value
+	order func(a, b V) int // order of values in the file and in all
+
+	mu    sync.Mutex
+	items map[K]V
+}
+
+// openFileStore loads the values stored at path. A missing file is an
+// empty store; it is created on the first change.
+func openFileStore[K comparable, V any](path, name string, key func(V) K, order func(a, b V) int) (*fileStore[K, V], error) {
+	s := &fileStore[K, V]{path: path, name: name, key: key, order: order, items: make(map[K]V)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	var values []V
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("open %s %s: %w", name, path, err)
+	}
+	for _, v := range values {
+		s.items[key(v)] = v
+	}
+	return s, nil
+}
+
+// get returns the value stored under k.
+func (s *fileStore[K, V]) get(k K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.items[k]
+	return v, ok
+}
+
+// all returns all values in order.
+func (s *fileStore[K, V]) all() []V {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sorted()
+}
+
+// put stores v, replacing the value with the same key.
+func (s *fileStore[K, V]) put(v V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := s.key(v)
+	prev, existed := s.items[k]
+	s.items[k] = v
+	if err := s.flush(); err != nil {
+		if existed {
+			s.items[k] = prev
+		} else {
+			delete(s.items, k)
+		}
+		return err
+	}
+	return nil
+}
+
+// remove deletes the value stored under k. Removing a missing key is not
+// an error.
+func (s *fileStore[K, V]) remove(k K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, existed := s.items[k]
+	if !existed {
+		return nil
+	}
+	delete(s.items, k)
+	if err := s.flush(); err != nil {
+		s.items[k] = prev
+		return err
+	}
+	return nil
+}
+
+func (s *fileStore[K, V]) sorted() []V {
+	return slices.SortedFunc(maps.Values(s.items), s.order)
+}
+
+func (s *fileStore[K, V]) flush() error {
+	data, err := json.Marshal(s.sorted())
+	if err != nil {
+		return fmt.Errorf("save %s: %w", s.name, err)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("save %s: %w", s.name, err)
+	}
+	return nil
+}
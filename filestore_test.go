@@ -0,0 +1,76 @@
+package maxigo
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type storedPair struct {
+	K string `json:"k"`
+	V int    `json:"v"`
+}
+
+func openPairStore(t *testing.T, path string) *fileStore[string, storedPair] {
+	t.Helper()
+	s, err := openFileStore(path, "pair store",
+		func(p storedPair) string { return p.K },
+		func(a, b storedPair) int { return cmp.Compare(a.K, b.K) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairs.json")
+	s := openPairStore(t, path)
+	for _, p := range []storedPair{{"b", 2}, {"a", 1}, {"c", 3}, {"b", 4}} {
+		if err := s.put(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.remove("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.remove("missing"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openPairStore(t, path)
+	want := []storedPair{{"a", 1}, {"b", 4}}
+	if got := reopened.all(); !slices.Equal(got, want) {
+		t.Errorf("all = %v, want %v", got, want)
+	}
+	if p, ok := reopened.get("b"); !ok || p.V != 4 {
+		t.Errorf("get(b) = %v, %v", p, ok)
+	}
+}
+
+func TestFileStoreRollback(t *testing.T) {
+	// The directory does not exist, so every write fails.
+	s := openPairStore(t, filepath.Join(t.TempDir(), "missing", "pairs.json"))
+	err := s.put(storedPair{"a", 1})
+	if err == nil || !strings.Contains(err.Error(), "save pair store") {
+		t.Fatalf("put err = %v, want save error", err)
+	}
+	if _, ok := s.get("a"); ok {
+		t.Error("failed put was kept in memory")
+	}
+}
+
+func TestFileStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairs.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := openFileStore(path, "pair store",
+		func(p storedPair) string { return p.K },
+		func(a, b storedPair) int { return cmp.Compare(a.K, b.K) })
+	if err == nil {
+		t.Error("expected error for corrupt file")
+	}
+}
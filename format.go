@@ -0,0 +1,224 @@
+package maxigo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrTextTooLong is returned when message text exceeds [MaxTextLength].
+var ErrTextTooLong = errors.New("message text is too long")
+
+// markdownEscaper escapes characters that have a meaning in Max markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`_`, `\_`,
+	`~`, `\~`,
+	"`", "\\`",
+	`+`, `\+`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`(`, `\(`,
+	`)`, `\)`,
+)
+
+// markdownCodeEscaper escapes characters inside markdown code spans and blocks,
+// where all other characters are literal.
+var markdownCodeEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+)
+
+// markdownURLEscaper percent-encodes characters that would end a markdown link target.
+var markdownURLEscaper = strings.NewReplacer(
+	` `, `%20`,
+	`(`, `%28`,
+	`)`, `%29`,
+)
+
+// htmlEscaper escapes text and attribute values for Max HTML.
+var htmlEscaper = strings.NewReplacer(
+	`&`, `&amp;`,
+	`<`, `&lt;`,
+	`>`, `&gt;`,
+	`"`, `&quot;`,
+)
+
+// EscapeMarkdown escapes s so that it is displayed literally in a message
+// sent with [FormatMarkdown].
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// EscapeHTML escapes s so that it is displayed literally in a message
+// sent with [FormatHTML].
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// UserMentionURL returns the link target that mentions a user in formatted text.
+func UserMentionURL(userID int64) string {
+	return "max://user/" + strconv.FormatInt(userID, 10)
+}
+
+// TextBuilder composes formatted message text with correct escaping for the
+// chosen [TextFormat]. All text passed to its methods is treated as plain
+// text and escaped, so user-provided names and messages are safe to use.
+//
+// With an empty format, styles are dropped and plain text is produced.
+//
+//	body, err := maxigo.NewTextBuilder(maxigo.FormatHTML).
+//	    Text("Hello, ").
+//	    Mention(user.FirstName, user.UserID).
+//	    Text("! Your order ").
+//	    Bold(orderID).
+//	    Text(" is ready.").
+//	    Build()
+//
+// The zero value is a ready-to-use builder for plain text; use
+// [NewTextBuilder] for markdown or HTML.
+type TextBuilder struct {
+	format TextFormat
+	sb     strings.Builder
+}
+
+// NewTextBuilder creates a builder for the given format.
+func NewTextBuilder(format TextFormat) *TextBuilder {
+	return &TextBuilder{format: format}
+}
+
+// Text appends plain text.
+func (b *TextBuilder) Text(s string) *TextBuilder {
+	b.sb.WriteString(b.escape(s))
+	return b
+}
+
+// Textf appends plain text formatted with [fmt.Sprintf].
+func (b *TextBuilder) Textf(format string, args ...any) *TextBuilder {
+	return b.Text(fmt.Sprintf(format, args...))
+}
+
+// Line appends s followed by a newline.
+func (b *TextBuilder) Line(s string) *TextBuilder {
+	return b.Text(s + "\n")
+}
+
+// Bold appends bold text.
+func (b *TextBuilder) Bold(s string) *TextBuilder {
+	return b.wrap(s, "**", "**", "<b>", "</b>")
+}
+
+// Italic appends italic text.
+func (b *TextBuilder) Italic(s string) *TextBuilder {
+	return b.wrap(s, "_", "_", "<i>", "</i>")
+}
+
+// Underline appends underlined text.
+func (b *TextBuilder) Underline(s string) *TextBuilder {
+	return b.wrap(s, "++", "++", "<u>", "</u>")
+}
+
+// Strikethrough appends struck-through text.
+func (b *TextBuilder) Strikethrough(s string) *TextBuilder {
+	return b.wrap(s, "~~", "~~", "<s>", "</s>")
+}
+
+// Code appends inline monospace text.
+func (b *TextBuilder) Code(s string) *TextBuilder {
+	switch b.format {
+	case FormatMarkdown:
+		b.sb.WriteString("`" + markdownCodeEscaper.Replace(s) + "`")
+	case FormatHTML:
+		b.sb.WriteString("<code>" + EscapeHTML(s) + "</code>")
+	default:
+		b.sb.WriteString(s)
+	}
+	return b
+}
+
+// Pre appends a preformatted block on its own lines.
+func (b *TextBuilder) Pre(s string) *TextBuilder {
+	switch b.format {
+	case FormatMarkdown:
+		b.sb.WriteString("```\n" + markdownCodeEscaper.Replace(s) + "\n```")
+	case FormatHTML:
+		b.sb.WriteString("<pre>" + EscapeHTML(s) + "</pre>")
+	default:
+		b.sb.WriteString(s)
+	}
+	return b
+}
+
+// Link appends a hyperlink with the given text.
+// In plain text mode it is rendered as "text (url)".
+func (b *TextBuilder) Link(text, url string) *TextBuilder {
+	switch b.format {
+	case FormatMarkdown:
+		b.sb.WriteString("[" + EscapeMarkdown(text) + "](" + markdownURLEscaper.Replace(url) + ")")
+	case FormatHTML:
+		b.sb.WriteString(`<a href="` + EscapeHTML(url) + `">` + EscapeHTML(text) + "</a>")
+	default:
+		b.sb.WriteString(text + " (" + url + ")")
+	}
+	return b
+}
+
+// Mention appends a user mention displayed as text.
+// In plain text mode only the text is written.
+func (b *TextBuilder) Mention(text string, userID int64) *TextBuilder {
+	if b.format == FormatMarkdown || b.format == FormatHTML {
+		return b.Link(text, UserMentionURL(userID))
+	}
+	b.sb.WriteString(text)
+	return b
+}
+
+// Len returns the current text length in UTF-16 code units.
+func (b *TextBuilder) Len() int {
+	return textLength(b.sb.String())
+}
+
+// String returns the formatted text built so far.
+func (b *TextBuilder) String() string {
+	return b.sb.String()
+}
+
+// Build returns a message body with the formatted text and format set.
+// Returns an error wrapping [ErrTextTooLong] if the text exceeds [MaxTextLength].
+func (b *TextBuilder) Build() (*NewMessageBody, error) {
+	text := b.sb.String()
+	if n := textLength(text); n > MaxTextLength {
+		return nil, fmt.Errorf("%w: %d characters, limit is %d", ErrTextTooLong, n, MaxTextLength)
+	}
+
+	body := &NewMessageBody{Text: Some(text)}
+	if b.format != "" {
+		body.Format = Some(b.format)
+	}
+	return body, nil
+}
+
+func (b *TextBuilder) escape(s string) string {
+	switch b.format {
+	case FormatMarkdown:
+		return EscapeMarkdown(s)
+	case FormatHTML:
+		return EscapeHTML(s)
+	default:
+		return s
+	}
+}
+
+func (b *TextBuilder) wrap(s, mdOpen, mdClose, htmlOpen, htmlClose string) *TextBuilder {
+	switch b.format {
+	case FormatMarkdown:
+		b.sb.WriteString(mdOpen + EscapeMarkdown(s) + mdClose)
+	case FormatHTML:
+		b.sb.WriteString(htmlOpen + EscapeHTML(s) + htmlClose)
+	default:
+		b.sb.WriteString(s)
+	}
+	return b
+}
@@ -0,0 +1,129 @@
+package maxigo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTextBuilderMarkdown(t *testing.T) {
+	got := NewTextBuilder(FormatMarkdown).
+		Text("Hi *all* ").
+		Bold("a_b").
+		Text(" ").
+		Italic("x").
+		Text(" ").
+		Underline("u").
+		Text(" ").
+		Strikethrough("s").
+		Text(" ").
+		Code("a`b").
+		Text(" ").
+		Link("docs [v2]", "https://example.com/a (b)").
+		Text(" ").
+		Mention("John_Doe", 42).
+		String()
+
+	want := `Hi \*all\* **a\_b** _x_ ++u++ ~~s~~ ` + "`a\\`b`" +
+		` [docs \[v2\]](https://example.com/a%20%28b%29) [John\_Doe](max://user/42)`
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestTextBuilderHTML(t *testing.T) {
+	got := NewTextBuilder(FormatHTML).
+		Text("1 < 2 & 3 > 2 ").
+		Bold("<b>").
+		Text(" ").
+		Italic("i").
+		Text(" ").
+		Underline("u").
+		Text(" ").
+		Strikethrough("s").
+		Text(" ").
+		Code("x<y").
+		Text(" ").
+		Link("go", `https://example.com/?a=1&b="2"`).
+		Text(" ").
+		Mention("Tom & Jerry", 7).
+		String()
+
+	want := `1 &lt; 2 &amp; 3 &gt; 2 <b>&lt;b&gt;</b> <i>i</i> <u>u</u> <s>s</s> <code>x&lt;y</code> ` +
+		`<a href="https://example.com/?a=1&amp;b=&quot;2&quot;">go</a> <a href="max://user/7">Tom &amp; Jerry</a>`
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestTextBuilderPlain(t *testing.T) {
+	got := NewTextBuilder("").
+		Bold("*bold*").
+		Text(" ").
+		Link("site", "https://example.com").
+		Text(" ").
+		Mention("Ann", 1).
+		String()
+
+	want := "*bold* site (https://example.com) Ann"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The zero value builds plain text too.
+	var zero TextBuilder
+	body, err := zero.Bold("*bold*").Text(" ").Mention("Ann", 1).Build()
+	if err != nil || body.Text.Value != "*bold* Ann" || body.Format.Set {
+		t.Errorf("zero value: body = %+v, err = %v", body, err)
+	}
+}
+
+func TestTextBuilderPre(t *testing.T) {
+	tests := []struct {
+		format TextFormat
+		want   string
+	}{
+		{FormatMarkdown, "```\nfmt.Println(\"\\`\")\n```"},
+		{FormatHTML, "<pre>fmt.Println(&quot;`&quot;)</pre>"},
+		{"", "fmt.Println(\"`\")"},
+	}
+	for _, tt := range tests {
+		got := NewTextBuilder(tt.format).Pre("fmt.Println(\"`\")").String()
+		if got != tt.want {
+			t.Errorf("format %q: got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestTextBuilderBuild(t *testing.T) {
+	body, err := NewTextBuilder(FormatHTML).Bold("hi").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body.Text.Value != "<b>hi</b>" {
+		t.Errorf("Text = %q, want %q", body.Text.Value, "<b>hi</b>")
+	}
+	if !body.Format.Set || body.Format.Value != FormatHTML {
+		t.Errorf("Format = %+v, want html", body.Format)
+	}
+
+	plain, err := NewTextBuilder("").Text("hi").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain.Format.Set {
+		t.Errorf("Format should be unset for plain text, got %+v", plain.Format)
+	}
+}
+
+func TestTextBuilderBuildTooLong(t *testing.T) {
+	// Each emoji is 2 UTF-16 code units.
+	b := NewTextBuilder("").Text(strings.Repeat("😀", MaxTextLength/2+1))
+	if b.Len() != MaxTextLength+2 {
+		t.Errorf("Len() = %d, want %d", b.Len(), MaxTextLength+2)
+	}
+	_, err := b.Build()
+	if !errors.Is(err, ErrTextTooLong) {
+		t.Errorf("err = %v, want ErrTextTooLong", err)
+	}
+}
@@ -0,0 +1,261 @@
+package maxigo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"path/filepath"
+	"strings"
+
+	// Register decoders for image.Decode.
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// defaultImageMaxDimension is the default longest side of a preprocessed image.
+	defaultImageMaxDimension = 2560
+	// defaultImageQuality is the default JPEG quality of a preprocessed image.
+	defaultImageQuality = 85
+	// maxImagePixels protects against decompression bombs (100 megapixels).
+	maxImagePixels = 100_000_000
+)
+
+// ImageOpts configures image preprocessing for [PreprocessImage]
+// and [WithImagePreprocessing].
+type ImageOpts struct {
+	// MaxDimension is the maximum width or height in pixels. Larger images are
+	// downscaled proportionally. 0 uses the default (2560).
+	MaxDimension int
+	// Quality is the JPEG quality from 1 to 100. 0 uses the default (85).
+	Quality int
+}
+
+// PreprocessImage decodes a JPEG, PNG or GIF image, downscales it so that
+// neither side exceeds opts.MaxDimension, and re-encodes it as JPEG.
+//
+// Re-encoding strips all metadata (EXIF, ICC profiles, comments). The EXIF
+// orientation of a JPEG is applied to the pixels first, so the photo is not
+// shown rotated or mirrored afterwards. Transparent areas are flattened onto a white background, and only the
+// first frame of an animated GIF is kept.
+func PreprocessImage(r io.Reader, opts ImageOpts) ([]byte, error) {
+	maxDim := opts.MaxDimension
+	if maxDim <= 0 {
+		maxDim = defaultImageMaxDimension
+	}
+	quality := opts.Quality
+	if quality <= 0 {
+		quality = defaultImageQuality
+	}
+	if quality > 100 {
+		quality = 100
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image header: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("decode image header: empty image")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return nil, fmt.Errorf("image too large: %dx%d pixels", cfg.Width, cfg.Height)
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	// Flatten onto white: JPEG has no alpha channel.
+	b := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+	if format == "jpeg" {
+		flat = orient(flat, jpegOrientation(data))
+	}
+
+	var out image.Image = flat
+	fw, fh := flat.Bounds().Dx(), flat.Bounds().Dy()
+	if w, h := fitWithin(fw, fh, maxDim); w != fw || h != fh {
+		out = downscale(flat, w, h)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fitWithin returns dimensions scaled proportionally so that neither side
+// exceeds maxDim. Dimensions that already fit are returned unchanged.
+func fitWithin(w, h, maxDim int) (int, int) {
+	if w <= maxDim && h <= maxDim {
+		return w, h
+	}
+	if w >= h {
+		return maxDim, max(1, h*maxDim/w)
+	}
+	return max(1, w*maxDim/h), maxDim
+}
+
+// downscale resizes src to w×h using box filtering (area averaging).
+// The target must not be larger than the source.
+func downscale(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for dy := range h {
+		sy0 := dy * sh / h
+		sy1 := max((dy+1)*sh/h, sy0+1)
+		for dx := range w {
+			sx0 := dx * sw / w
+			sx1 := max((dx+1)*sw/w, sx0+1)
+
+			var r, g, bl, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := sx0; sx < sx1; sx++ {
+					p := row[sx*4:]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					bl += uint64(p[2])
+					n++
+				}
+			}
+
+			d := dst.Pix[dy*dst.Stride+dx*4:]
+			d[0] = uint8(r / n)
+			d[1] = uint8(g / n)
+			d[2] = uint8(bl / n)
+			d[3] = 0xff
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation (1 to 8) of a JPEG image,
+// or 1 if the image has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF: // fill byte
+			i++
+			continue
+		case marker == 0x01 || marker >= 0xD0 && marker <= 0xD8: // no length
+			i += 2
+			continue
+		case marker == 0xDA || marker == 0xD9: // image data starts, no EXIF
+			return 1
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return 1
+		}
+		if seg := data[i+4 : i+2+n]; marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + n
+	}
+	return 1
+}
+
+// exifOrientation reads the Orientation tag from IFD0 of TIFF-encoded EXIF
+// data, returning 1 if it is missing or invalid.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := range entries {
+		e := int(ifd) + 2 + k*12
+		if e+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[e:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[e+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient transforms src so that an image stored with the given EXIF
+// orientation is upright. Orientations 5 to 8 swap width and height.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch orientation {
+			case 2: // flip horizontally
+				dx, dy = w-1-x, y
+			case 3: // turn 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // flip vertically
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // turn 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // turn 90° counterclockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:][:4], src.Pix[y*src.Stride+x*4:][:4])
+		}
+	}
+	return dst
+}
+
+// jpegFilename replaces the extension of filename with ".jpg".
+func jpegFilename(filename string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	if base == "" {
+		base = "photo"
+	}
+	return base + ".jpg"
+}
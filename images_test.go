@@ -0,0 +1,227 @@
+package maxigo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// testPNG encodes a w×h PNG with a fully transparent left half. Test helper.
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			if x >= w/2 {
+				img.Set(x, y, color.NRGBA{R: 200, A: 0xff})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPreprocessImage(t *testing.T) {
+	out, err := PreprocessImage(bytes.NewReader(testPNG(t, 400, 100)), ImageOpts{MaxDimension: 200})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if format != "jpeg" {
+		t.Errorf("format = %q, want jpeg", format)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 50 {
+		t.Errorf("size = %dx%d, want 200x50", b.Dx(), b.Dy())
+	}
+
+	// Transparent pixels are flattened onto white.
+	r, g, b, _ := img.At(10, 25).RGBA()
+	if r>>8 < 0xf0 || g>>8 < 0xf0 || b>>8 < 0xf0 {
+		t.Errorf("transparent pixel = (%d,%d,%d), want near white", r>>8, g>>8, b>>8)
+	}
+}
+
+// testJPEGWithOrientation encodes a w×h JPEG with a red left half and a
+// blue right half, and an EXIF segment with the given orientation. Test
+// helper.
+func testJPEGWithOrientation(t *testing.T, w, h int, orientation uint16) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			if x < w/2 {
+				img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+			} else {
+				img.Set(x, y, color.RGBA{B: 0xff, A: 0xff})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Big-endian TIFF header and IFD0 with the Orientation tag (SHORT).
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1,
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, byte(orientation >> 8), byte(orientation), 0, 0,
+		0, 0, 0, 0}
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := append([]byte{0xFF, 0xE1, byte((len(seg) + 2) >> 8), byte(len(seg) + 2)}, seg...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func TestPreprocessImageOrientation(t *testing.T) {
+	out, err := PreprocessImage(bytes.NewReader(testJPEGWithOrientation(t, 64, 32, 6)), ImageOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 32 || b.Dy() != 64 {
+		t.Fatalf("size = %dx%d, want 32x64", b.Dx(), b.Dy())
+	}
+	// Turned clockwise, the red left half ends up on top.
+	if r, _, b, _ := img.At(16, 8).RGBA(); r>>8 < 0xc0 || b>>8 > 0x40 {
+		t.Errorf("top pixel = (%d,_,%d), want red", r>>8, b>>8)
+	}
+	if r, _, b, _ := img.At(16, 56).RGBA(); b>>8 < 0xc0 || r>>8 > 0x40 {
+		t.Errorf("bottom pixel = (%d,_,%d), want blue", r>>8, b>>8)
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for o := uint16(1); o <= 8; o++ {
+		if got := jpegOrientation(testJPEGWithOrientation(t, 8, 8, o)); got != int(o) {
+			t.Errorf("orientation %d: got %d", o, got)
+		}
+	}
+	if got := jpegOrientation(testPNG(t, 8, 8)); got != 1 {
+		t.Errorf("PNG: got %d, want 1", got)
+	}
+	if got := jpegOrientation([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF}); got != 1 {
+		t.Errorf("truncated: got %d, want 1", got)
+	}
+}
+
+func TestPreprocessImageKeepsSmallImageSize(t *testing.T) {
+	out, err := PreprocessImage(bytes.NewReader(testPNG(t, 30, 40)), ImageOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if cfg.Width != 30 || cfg.Height != 40 {
+		t.Errorf("size = %dx%d, want 30x40", cfg.Width, cfg.Height)
+	}
+}
+
+func TestPreprocessImageInvalid(t *testing.T) {
+	_, err := PreprocessImage(strings.NewReader("not an image"), ImageOpts{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestFitWithin(t *testing.T) {
+	tests := []struct {
+		w, h, maxDim int
+		wantW, wantH int
+	}{
+		{100, 50, 200, 100, 50},
+		{400, 100, 200, 200, 50},
+		{100, 400, 200, 50, 200},
+		{5000, 1, 100, 100, 1},
+	}
+	for _, tt := range tests {
+		w, h := fitWithin(tt.w, tt.h, tt.maxDim)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitWithin(%d, %d, %d) = %d, %d, want %d, %d",
+				tt.w, tt.h, tt.maxDim, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestJPEGFilename(t *testing.T) {
+	tests := map[string]string{
+		"photo.png":      "photo.jpg",
+		"archive.tar.gz": "archive.tar.jpg",
+		"noext":          "noext.jpg",
+		".png":           "photo.jpg",
+	}
+	for in, want := range tests {
+		if got := jpegFilename(in); got != want {
+			t.Errorf("jpegFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestUploadPhotoWithImagePreprocessing(t *testing.T) {
+	var requestCount atomic.Int32
+	var uploadedFilename string
+	var uploaded []byte
+	c, _ := testClientWithOpts(t, func(w http.ResponseWriter, r *http.Request) {
+		if requestCount.Add(1) == 1 {
+			writeJSON(t, w, UploadEndpoint{URL: "http://" + r.Host + "/do-upload"})
+			return
+		}
+		f, fh, err := r.FormFile("data")
+		if err != nil {
+			t.Fatalf("form file: %v", err)
+		}
+		uploadedFilename = fh.Filename
+		uploaded, _ = io.ReadAll(f)
+		writeJSON(t, w, PhotoTokens{Photos: map[string]PhotoToken{"default": {Token: "tok"}}})
+	}, WithImagePreprocessing(ImageOpts{MaxDimension: 64}))
+
+	_, err := c.UploadPhoto(context.Background(), "big.png", bytes.NewReader(testPNG(t, 256, 128)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if uploadedFilename != "big.jpg" {
+		t.Errorf("filename = %q, want big.jpg", uploadedFilename)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(uploaded))
+	if err != nil {
+		t.Fatalf("uploaded data is not JPEG: %v", err)
+	}
+	if cfg.Width != 64 || cfg.Height != 32 {
+		t.Errorf("size = %dx%d, want 64x32", cfg.Width, cfg.Height)
+	}
+}
+
+func TestUploadPhotoWithImagePreprocessingInvalid(t *testing.T) {
+	c, _ := testClientWithOpts(t, func(w http.ResponseWriter, _ *http.Request) {
+		t.Fatal("no HTTP request should be made")
+	}, WithImagePreprocessing(ImageOpts{}))
+
+	_, err := c.UploadPhoto(context.Background(), "bad.png", strings.NewReader("garbage"))
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if e.Kind != ErrDecode {
+		t.Errorf("Kind = %v, want ErrDecode", e.Kind)
+	}
+}
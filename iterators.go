@@ -0,0 +1,127 @@
+package maxigo
+
+import (
+	"context"
+	"iter"
+)
+
+// messagesPageSize is the number of messages requested per page by
+// [Client.MessagesBetween] (the API maximum).
+const messagesPageSize = 100
+
+// AllChats returns an iterator over all chats the bot participates in,
+// following [ChatList.Marker] from opts.Marker until the last page.
+// opts.Count sets the page size.
+//
+//	for chat, err := range client.AllChats(ctx, maxigo.GetChatsOpts{}) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(chat.Title)
+//	}
+//
+// On error, the iterator yields the error once and stops.
+func (c *Client) AllChats(ctx context.Context, opts GetChatsOpts) iter.Seq2[Chat, error] {
+	return func(yield func(Chat, error) bool) {
+		for {
+			list, err := c.GetChats(ctx, opts)
+			if err != nil {
+				yield(Chat{}, err)
+				return
+			}
+			for _, chat := range list.Chats {
+				if !yield(chat, nil) {
+					return
+				}
+			}
+			if !nextMarker(&opts.Marker, list.Marker) {
+				return
+			}
+		}
+	}
+}
+
+// AllMembers returns an iterator over all members of a chat, following
+// [ChatMembersList.Marker] from opts.Marker until the last page.
+// opts.Count sets the page size. See [Client.AllChats].
+func (c *Client) AllMembers(ctx context.Context, chatID int64, opts GetMembersOpts) iter.Seq2[ChatMember, error] {
+	return func(yield func(ChatMember, error) bool) {
+		for {
+			list, err := c.GetMembers(ctx, chatID, opts)
+			if err != nil {
+				yield(ChatMember{}, err)
+				return
+			}
+			for _, member := range list.Members {
+				if !yield(member, nil) {
+					return
+				}
+			}
+			if !nextMarker(&opts.Marker, list.Marker) {
+				return
+			}
+		}
+	}
+}
+
+// MessagesBetween returns an iterator over the messages of a chat from
+// newest to oldest. As in [GetMessagesOpts], from is the newer and to the
+// older bound (Unix milliseconds, inclusive); 0 means no bound.
+//
+// Pages are requested backwards by [Message.Timestamp]. Messages on a page
+// boundary are returned once.
+func (c *Client) MessagesBetween(ctx context.Context, chatID int64, from, to int64) iter.Seq2[Message, error] {
+	return func(yield func(Message, error) bool) {
+		opts := GetMessagesOpts{ChatID: chatID, Count: messagesPageSize, From: from, To: to}
+		seen := make(map[string]bool) // messages at the current boundary timestamp
+
+		for {
+			list, err := c.GetMessages(ctx, opts)
+			if err != nil {
+				yield(Message{}, err)
+				return
+			}
+
+			boundary := opts.From
+			fresh := 0
+			for _, msg := range list.Messages {
+				if seen[msg.Body.MID] {
+					continue
+				}
+				fresh++
+				if msg.Timestamp != boundary {
+					boundary = msg.Timestamp
+					clear(seen)
+				}
+				seen[msg.Body.MID] = true
+				if !yield(msg, nil) {
+					return
+				}
+			}
+
+			if len(list.Messages) < messagesPageSize {
+				return
+			}
+			if fresh == 0 {
+				// A full page of already seen messages with one timestamp:
+				// skip past it to make progress.
+				boundary--
+				clear(seen)
+			}
+			if boundary <= 0 || (to != 0 && boundary < to) {
+				return
+			}
+			opts.From = boundary
+		}
+	}
+}
+
+// nextMarker stores the next page marker and reports whether there is a next
+// page. It also stops when the server returns the same marker again.
+func nextMarker(current *int64, next *int64) bool {
+	if next == nil || *next == 0 || *next == *current {
+		return false
+	}
+	*current = *next
+	return true
+}
@@ -0,0 +1,178 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestAllChats(t *testing.T) {
+	pages := map[string]ChatList{
+		"":   {Chats: []Chat{{ChatID: 1}, {ChatID: 2}}, Marker: int64Ptr(10)},
+		"10": {Chats: []Chat{{ChatID: 3}}, Marker: int64Ptr(20)},
+		"20": {Chats: []Chat{{ChatID: 4}}},
+	}
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("count") != "2" {
+			t.Errorf("count = %q, want 2", r.URL.Query().Get("count"))
+		}
+		writeJSON(t, w, pages[r.URL.Query().Get("marker")])
+	})
+
+	var ids []int64
+	for chat, err := range c.AllChats(context.Background(), GetChatsOpts{Count: 2}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, chat.ChatID)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4]" {
+		t.Errorf("ids = %v, want [1 2 3 4]", ids)
+	}
+
+	t.Run("break", func(t *testing.T) {
+		requests := 0
+		c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			writeJSON(t, w, pages[r.URL.Query().Get("marker")])
+		})
+		for range c.AllChats(context.Background(), GetChatsOpts{}) {
+			break
+		}
+		if requests != 1 {
+			t.Errorf("requests = %d, want 1", requests)
+		}
+	})
+}
+
+func TestAllMembers(t *testing.T) {
+	t.Run("pages", func(t *testing.T) {
+		c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/chats/5/members" {
+				t.Errorf("path = %q", r.URL.Path)
+			}
+			if r.URL.Query().Get("marker") == "" {
+				writeJSON(t, w, ChatMembersList{Members: []ChatMember{{UserWithPhoto: UserWithPhoto{User: User{UserID: 1}}}}, Marker: int64Ptr(7)})
+				return
+			}
+			writeJSON(t, w, ChatMembersList{Members: []ChatMember{{UserWithPhoto: UserWithPhoto{User: User{UserID: 2}}}}})
+		})
+
+		var ids []int64
+		for m, err := range c.AllMembers(context.Background(), 5, GetMembersOpts{}) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids = append(ids, m.UserID)
+		}
+		if fmt.Sprint(ids) != "[1 2]" {
+			t.Errorf("ids = %v, want [1 2]", ids)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			writeError(t, w, http.StatusForbidden, `{"code":"access.denied","message":"Forbidden"}`)
+		})
+
+		n := 0
+		for _, err := range c.AllMembers(context.Background(), 5, GetMembersOpts{}) {
+			n++
+			var e *Error
+			if !errors.As(err, &e) || e.StatusCode != http.StatusForbidden {
+				t.Errorf("err = %v, want 403 API error", err)
+			}
+		}
+		if n != 1 {
+			t.Errorf("yielded %d times, want 1", n)
+		}
+	})
+}
+
+// historyServer serves messages newest first, honoring from, to and count
+// like GET /messages.
+func historyServer(t *testing.T, history []Message) (*Client, *int) {
+	t.Helper()
+	requests := 0
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		from, _ := strconv.ParseInt(q.Get("from"), 10, 64)
+		to, _ := strconv.ParseInt(q.Get("to"), 10, 64)
+		count, _ := strconv.Atoi(q.Get("count"))
+
+		var page []Message
+		for _, m := range history {
+			if (from == 0 || m.Timestamp <= from) && m.Timestamp >= to && len(page) < count {
+				page = append(page, m)
+			}
+		}
+		writeJSON(t, w, MessageList{Messages: page})
+	})
+	return c, &requests
+}
+
+func TestMessagesBetween(t *testing.T) {
+	// 250 messages, three per timestamp, so page boundaries split timestamps.
+	var history []Message
+	for i := range 250 {
+		history = append(history, Message{Timestamp: int64(1000 - i/3), Body: MessageBody{MID: fmt.Sprintf("m%d", i)}})
+	}
+
+	tests := []struct {
+		name     string
+		from, to int64
+		want     int
+	}{
+		{"all", 0, 0, 250},
+		{"bounded", 990, 950, 123},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := historyServer(t, history)
+
+			seen := make(map[string]bool)
+			last := int64(1 << 62)
+			for msg, err := range c.MessagesBetween(context.Background(), 1, tt.from, tt.to) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if seen[msg.Body.MID] {
+					t.Fatalf("duplicate message %s", msg.Body.MID)
+				}
+				if msg.Timestamp > last {
+					t.Fatalf("message %s is out of order", msg.Body.MID)
+				}
+				seen[msg.Body.MID] = true
+				last = msg.Timestamp
+			}
+			if len(seen) != tt.want {
+				t.Errorf("got %d messages, want %d", len(seen), tt.want)
+			}
+		})
+	}
+
+	t.Run("same timestamp", func(t *testing.T) {
+		var same []Message
+		for i := range messagesPageSize + 1 {
+			same = append(same, Message{Timestamp: 500, Body: MessageBody{MID: fmt.Sprintf("s%d", i)}})
+		}
+		same = append(same, Message{Timestamp: 400, Body: MessageBody{MID: "older"}})
+		c, requests := historyServer(t, same)
+
+		var got []string
+		for msg, err := range c.MessagesBetween(context.Background(), 1, 0, 0) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got = append(got, msg.Body.MID)
+		}
+		if got[len(got)-1] != "older" || *requests > 3 {
+			t.Errorf("got %d messages ending with %q in %d requests", len(got), got[len(got)-1], *requests)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package maxigo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidKeyboard is wrapped by errors returned from [KeyboardBuilder.Build]
+// when the keyboard violates Max Bot API limits.
+var ErrInvalidKeyboard = errors.New("invalid inline keyboard")
+
+// KeyboardBuilder builds inline keyboards row by row.
+//
+//	kb, err := maxigo.NewKeyboardBuilder().
+//	    Row(maxigo.NewCallbackButton("Yes", "yes"), maxigo.NewCallbackButton("No", "no")).
+//	    Columns(3).
+//	    Add(itemButtons...).
+//	    AddIf(isAdmin, maxigo.NewCallbackButton("Delete", "delete")).
+//	    Build()
+//
+// The zero value is not usable; create builders with [NewKeyboardBuilder].
+type KeyboardBuilder struct {
+	rows    [][]Button
+	current []Button
+	columns int
+}
+
+// NewKeyboardBuilder creates an empty keyboard builder.
+func NewKeyboardBuilder() *KeyboardBuilder {
+	return &KeyboardBuilder{}
+}
+
+// Row finishes the current row and appends a new row with the given buttons.
+// Empty rows are ignored.
+func (kb *KeyboardBuilder) Row(buttons ...Button) *KeyboardBuilder {
+	kb.NewRow()
+	if len(buttons) > 0 {
+		kb.rows = append(kb.rows, append([]Button(nil), buttons...))
+	}
+	return kb
+}
+
+// RowIf is like [KeyboardBuilder.Row] but does nothing when cond is false.
+func (kb *KeyboardBuilder) RowIf(cond bool, buttons ...Button) *KeyboardBuilder {
+	if !cond {
+		return kb
+	}
+	return kb.Row(buttons...)
+}
+
+// Columns sets the number of buttons per row for subsequent [KeyboardBuilder.Add]
+// calls. When the current row is full, a new row is started automatically.
+// 0 disables wrapping.
+func (kb *KeyboardBuilder) Columns(n int) *KeyboardBuilder {
+	kb.columns = max(n, 0)
+	return kb
+}
+
+// Add appends buttons to the current row, wrapping according to
+// [KeyboardBuilder.Columns].
+func (kb *KeyboardBuilder) Add(buttons ...Button) *KeyboardBuilder {
+	for _, b := range buttons {
+		if kb.columns > 0 && len(kb.current) >= kb.columns {
+			kb.NewRow()
+		}
+		kb.current = append(kb.current, b)
+	}
+	return kb
+}
+
+// AddIf is like [KeyboardBuilder.Add] but does nothing when cond is false.
+func (kb *KeyboardBuilder) AddIf(cond bool, buttons ...Button) *KeyboardBuilder {
+	if !cond {
+		return kb
+	}
+	return kb.Add(buttons...)
+}
+
+// NewRow finishes the current row. Subsequent [KeyboardBuilder.Add] calls start
+// a new row. Calling NewRow on an empty row does nothing.
+func (kb *KeyboardBuilder) NewRow() *KeyboardBuilder {
+	if len(kb.current) > 0 {
+		kb.rows = append(kb.rows, kb.current)
+		kb.current = nil
+	}
+	return kb
+}
+
+// Buttons returns the keyboard layout built so far without validation.
+func (kb *KeyboardBuilder) Buttons() [][]Button {
+	rows := make([][]Button, 0, len(kb.rows)+1)
+	for _, row := range kb.rows {
+		rows = append(rows, append([]Button(nil), row...))
+	}
+	if len(kb.current) > 0 {
+		rows = append(rows, append([]Button(nil), kb.current...))
+	}
+	return rows
+}
+
+// Build validates the keyboard and returns it as an attachment created with
+// [NewInlineKeyboardAttachment]. The error wraps [ErrInvalidKeyboard] and
+// describes the first violated limit.
+func (kb *KeyboardBuilder) Build() (AttachmentRequest, error) {
+	rows := kb.Buttons()
+	if err := validateKeyboard(rows); err != nil {
+		return AttachmentRequest{}, err
+	}
+	return NewInlineKeyboardAttachment(rows), nil
+}
+
+// validateKeyboard checks an inline keyboard layout against known Max limits.
+func validateKeyboard(rows [][]Button) error {
+	if err := validateButtons(rows); err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidKeyboard, err.Field, err.Message)
+	}
+	return nil
+}
+
+// isWideButton reports whether a button type is subject to [MaxWideButtonsPerRow].
+func isWideButton(buttonType string) bool {
+	switch buttonType {
+	case "link", "open_app", "request_contact", "request_geo_location":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package maxigo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeyboardBuilderLayout(t *testing.T) {
+	kb := NewKeyboardBuilder().
+		Row(NewCallbackButton("Yes", "yes"), NewCallbackButton("No", "no")).
+		Columns(2).
+		Add(
+			NewCallbackButton("1", "1"),
+			NewCallbackButton("2", "2"),
+			NewCallbackButton("3", "3"),
+		).
+		AddIf(false, NewCallbackButton("hidden", "hidden")).
+		AddIf(true, NewCallbackButton("4", "4")).
+		Row().
+		RowIf(false, NewCallbackButton("skipped", "skipped")).
+		Row(NewLinkButton("Site", "https://example.com"))
+
+	got := kb.Buttons()
+	want := [][]string{{"Yes", "No"}, {"1", "2"}, {"3", "4"}, {"Site"}}
+	if len(got) != len(want) {
+		t.Fatalf("rows = %d, want %d (%+v)", len(got), len(want), got)
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("row %d has %d buttons, want %d", i, len(got[i]), len(want[i]))
+		}
+		for j := range want[i] {
+			if got[i][j].Text != want[i][j] {
+				t.Errorf("button [%d][%d] = %q, want %q", i, j, got[i][j].Text, want[i][j])
+			}
+		}
+	}
+
+	att, err := kb.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if att.Type != "inline_keyboard" {
+		t.Errorf("Type = %q, want inline_keyboard", att.Type)
+	}
+	if kbd, ok := att.Payload.(Keyboard); !ok || len(kbd.Buttons) != 4 {
+		t.Errorf("Payload = %+v, want keyboard with 4 rows", att.Payload)
+	}
+}
+
+func TestKeyboardBuilderNewRow(t *testing.T) {
+	got := NewKeyboardBuilder().
+		Add(NewCallbackButton("a", "a")).
+		NewRow().
+		NewRow().
+		Add(NewCallbackButton("b", "b")).
+		Buttons()
+	if len(got) != 2 {
+		t.Errorf("rows = %d, want 2", len(got))
+	}
+}
+
+func TestKeyboardBuilderValidation(t *testing.T) {
+	many := func(n int) []Button {
+		buttons := make([]Button, n)
+		for i := range buttons {
+			buttons[i] = NewCallbackButton("b", "p")
+		}
+		return buttons
+	}
+
+	tests := []struct {
+		name    string
+		kb      *KeyboardBuilder
+		wantMsg string
+	}{
+		{"empty", NewKeyboardBuilder(), "no buttons"},
+		{"too many in row", NewKeyboardBuilder().Row(many(MaxButtonsPerRow + 1)...), "buttons[0]: has 8 buttons"},
+		{
+			"too many wide buttons",
+			NewKeyboardBuilder().Row(
+				NewLinkButton("a", "https://a"), NewLinkButton("b", "https://b"),
+				NewLinkButton("c", "https://c"), NewLinkButton("d", "https://d"),
+			),
+			"limit is 3",
+		},
+		{"too many rows", NewKeyboardBuilder().Columns(1).Add(many(MaxKeyboardRows + 1)...), "31 rows"},
+		{"empty text", NewKeyboardBuilder().Row(NewCallbackButton("", "p")), "buttons[0][0].text: must not be empty"},
+		{"long text", NewKeyboardBuilder().Row(NewCallbackButton(strings.Repeat("x", MaxButtonTextLength+1), "p")), "text: is 129"},
+		{"empty payload", NewKeyboardBuilder().Row(NewCallbackButton("a", "")), "payload: must not be empty"},
+		{
+			"long payload",
+			NewKeyboardBuilder().Row(NewCallbackButton("a", strings.Repeat("p", MaxCallbackPayloadLength+1))),
+			"payload: is 1025",
+		},
+		{"empty URL", NewKeyboardBuilder().Row(NewLinkButton("a", "")), "url: must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.kb.Build()
+			if !errors.Is(err, ErrInvalidKeyboard) {
+				t.Fatalf("err = %v, want ErrInvalidKeyboard", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestKeyboardBuilderAtLimits(t *testing.T) {
+	kb := NewKeyboardBuilder().Columns(MaxButtonsPerRow)
+	for range MaxKeyboardRows * MaxButtonsPerRow {
+		kb.Add(NewCallbackButton("b", "p"))
+	}
+	if _, err := kb.Build(); err != nil {
+		t.Errorf("unexpected error at the limits: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package maxigo
+
+import "unicode/utf16"
+
+// Known Max Bot API limits. Requests exceeding them are rejected by the server.
+const (
+	// MaxTextLength is the maximum message text length in UTF-16 code units.
+	MaxTextLength = 4000
+	// MaxAttachmentsPerMessage is the maximum number of attachments
+	// [Client.SendAlbum] accepts for a single message.
+	MaxAttachmentsPerMessage = 10
+	// MaxMembersPerRequest is the number of users [Client.AddMembersBulk]
+	// sends in a single AddMembers request.
+	MaxMembersPerRequest = 100
+	// MaxPhonesPerRequest is the number of phone numbers
+	// [Client.CheckPhoneNumbersBatch] and [Client.SendMessageToPhonesBatch]
+	// send in a single request.
+	MaxPhonesPerRequest = 100
+
+	// MaxKeyboardButtons is the maximum number of buttons in an inline keyboard.
+	MaxKeyboardButtons = 210
+	// MaxKeyboardRows is the maximum number of rows in an inline keyboard.
+	MaxKeyboardRows = 30
+	// MaxButtonsPerRow is the maximum number of buttons in a keyboard row.
+	MaxButtonsPerRow = 7
+	// MaxWideButtonsPerRow is the maximum number of buttons in a row that
+	// contains link, open_app, request_contact or request_geo_location buttons.
+	MaxWideButtonsPerRow = 3
+	// MaxButtonTextLength is the maximum button label length.
+	MaxButtonTextLength = 128
+	// MaxCallbackPayloadLength is the maximum callback button payload length.
+	MaxCallbackPayloadLength = 1024
+)
+
+// textLength returns the length of s in UTF-16 code units, the unit the
+// Max API uses for text limits and markup offsets.
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,180 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LiveMessageOpts holds optional parameters for [Client.NewLiveMessage].
+type LiveMessageOpts struct {
+	// Interval is the minimum time between edits. 0 means 1 second.
+	Interval time.Duration
+	// Format is the text format used for the message and all updates.
+	Format TextFormat
+}
+
+// LiveMessage is a message that is edited frequently, such as a progress
+// indicator or a streamed response. Updates are coalesced so that at most
+// one [Client.EditMessage] call is made per interval, always with the latest
+// text; unchanged text is not sent. If the message is deleted, the next edit
+// sends a new message instead.
+//
+// A LiveMessage is safe for concurrent use. Call [LiveMessage.Close] to send
+// the final text.
+type LiveMessage struct {
+	client   *Client
+	ctx      context.Context
+	chatID   int64
+	format   TextFormat
+	interval time.Duration
+
+	flushMu sync.Mutex // serializes edits
+
+	mu       sync.Mutex
+	msg      *Message
+	pending  string
+	sent     string
+	lastEdit time.Time
+	timer    *time.Timer
+	closed   bool
+	err      error
+}
+
+// NewLiveMessage sends a message with the initial text to a chat and returns
+// a [LiveMessage] for updating it. ctx is used for all subsequent edits.
+func (c *Client) NewLiveMessage(ctx context.Context, chatID int64, text string, opts LiveMessageOpts) (*LiveMessage, error) {
+	l := &LiveMessage{
+		client:   c,
+		ctx:      ctx,
+		chatID:   chatID,
+		format:   opts.Format,
+		interval: opts.Interval,
+		pending:  text,
+		sent:     text,
+		lastEdit: time.Now(),
+	}
+	if l.interval <= 0 {
+		l.interval = time.Second
+	}
+
+	msg, err := c.SendMessage(ctx, chatID, l.body(text))
+	if err != nil {
+		return nil, err
+	}
+	l.msg = msg
+	return l, nil
+}
+
+// Update sets the message text. It does not block: the text is sent by a
+// background edit once the interval since the previous edit has passed.
+// Updates after [LiveMessage.Close] are ignored.
+func (l *LiveMessage) Update(text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return
+	}
+	l.pending = text
+	if l.timer != nil || text == l.sent {
+		return
+	}
+	delay := max(time.Until(l.lastEdit.Add(l.interval)), 0)
+	l.timer = time.AfterFunc(delay, l.tick)
+}
+
+// Message returns the current message. It changes if the original message
+// was deleted and a new one was sent.
+func (l *LiveMessage) Message() *Message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.msg
+}
+
+// Err returns the error of the last background edit, or nil if it succeeded.
+func (l *LiveMessage) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Close stops background edits and sends the latest text if it has not been
+// sent yet, without waiting for the interval. It returns the error of that
+// final edit. Close is idempotent.
+func (l *LiveMessage) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.mu.Unlock()
+
+	l.flushMu.Lock()
+	defer l.flushMu.Unlock()
+	return l.flush()
+}
+
+func (l *LiveMessage) tick() {
+	l.flushMu.Lock()
+	defer l.flushMu.Unlock()
+
+	l.mu.Lock()
+	l.timer = nil
+	closed := l.closed
+	l.mu.Unlock()
+	if closed {
+		return // Close flushes
+	}
+
+	err := l.flush()
+	l.mu.Lock()
+	l.err = err
+	l.mu.Unlock()
+}
+
+// flush sends the pending text. The caller must hold flushMu.
+func (l *LiveMessage) flush() error {
+	l.mu.Lock()
+	text := l.pending
+	if text == l.sent {
+		l.mu.Unlock()
+		return nil
+	}
+	// Set before the request so that updates made meanwhile are scheduled
+	// one interval after this edit.
+	l.lastEdit = time.Now()
+	mid := l.msg.Body.MID
+	l.mu.Unlock()
+
+	body := l.body(text)
+	_, err := l.client.EditMessage(l.ctx, mid, body)
+
+	var msg *Message
+	var e *Error
+	if errors.As(err, &e) && e.Kind == ErrAPI && e.StatusCode == http.StatusNotFound {
+		msg, err = l.client.SendMessage(l.ctx, l.chatID, body)
+	}
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.sent = text
+	if msg != nil {
+		l.msg = msg
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *LiveMessage) body(text string) *NewMessageBody {
+	body := &NewMessageBody{Text: Some(text)}
+	if l.format != "" {
+		body.Format = Some(l.format)
+	}
+	return body
+}
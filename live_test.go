@@ -0,0 +1,118 @@
+package maxigo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// liveServer records message texts sent with POST (send) and PUT (edit).
+type liveServer struct {
+	mu      sync.Mutex
+	sends   []string
+	edits   []string
+	deleted bool
+}
+
+func (s *liveServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body NewMessageBody
+		readJSON(t, r, &body)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch r.Method {
+		case http.MethodPost:
+			s.sends = append(s.sends, body.Text.Value)
+			writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: fmt.Sprintf("mid.%d", len(s.sends))}}})
+		case http.MethodPut:
+			if s.deleted && r.URL.Query().Get("message_id") == "mid.1" {
+				writeError(t, w, http.StatusNotFound, `{"code":"not.found","message":"Message not found"}`)
+				return
+			}
+			s.edits = append(s.edits, body.Text.Value)
+			writeJSON(t, w, SimpleQueryResult{Success: true})
+		}
+	}
+}
+
+func (s *liveServer) snapshot() (sends, edits []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sends...), append([]string(nil), s.edits...)
+}
+
+func TestLiveMessageCoalesces(t *testing.T) {
+	srv := &liveServer{}
+	c, _ := testClient(t, srv.handler(t))
+
+	l, err := c.NewLiveMessage(context.Background(), 1, "0%", LiveMessageOpts{Interval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewLiveMessage: %v", err)
+	}
+	for i := 1; i <= 100; i++ {
+		l.Update(fmt.Sprintf("%d%%", i))
+	}
+	time.Sleep(120 * time.Millisecond)
+	l.Update("100%") // unchanged
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	sends, edits := srv.snapshot()
+	if len(sends) != 1 || sends[0] != "0%" {
+		t.Errorf("sends = %v, want [0%%]", sends)
+	}
+	if len(edits) != 1 || edits[0] != "100%" {
+		t.Errorf("edits = %v, want [100%%]", edits)
+	}
+}
+
+func TestLiveMessageCloseFlushes(t *testing.T) {
+	srv := &liveServer{}
+	c, _ := testClient(t, srv.handler(t))
+
+	l, err := c.NewLiveMessage(context.Background(), 1, "start", LiveMessageOpts{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewLiveMessage: %v", err)
+	}
+	l.Update("middle")
+	l.Update("done")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	l.Update("ignored")
+	if err := l.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	_, edits := srv.snapshot()
+	if len(edits) != 1 || edits[0] != "done" {
+		t.Errorf("edits = %v, want [done]", edits)
+	}
+}
+
+func TestLiveMessageDeleted(t *testing.T) {
+	srv := &liveServer{deleted: true}
+	c, _ := testClient(t, srv.handler(t))
+
+	l, err := c.NewLiveMessage(context.Background(), 1, "start", LiveMessageOpts{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewLiveMessage: %v", err)
+	}
+	l.Update("after delete")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	sends, _ := srv.snapshot()
+	if len(sends) != 2 || sends[1] != "after delete" {
+		t.Errorf("sends = %v, want a new message with the final text", sends)
+	}
+	if l.Message().Body.MID != "mid.2" {
+		t.Errorf("MID = %q, want mid.2", l.Message().Body.MID)
+	}
+}
@@ -0,0 +1,280 @@
+package maxigo
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// Markup element types used in [MarkupElement.Type].
+const (
+	MarkupStrong        = "strong"
+	MarkupEmphasized    = "emphasized"
+	MarkupMonospaced    = "monospaced"
+	MarkupLink          = "link"
+	MarkupStrikethrough = "strikethrough"
+	MarkupUnderline     = "underline"
+	MarkupUserMention   = "user_mention"
+)
+
+// Render returns the message text with its markup applied in the given format.
+// See [RenderMarkup].
+func (mb *MessageBody) Render(format TextFormat) string {
+	if mb.Text == nil {
+		return ""
+	}
+	return RenderMarkup(*mb.Text, mb.Markup, format)
+}
+
+// RenderMarkup applies markup elements to text and returns it as
+// [FormatHTML], [FormatMarkdown], or plain text (empty format).
+//
+// Element offsets are in UTF-16 code units, as sent by the Max API.
+// Overlapping elements are split so that the output is always properly
+// nested; offsets that fall inside a surrogate pair or outside the text are
+// adjusted. Unknown element types are ignored. Text is escaped for the target
+// format, so the result can be sent back with the same format.
+//
+// In plain text, links are written as "text (url)" unless the text is the URL.
+func RenderMarkup(text string, markup []MarkupElement, format TextFormat) string {
+	units := utf16.Encode([]rune(text))
+	n := len(units)
+
+	type span struct {
+		el         MarkupElement
+		start, end int
+		order      int
+	}
+
+	spans := make([]span, 0, len(markup))
+	for i, el := range markup {
+		if !knownMarkup(el.Type) {
+			continue
+		}
+		start := snapUTF16(units, max(el.From, 0))
+		end := snapUTF16(units, min(el.From+el.Length, n))
+		if start >= end {
+			continue
+		}
+		spans = append(spans, span{el: el, start: start, end: end, order: i})
+	}
+
+	if len(spans) == 0 {
+		return escapeFor(format, text, false)
+	}
+
+	// Outer (longer) elements first so that nesting is preserved when possible.
+	slices.SortStableFunc(spans, func(a, b span) int {
+		if c := cmp.Compare(a.start, b.start); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.end, a.end)
+	})
+
+	bounds := []int{0, n}
+	for _, s := range spans {
+		bounds = append(bounds, s.start, s.end)
+	}
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+
+	var sb strings.Builder
+	var stack []span
+	next := 0
+	for bi, pos := range bounds {
+		// Close elements ending here, together with everything opened after them.
+		lowest := len(stack)
+		for i, s := range stack {
+			if s.end == pos {
+				lowest = i
+				break
+			}
+		}
+		var reopen []span
+		for i := len(stack) - 1; i >= lowest; i-- {
+			s := stack[i]
+			if s.end != pos {
+				reopen = append(reopen, s)
+				if format != FormatHTML && format != FormatMarkdown {
+					continue // plain text has nothing to reopen
+				}
+			}
+			sb.WriteString(closeTag(format, s.el, textBetween(units, s.start, s.end)))
+		}
+		stack = stack[:lowest]
+
+		// Reopen interrupted elements and open new ones, longest first.
+		for next < len(spans) && spans[next].start == pos {
+			reopen = append(reopen, spans[next])
+			next++
+		}
+		slices.SortStableFunc(reopen, func(a, b span) int {
+			if c := cmp.Compare(b.end, a.end); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.order, b.order)
+		})
+		for _, s := range reopen {
+			sb.WriteString(openTag(format, s.el))
+			stack = append(stack, s)
+		}
+
+		if bi+1 < len(bounds) {
+			inCode := false
+			for _, s := range stack {
+				if s.el.Type == MarkupMonospaced {
+					inCode = true
+				}
+			}
+			sb.WriteString(escapeFor(format, textBetween(units, pos, bounds[bi+1]), inCode))
+		}
+	}
+
+	return sb.String()
+}
+
+// knownMarkup reports whether the renderer supports the markup type.
+func knownMarkup(t string) bool {
+	switch t {
+	case MarkupStrong, MarkupEmphasized, MarkupMonospaced, MarkupLink,
+		MarkupStrikethrough, MarkupUnderline, MarkupUserMention:
+		return true
+	}
+	return false
+}
+
+// snapUTF16 moves an offset that points between the halves of a surrogate
+// pair to just after the pair.
+func snapUTF16(units []uint16, pos int) int {
+	if pos > 0 && pos < len(units) &&
+		units[pos-1] >= 0xd800 && units[pos-1] < 0xdc00 &&
+		units[pos] >= 0xdc00 && units[pos] < 0xe000 {
+		return pos + 1
+	}
+	return pos
+}
+
+func textBetween(units []uint16, from, to int) string {
+	return string(utf16.Decode(units[from:to]))
+}
+
+// markupTarget returns the link target of a link or user mention element.
+// A mention without a user ID links to the public profile in UserLink,
+// which is either "@username" or a full URL.
+func markupTarget(el MarkupElement) string {
+	if el.Type == MarkupLink {
+		return el.URL
+	}
+	if el.UserID != nil {
+		return UserMentionURL(*el.UserID)
+	}
+	if el.UserLink != nil && *el.UserLink != "" {
+		link := *el.UserLink
+		if strings.HasPrefix(link, "https://") || strings.HasPrefix(link, "http://") {
+			return link
+		}
+		return "https://max.ru/" + strings.TrimPrefix(link, "@")
+	}
+	return ""
+}
+
+func escapeFor(format TextFormat, s string, inCode bool) string {
+	switch format {
+	case FormatHTML:
+		return EscapeHTML(s)
+	case FormatMarkdown:
+		if inCode {
+			return markdownCodeEscaper.Replace(s)
+		}
+		return EscapeMarkdown(s)
+	default:
+		return s
+	}
+}
+
+func openTag(format TextFormat, el MarkupElement) string {
+	switch format {
+	case FormatHTML:
+		switch el.Type {
+		case MarkupStrong:
+			return "<b>"
+		case MarkupEmphasized:
+			return "<i>"
+		case MarkupMonospaced:
+			return "<code>"
+		case MarkupStrikethrough:
+			return "<s>"
+		case MarkupUnderline:
+			return "<u>"
+		case MarkupLink, MarkupUserMention:
+			if target := markupTarget(el); target != "" {
+				return `<a href="` + EscapeHTML(target) + `">`
+			}
+		}
+	case FormatMarkdown:
+		switch el.Type {
+		case MarkupStrong:
+			return "**"
+		case MarkupEmphasized:
+			return "_"
+		case MarkupMonospaced:
+			return "`"
+		case MarkupStrikethrough:
+			return "~~"
+		case MarkupUnderline:
+			return "++"
+		case MarkupLink, MarkupUserMention:
+			if markupTarget(el) != "" {
+				return "["
+			}
+		}
+	}
+	return ""
+}
+
+// closeTag returns the closing tag. text is the full text covered by the
+// element, used for plain-text links.
+func closeTag(format TextFormat, el MarkupElement, text string) string {
+	switch format {
+	case FormatHTML:
+		switch el.Type {
+		case MarkupStrong:
+			return "</b>"
+		case MarkupEmphasized:
+			return "</i>"
+		case MarkupMonospaced:
+			return "</code>"
+		case MarkupStrikethrough:
+			return "</s>"
+		case MarkupUnderline:
+			return "</u>"
+		case MarkupLink, MarkupUserMention:
+			if markupTarget(el) != "" {
+				return "</a>"
+			}
+		}
+	case FormatMarkdown:
+		switch el.Type {
+		case MarkupStrong:
+			return "**"
+		case MarkupEmphasized:
+			return "_"
+		case MarkupMonospaced:
+			return "`"
+		case MarkupStrikethrough:
+			return "~~"
+		case MarkupUnderline:
+			return "++"
+		case MarkupLink, MarkupUserMention:
+			if target := markupTarget(el); target != "" {
+				return "](" + markdownURLEscaper.Replace(target) + ")"
+			}
+		}
+	default:
+		if el.Type == MarkupLink && el.URL != "" && el.URL != text {
+			return " (" + el.URL + ")"
+		}
+	}
+	return ""
+}
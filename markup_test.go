@@ -0,0 +1,174 @@
+package maxigo
+
+import "testing"
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestRenderMarkup(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		markup []MarkupElement
+		format TextFormat
+		want   string
+	}{
+		{
+			name:   "no markup escapes text",
+			text:   "a < b *c*",
+			format: FormatHTML,
+			want:   "a &lt; b *c*",
+		},
+		{
+			name:   "simple bold html",
+			text:   "Hello world",
+			markup: []MarkupElement{{Type: MarkupStrong, From: 6, Length: 5}},
+			format: FormatHTML,
+			want:   "Hello <b>world</b>",
+		},
+		{
+			name: "nested elements",
+			text: "bold italic",
+			markup: []MarkupElement{
+				{Type: MarkupEmphasized, From: 5, Length: 6},
+				{Type: MarkupStrong, From: 0, Length: 11},
+			},
+			format: FormatHTML,
+			want:   "<b>bold <i>italic</i></b>",
+		},
+		{
+			name: "overlapping elements are split",
+			text: "abcdef",
+			markup: []MarkupElement{
+				{Type: MarkupStrong, From: 0, Length: 4},
+				{Type: MarkupEmphasized, From: 2, Length: 4},
+			},
+			format: FormatHTML,
+			want:   "<b>ab<i>cd</i></b><i>ef</i>",
+		},
+		{
+			name:   "UTF-16 offsets after emoji",
+			text:   "😀 hi there",
+			markup: []MarkupElement{{Type: MarkupStrong, From: 3, Length: 2}},
+			format: FormatHTML,
+			want:   "😀 <b>hi</b> there",
+		},
+		{
+			name:   "emoji inside element",
+			text:   "a😀b",
+			markup: []MarkupElement{{Type: MarkupUnderline, From: 1, Length: 2}},
+			format: FormatMarkdown,
+			want:   "a++😀++b",
+		},
+		{
+			name:   "offset inside surrogate pair is snapped",
+			text:   "😀x",
+			markup: []MarkupElement{{Type: MarkupStrong, From: 1, Length: 2}},
+			format: FormatHTML,
+			want:   "😀<b>x</b>",
+		},
+		{
+			name:   "out of range element is clamped",
+			text:   "abc",
+			markup: []MarkupElement{{Type: MarkupStrikethrough, From: 1, Length: 100}},
+			format: FormatHTML,
+			want:   "a<s>bc</s>",
+		},
+		{
+			name:   "link markdown",
+			text:   "see docs (v2)",
+			markup: []MarkupElement{{Type: MarkupLink, From: 4, Length: 4, URL: "https://example.com/a b"}},
+			format: FormatMarkdown,
+			want:   `see [docs](https://example.com/a%20b) \(v2\)`,
+		},
+		{
+			name:   "user mention html",
+			text:   "hi Bob",
+			markup: []MarkupElement{{Type: MarkupUserMention, From: 3, Length: 3, UserID: int64Ptr(5)}},
+			format: FormatHTML,
+			want:   `hi <a href="max://user/5">Bob</a>`,
+		},
+		{
+			name:   "mention by username links to the profile",
+			text:   "hi @bob",
+			markup: []MarkupElement{{Type: MarkupUserMention, From: 3, Length: 4, UserLink: strPtr("@bob")}},
+			format: FormatHTML,
+			want:   `hi <a href="https://max.ru/bob">@bob</a>`,
+		},
+		{
+			name:   "mention by username in plain text",
+			text:   "hi @bob",
+			markup: []MarkupElement{{Type: MarkupUserMention, From: 3, Length: 4, UserLink: strPtr("@bob")}},
+			format: "",
+			want:   "hi @bob",
+		},
+		{
+			name:   "monospaced markdown uses code escaping",
+			text:   "run a*b",
+			markup: []MarkupElement{{Type: MarkupMonospaced, From: 4, Length: 3}},
+			format: FormatMarkdown,
+			want:   "run `a*b`",
+		},
+		{
+			name: "plain text link",
+			text: "open site now",
+			markup: []MarkupElement{
+				{Type: MarkupLink, From: 5, Length: 4, URL: "https://example.com"},
+				{Type: MarkupStrong, From: 0, Length: 7},
+			},
+			format: "",
+			want:   "open site (https://example.com) now",
+		},
+		{
+			name:   "plain text link equal to URL",
+			text:   "https://example.com",
+			markup: []MarkupElement{{Type: MarkupLink, From: 0, Length: 19, URL: "https://example.com"}},
+			format: "",
+			want:   "https://example.com",
+		},
+		{
+			name:   "unknown type ignored",
+			text:   "abc",
+			markup: []MarkupElement{{Type: "sparkles", From: 0, Length: 3}},
+			format: FormatHTML,
+			want:   "abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkup(tt.text, tt.markup, tt.format); got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageBodyRender(t *testing.T) {
+	mb := MessageBody{
+		Text:   strPtr("Hello world"),
+		Markup: []MarkupElement{{Type: MarkupStrong, From: 0, Length: 5}},
+	}
+	if got := mb.Render(FormatMarkdown); got != "**Hello** world" {
+		t.Errorf("Render() = %q, want %q", got, "**Hello** world")
+	}
+
+	empty := MessageBody{}
+	if got := empty.Render(FormatHTML); got != "" {
+		t.Errorf("Render() on nil text = %q, want empty", got)
+	}
+}
+
+func TestRenderMarkupUserLinkRoundTrip(t *testing.T) {
+	// A username mention renders to the same text a bot would build to
+	// mention that user, for every format.
+	mb := MessageBody{
+		Text:   strPtr("ping @bob_smith"),
+		Markup: []MarkupElement{{Type: MarkupUserMention, From: 5, Length: 10, UserLink: strPtr("@bob_smith")}},
+	}
+	for _, format := range []TextFormat{FormatHTML, FormatMarkdown} {
+		want := NewTextBuilder(format).Text("ping ").Link("@bob_smith", "https://max.ru/bob_smith").String()
+		if got := mb.Render(format); got != want {
+			t.Errorf("%s: got %q, want %q", format, got, want)
+		}
+	}
+}
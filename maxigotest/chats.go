@@ -0,0 +1,684 @@
+package maxigotest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// allPermissions are the permissions of chat owners.
+var allPermissions = []maxigo.ChatAdminPermission{
+	maxigo.PermReadAllMessages,
+	maxigo.PermAddRemoveMembers,
+	maxigo.PermAddAdmins,
+	maxigo.PermChangeChatInfo,
+	maxigo.PermPinMessage,
+	maxigo.PermWrite,
+	maxigo.PermEditLink,
+	maxigo.PermPostEditDeleteMessage,
+	maxigo.PermEditMessage,
+	maxigo.PermDeleteMessage,
+}
+
+// chatState is a chat and everything that happened in it.
+type chatState struct {
+	chat    maxigo.Chat // participant count and pinned message are computed
+	members []*member   // in join order
+	mids    []string    // message IDs, oldest first
+	pinned  string
+	actions []maxigo.SenderAction
+	blocked map[int64]bool
+}
+
+// member is a chat member.
+type member struct {
+	userID   int64
+	joinTime int64
+	owner    bool
+	admin    bool
+	perms    []maxigo.ChatAdminPermission
+	alias    *string
+}
+
+func (cs *chatState) member(userID int64) *member {
+	i := slices.IndexFunc(cs.members, func(m *member) bool { return m.userID == userID })
+	if i < 0 {
+		return nil
+	}
+	return cs.members[i]
+}
+
+// AddChat creates a group chat with the bot and the given users as members.
+// The first user is the owner, or the bot if there are none; the bot is an
+// administrator with all permissions. Change its rights with
+// [Server.SetAdmin].
+func (s *Server) AddChat(title string, memberIDs ...int64) maxigo.Chat {
+	return s.addChat(maxigo.ChatGroup, title, memberIDs)
+}
+
+// AddChannel creates a channel with the given subscribers. Like
+// [Server.AddChat], the bot is an administrator with all permissions.
+func (s *Server) AddChannel(title string, subscriberIDs ...int64) maxigo.Chat {
+	return s.addChat(maxigo.ChatChannel, title, subscriberIDs)
+}
+
+func (s *Server) addChat(typ maxigo.ChatType, title string, memberIDs []int64) maxigo.Chat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs := s.newChat(typ)
+	cs.chat.Title = strPtr(title)
+
+	now := s.now()
+	cs.members = append(cs.members, &member{userID: s.bot.UserID, joinTime: now, admin: true, perms: allPermissions})
+	for _, id := range memberIDs {
+		if cs.member(id) == nil {
+			cs.members = append(cs.members, &member{userID: id, joinTime: now})
+		}
+	}
+	owner := cs.members[min(1, len(cs.members)-1)]
+	owner.owner, owner.admin, owner.perms = true, true, allPermissions
+	cs.chat.OwnerID = &owner.userID
+	return s.chatView(cs)
+}
+
+func (s *Server) newChat(typ maxigo.ChatType) *chatState {
+	cs := &chatState{
+		chat: maxigo.Chat{
+			ChatID:        -s.newID(),
+			Type:          typ,
+			Status:        maxigo.ChatStatusActive,
+			LastEventTime: s.now(),
+		},
+		blocked: make(map[int64]bool),
+	}
+	s.chats[cs.chat.ChatID] = cs
+	s.chatOrder = append(s.chatOrder, cs.chat.ChatID)
+	return cs
+}
+
+// dialog returns the dialog of the bot with a user, creating it if needed.
+func (s *Server) dialog(userID int64) *chatState {
+	if cs, ok := s.chats[s.dialogs[userID]]; ok {
+		return cs
+	}
+	cs := s.newChat(maxigo.ChatDialog)
+	cs.chat.DialogWithUser = &maxigo.UserWithPhoto{User: *s.users[userID]}
+	now := s.now()
+	cs.members = []*member{{userID: s.bot.UserID, joinTime: now}, {userID: userID, joinTime: now}}
+	s.dialogs[userID] = cs.chat.ChatID
+	return cs
+}
+
+// dialogUser returns the user the bot talks to in a dialog.
+func (s *Server) dialogUser(cs *chatState) int64 {
+	for _, m := range cs.members {
+		if m.userID != s.bot.UserID {
+			return m.userID
+		}
+	}
+	return 0
+}
+
+// SetAdmin makes a chat member an administrator with perms, or a regular
+// member if perms is empty. Pass the bot's user ID ([Server.Bot]) to change
+// the bot's own rights. It reports false if the chat or member is unknown.
+func (s *Server) SetAdmin(chatID, userID int64, perms ...maxigo.ChatAdminPermission) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs, ok := s.chats[chatID]
+	if !ok {
+		return false
+	}
+	m := cs.member(userID)
+	if m == nil {
+		return false
+	}
+	m.admin, m.perms = len(perms) > 0, slices.Clone(perms)
+	return true
+}
+
+// SetChatLink sets the public link of a chat, e.g. "https://max.ru/team",
+// for lookup by link or by its last path element.
+func (s *Server) SetChatLink(chatID int64, link string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs, ok := s.chats[chatID]
+	if ok {
+		cs.chat.Link = strPtr(link)
+		cs.chat.IsPublic = true
+	}
+	return ok
+}
+
+// Chat returns a chat as the bot sees it.
+func (s *Server) Chat(chatID int64) (maxigo.Chat, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs, ok := s.chats[chatID]
+	if !ok {
+		return maxigo.Chat{}, false
+	}
+	return s.chatView(cs), true
+}
+
+// Members returns the members of a chat in join order.
+func (s *Server) Members(chatID int64) []maxigo.ChatMember {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs, ok := s.chats[chatID]
+	if !ok {
+		return nil
+	}
+	members := make([]maxigo.ChatMember, len(cs.members))
+	for i, m := range cs.members {
+		members[i] = s.chatMember(m)
+	}
+	return members
+}
+
+// Actions returns the actions the bot sent to a chat, e.g. typing_on.
+func (s *Server) Actions(chatID int64) []maxigo.SenderAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cs, ok := s.chats[chatID]; ok {
+		return slices.Clone(cs.actions)
+	}
+	return nil
+}
+
+func (s *Server) chatView(cs *chatState) maxigo.Chat {
+	chat := cs.chat
+	chat.ParticipantsCount = len(cs.members)
+	if msg, ok := s.messages[cs.pinned]; ok {
+		pinned := *msg
+		chat.PinnedMessage = &pinned
+	}
+	return chat
+}
+
+func (s *Server) chatMember(m *member) maxigo.ChatMember {
+	cm := maxigo.ChatMember{
+		JoinTime:       m.joinTime,
+		LastAccessTime: m.joinTime,
+		IsOwner:        m.owner,
+		IsAdmin:        m.admin,
+		Permissions:    slices.Clone(m.perms),
+		Alias:          m.alias,
+	}
+	if u, ok := s.users[m.userID]; ok {
+		cm.User = *u
+	} else {
+		cm.UserID = m.userID
+	}
+	return cm
+}
+
+// chatParam returns the chat in the {chat} path segment.
+func (s *Server) chatParam(r *http.Request) (*chatState, *apiError) {
+	raw := r.PathValue("chat")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, badRequest("Invalid chat_id %q", raw)
+	}
+	cs, ok := s.chats[id]
+	if !ok {
+		return nil, notFound("Chat %d not found", id)
+	}
+	return cs, nil
+}
+
+// requireMember checks that the bot is a member of the chat.
+func (s *Server) requireMember(cs *chatState) *apiError {
+	if cs.member(s.bot.UserID) == nil {
+		return errorf(http.StatusForbidden, "chat.denied", "Bot is not a member of chat %d", cs.chat.ChatID)
+	}
+	return nil
+}
+
+// requireAdmin checks that the bot may manage the chat: in group chats and
+// channels it must be the owner or an administrator with perm ("" for any
+// administrator).
+func (s *Server) requireAdmin(cs *chatState, perm maxigo.ChatAdminPermission) *apiError {
+	if err := s.requireMember(cs); err != nil {
+		return err
+	}
+	bot := cs.member(s.bot.UserID)
+	if cs.chat.Type == maxigo.ChatDialog || bot.owner {
+		return nil
+	}
+	if !bot.admin {
+		return forbidden("Bot is not an administrator of chat %d", cs.chat.ChatID)
+	}
+	if perm != "" && !slices.Contains(bot.perms, perm) {
+		return forbidden("Bot has no %s permission in chat %d", perm, cs.chat.ChatID)
+	}
+	return nil
+}
+
+// requireGroup rejects requests that make no sense for dialogs.
+func requireGroup(cs *chatState) *apiError {
+	if cs.chat.Type == maxigo.ChatDialog {
+		return badRequest("Chat %d is a dialog", cs.chat.ChatID)
+	}
+	return nil
+}
+
+// intParam parses an optional integer query parameter in [lo, hi].
+func intParam(r *http.Request, name string, def, lo, hi int64) (int64, *apiError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v < lo || v > hi {
+		return 0, badRequest("Invalid %s %q", name, raw)
+	}
+	return v, nil
+}
+
+// page returns items[marker:marker+count] and the marker of the next page.
+func page[T any](items []T, marker, count int64) ([]T, *int64) {
+	start := min(marker, int64(len(items)))
+	end := min(start+count, int64(len(items)))
+	if end == int64(len(items)) {
+		return items[start:end], nil
+	}
+	return items[start:end], &end
+}
+
+// getChats serves GET /chats.
+func (s *Server) getChats(r *http.Request, _ []byte) (any, *apiError) {
+	count, err := intParam(r, "count", 50, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+	marker, err := intParam(r, "marker", 0, 0, 1<<62)
+	if err != nil {
+		return nil, err
+	}
+
+	chats := []maxigo.Chat{}
+	for _, id := range s.chatOrder {
+		if cs := s.chats[id]; cs.member(s.bot.UserID) != nil {
+			chats = append(chats, s.chatView(cs))
+		}
+	}
+	chats, next := page(chats, marker, count)
+	return maxigo.ChatList{Chats: chats, Marker: next}, nil
+}
+
+// getChat serves GET /chats/{chat} by chat ID or public link.
+func (s *Server) getChat(r *http.Request, _ []byte) (any, *apiError) {
+	raw := r.PathValue("chat")
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		cs, err := s.chatParam(r)
+		if err != nil {
+			return nil, err
+		}
+		return s.chatView(cs), nil
+	}
+	for _, id := range s.chatOrder {
+		cs := s.chats[id]
+		if link := cs.chat.Link; link != nil && (*link == raw || strings.TrimPrefix(*link, "https://max.ru/") == raw) {
+			return s.chatView(cs), nil
+		}
+	}
+	return nil, notFound("Chat %s not found", raw)
+}
+
+// editChat serves PATCH /chats/{chat}.
+func (s *Server) editChat(r *http.Request, body []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var patch maxigo.ChatPatch
+	if err := decode(body, &patch); err != nil {
+		return nil, err
+	}
+	if err := checkChatPatch(&patch); err != nil {
+		return nil, err
+	}
+	if err := requireGroup(cs); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, maxigo.PermChangeChatInfo); err != nil {
+		return nil, err
+	}
+	if patch.Pin.Set && !slices.Contains(cs.mids, patch.Pin.Value) {
+		return nil, notFound("Message %s not found in chat %d", patch.Pin.Value, cs.chat.ChatID)
+	}
+
+	if patch.Title.Set {
+		cs.chat.Title = strPtr(patch.Title.Value)
+	}
+	if patch.Icon != nil {
+		url := s.URL + "/icons/" + strconv.FormatInt(-cs.chat.ChatID, 10)
+		if patch.Icon.URL.Set {
+			url = patch.Icon.URL.Value
+		}
+		cs.chat.Icon = &maxigo.Image{URL: url}
+	}
+	if patch.Pin.Set {
+		cs.pinned = patch.Pin.Value
+	}
+	cs.chat.LastEventTime = s.now()
+	return s.chatView(cs), nil
+}
+
+// deleteChat serves DELETE /chats/{chat}.
+func (s *Server) deleteChat(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, ""); err != nil {
+		return nil, err
+	}
+	id := cs.chat.ChatID
+	for _, mid := range cs.mids {
+		delete(s.messages, mid)
+	}
+	delete(s.chats, id)
+	s.chatOrder = slices.DeleteFunc(s.chatOrder, func(c int64) bool { return c == id })
+	if cs.chat.Type == maxigo.ChatDialog {
+		delete(s.dialogs, s.dialogUser(cs))
+	}
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// getMembers serves GET /chats/{chat}/members.
+func (s *Server) getMembers(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireMember(cs); err != nil {
+		return nil, err
+	}
+	count, err := intParam(r, "count", 20, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+	marker, err := intParam(r, "marker", 0, 0, 1<<62)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter []int64
+	if raw := r.URL.Query().Get("user_ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, perr := strconv.ParseInt(part, 10, 64)
+			if perr != nil {
+				return nil, badRequest("Invalid user_ids %q", raw)
+			}
+			filter = append(filter, id)
+		}
+	}
+
+	members := []maxigo.ChatMember{}
+	for _, m := range cs.members {
+		if filter == nil || slices.Contains(filter, m.userID) {
+			members = append(members, s.chatMember(m))
+		}
+	}
+	members, next := page(members, marker, count)
+	return maxigo.ChatMembersList{Members: members, Marker: next}, nil
+}
+
+// addMembers serves POST /chats/{chat}/members.
+func (s *Server) addMembers(r *http.Request, body []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var list maxigo.UserIDsList
+	if err := decode(body, &list); err != nil {
+		return nil, err
+	}
+	if len(list.UserIDs) == 0 {
+		return nil, badRequest("user_ids: must not be empty")
+	}
+	if err := requireGroup(cs); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, maxigo.PermAddRemoveMembers); err != nil {
+		return nil, err
+	}
+	for _, id := range list.UserIDs {
+		if _, ok := s.users[id]; !ok {
+			return nil, notFound("User %d not found", id)
+		}
+		if cs.blocked[id] {
+			return nil, forbidden("User %d is blocked in chat %d", id, cs.chat.ChatID)
+		}
+	}
+
+	now := s.now()
+	for _, id := range list.UserIDs {
+		if cs.member(id) == nil {
+			cs.members = append(cs.members, &member{userID: id, joinTime: now})
+		}
+	}
+	cs.chat.LastEventTime = now
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// removeMember serves DELETE /chats/{chat}/members.
+func (s *Server) removeMember(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := intParam(r, "user_id", 0, 1, 1<<62)
+	if err != nil {
+		return nil, err
+	}
+	if userID == 0 {
+		return nil, badRequest("user_id is required")
+	}
+	if err := requireGroup(cs); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, maxigo.PermAddRemoveMembers); err != nil {
+		return nil, err
+	}
+	m := cs.member(userID)
+	if m == nil {
+		return nil, notFound("User %d is not a member of chat %d", userID, cs.chat.ChatID)
+	}
+	if m.owner {
+		return nil, forbidden("Chat owner can't be removed")
+	}
+
+	cs.members = slices.DeleteFunc(cs.members, func(m *member) bool { return m.userID == userID })
+	if r.URL.Query().Get("block") == "true" {
+		cs.blocked[userID] = true
+	}
+	cs.chat.LastEventTime = s.now()
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// getAdmins serves GET /chats/{chat}/members/admins.
+func (s *Server) getAdmins(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireGroup(cs); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, ""); err != nil {
+		return nil, err
+	}
+	admins := []maxigo.ChatAdmin{}
+	for _, m := range cs.members {
+		if m.admin {
+			admins = append(admins, maxigo.ChatAdmin{UserID: m.userID, Permissions: slices.Clone(m.perms), Alias: m.alias})
+		}
+	}
+	return maxigo.ChatAdminsList{Admins: admins}, nil
+}
+
+// setAdmins serves POST /chats/{chat}/members/admins.
+func (s *Server) setAdmins(r *http.Request, body []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var list maxigo.ChatAdminsList
+	if err := decode(body, &list); err != nil {
+		return nil, err
+	}
+	if err := checkAdmins(&list); err != nil {
+		return nil, err
+	}
+	if err := requireGroup(cs); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, maxigo.PermAddAdmins); err != nil {
+		return nil, err
+	}
+	for _, a := range list.Admins {
+		if cs.member(a.UserID) == nil {
+			return nil, notFound("User %d is not a member of chat %d", a.UserID, cs.chat.ChatID)
+		}
+	}
+
+	for _, a := range list.Admins {
+		m := cs.member(a.UserID)
+		m.admin, m.perms, m.alias = true, slices.Clone(a.Permissions), a.Alias
+	}
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// removeAdmin serves DELETE /chats/{chat}/members/admins/{user}.
+func (s *Server) removeAdmin(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	userID, perr := strconv.ParseInt(r.PathValue("user"), 10, 64)
+	if perr != nil {
+		return nil, badRequest("Invalid user_id %q", r.PathValue("user"))
+	}
+	if err := requireGroup(cs); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, maxigo.PermAddAdmins); err != nil {
+		return nil, err
+	}
+	m := cs.member(userID)
+	if m == nil || !m.admin {
+		return nil, notFound("User %d is not an administrator of chat %d", userID, cs.chat.ChatID)
+	}
+	if m.owner {
+		return nil, forbidden("Chat owner can't be demoted")
+	}
+
+	m.admin, m.perms, m.alias = false, nil, nil
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// getMembership serves GET /chats/{chat}/members/me.
+func (s *Server) getMembership(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireMember(cs); err != nil {
+		return nil, err
+	}
+	return s.chatMember(cs.member(s.bot.UserID)), nil
+}
+
+// leaveChat serves DELETE /chats/{chat}/members/me.
+func (s *Server) leaveChat(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireGroup(cs); err != nil {
+		return nil, err
+	}
+	if err := s.requireMember(cs); err != nil {
+		return nil, err
+	}
+	cs.members = slices.DeleteFunc(cs.members, func(m *member) bool { return m.userID == s.bot.UserID })
+	cs.chat.Status = maxigo.ChatStatusLeft
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// sendAction serves POST /chats/{chat}/actions.
+func (s *Server) sendAction(r *http.Request, body []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var req maxigo.ActionRequestBody
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	switch req.Action {
+	case maxigo.ActionTypingOn, maxigo.ActionSendPhoto, maxigo.ActionSendVideo,
+		maxigo.ActionSendAudio, maxigo.ActionSendFile, maxigo.ActionMarkSeen:
+	default:
+		return nil, badRequest("Unknown action %q", req.Action)
+	}
+	if err := s.requireMember(cs); err != nil {
+		return nil, err
+	}
+	cs.actions = append(cs.actions, req.Action)
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// getPinned serves GET /chats/{chat}/pin.
+func (s *Server) getPinned(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireMember(cs); err != nil {
+		return nil, err
+	}
+	return maxigo.GetPinnedMessageResult{Message: s.chatView(cs).PinnedMessage}, nil
+}
+
+// pinMessage serves PUT /chats/{chat}/pin.
+func (s *Server) pinMessage(r *http.Request, body []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var req maxigo.PinMessageBody
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.MessageID == "" {
+		return nil, badRequest("message_id is required")
+	}
+	if err := s.requireAdmin(cs, maxigo.PermPinMessage); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(cs.mids, req.MessageID) {
+		return nil, notFound("Message %s not found in chat %d", req.MessageID, cs.chat.ChatID)
+	}
+	cs.pinned = req.MessageID
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// unpinMessage serves DELETE /chats/{chat}/pin.
+func (s *Server) unpinMessage(r *http.Request, _ []byte) (any, *apiError) {
+	cs, err := s.chatParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(cs, maxigo.PermPinMessage); err != nil {
+		return nil, err
+	}
+	cs.pinned = ""
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
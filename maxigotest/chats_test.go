@@ -0,0 +1,149 @@
+package maxigotest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+func TestServerChats(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice, bob := srv.AddUser("Alice"), srv.AddUser("Bob")
+	team := srv.AddChat("Team", alice.UserID)
+	news := srv.AddChannel("News")
+	srv.SetChatLink(news.ChatID, "https://max.ru/news")
+
+	chat, err := c.GetChat(ctx, team.ChatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.Type != maxigo.ChatGroup || *chat.Title != "Team" || chat.ParticipantsCount != 2 || *chat.OwnerID != alice.UserID {
+		t.Errorf("chat = %+v", chat)
+	}
+	if chat, err := c.GetChatByLink(ctx, "news"); err != nil || chat.ChatID != news.ChatID {
+		t.Errorf("GetChatByLink = %+v, %v", chat, err)
+	}
+	_, err = c.GetChat(ctx, 42)
+	wantAPIError(t, err, http.StatusNotFound)
+
+	list, err := c.GetChats(ctx, maxigo.GetChatsOpts{Count: 1})
+	if err != nil || len(list.Chats) != 1 || list.Marker == nil {
+		t.Fatalf("GetChats = %+v, %v", list, err)
+	}
+	list, err = c.GetChats(ctx, maxigo.GetChatsOpts{Count: 1, Marker: *list.Marker})
+	if err != nil || len(list.Chats) != 1 || list.Chats[0].ChatID != news.ChatID || list.Marker != nil {
+		t.Fatalf("GetChats page 2 = %+v, %v", list, err)
+	}
+
+	if _, err := c.EditChat(ctx, team.ChatID, &maxigo.ChatPatch{Title: maxigo.Some("Core team")}); err != nil {
+		t.Fatal(err)
+	}
+	if chat, _ := srv.Chat(team.ChatID); *chat.Title != "Core team" {
+		t.Errorf("title = %q", *chat.Title)
+	}
+
+	if _, err := c.AddMembers(ctx, team.ChatID, []int64{bob.UserID}); err != nil {
+		t.Fatal(err)
+	}
+	members, err := c.GetMembers(ctx, team.ChatID, maxigo.GetMembersOpts{UserIDs: []int64{bob.UserID}})
+	if err != nil || len(members.Members) != 1 || members.Members[0].FirstName != "Bob" {
+		t.Fatalf("GetMembers = %+v, %v", members, err)
+	}
+	_, err = c.AddMembers(ctx, team.ChatID, []int64{999})
+	wantAPIError(t, err, http.StatusNotFound)
+
+	if _, err := c.RemoveMember(ctx, team.ChatID, bob.UserID, true); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.AddMembers(ctx, team.ChatID, []int64{bob.UserID})
+	wantAPIError(t, err, http.StatusForbidden)
+	_, err = c.RemoveMember(ctx, team.ChatID, alice.UserID, false)
+	wantAPIError(t, err, http.StatusForbidden)
+
+	if _, err := c.DeleteChat(ctx, news.ChatID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.Chat(news.ChatID); ok {
+		t.Error("channel still exists")
+	}
+}
+
+func TestServerAdmins(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice, bob := srv.AddUser("Alice"), srv.AddUser("Bob")
+	team := srv.AddChat("Team", alice.UserID, bob.UserID)
+
+	if _, err := c.SetAdmins(ctx, team.ChatID, &maxigo.ChatAdminsList{Admins: []maxigo.ChatAdmin{
+		{UserID: bob.UserID, Permissions: []maxigo.ChatAdminPermission{maxigo.PermPinMessage}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	admins, err := c.GetAdmins(ctx, team.ChatID)
+	if err != nil || len(admins.Admins) != 3 {
+		t.Fatalf("GetAdmins = %+v, %v; want bot, owner and Bob", admins, err)
+	}
+	if _, err := c.RemoveAdmin(ctx, team.ChatID, bob.UserID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.RemoveAdmin(ctx, team.ChatID, bob.UserID)
+	wantAPIError(t, err, http.StatusNotFound)
+
+	// Without admin rights the bot can't manage the chat.
+	srv.SetAdmin(team.ChatID, srv.Bot().UserID)
+	_, err = c.GetAdmins(ctx, team.ChatID)
+	wantAPIError(t, err, http.StatusForbidden)
+	_, err = c.EditChat(ctx, team.ChatID, &maxigo.ChatPatch{Title: maxigo.Some("x")})
+	wantAPIError(t, err, http.StatusForbidden)
+
+	me, err := c.GetMembership(ctx, team.ChatID)
+	if err != nil || me.UserID != srv.Bot().UserID || me.IsAdmin {
+		t.Errorf("GetMembership = %+v, %v", me, err)
+	}
+	if _, err := c.LeaveChat(ctx, team.ChatID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetMembership(ctx, team.ChatID)
+	wantAPIError(t, err, http.StatusForbidden)
+	if chat, _ := srv.Chat(team.ChatID); chat.Status != maxigo.ChatStatusLeft {
+		t.Errorf("status = %q, want left", chat.Status)
+	}
+}
+
+func TestServerPinAndActions(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	team := srv.AddChat("Team")
+
+	msg, err := c.SendMessage(ctx, team.ChatID, &maxigo.NewMessageBody{Text: maxigo.Some("rules")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.PinMessage(ctx, team.ChatID, &maxigo.PinMessageBody{MessageID: msg.Body.MID}); err != nil {
+		t.Fatal(err)
+	}
+	pinned, err := c.GetPinnedMessage(ctx, team.ChatID)
+	if err != nil || pinned.Message == nil || pinned.Message.Body.MID != msg.Body.MID {
+		t.Fatalf("GetPinnedMessage = %+v, %v", pinned, err)
+	}
+	_, err = c.PinMessage(ctx, team.ChatID, &maxigo.PinMessageBody{MessageID: "mid.404"})
+	wantAPIError(t, err, http.StatusNotFound)
+	if _, err := c.UnpinMessage(ctx, team.ChatID); err != nil {
+		t.Fatal(err)
+	}
+	if pinned, _ := c.GetPinnedMessage(ctx, team.ChatID); pinned.Message != nil {
+		t.Errorf("pinned = %+v, want none", pinned.Message)
+	}
+
+	if _, err := c.SendAction(ctx, team.ChatID, maxigo.ActionTypingOn); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.SendAction(ctx, team.ChatID, "dancing")
+	wantAPIError(t, err, http.StatusBadRequest)
+	if got := srv.Actions(team.ChatID); len(got) != 1 || got[0] != maxigo.ActionTypingOn {
+		t.Errorf("actions = %v", got)
+	}
+}
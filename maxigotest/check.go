@@ -0,0 +1,266 @@
+package maxigotest
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	maxigo "github.com/maxigo-bot/maxigo-client"
+)
+
+// The checks in this file follow the request rules documented for the Max
+// Bot API. They are written out here instead of calling the Validate
+// methods of the client, so the fake server catches client-side validation
+// bugs instead of repeating them.
+
+// Limits of the Max Bot API for bot profiles.
+const (
+	maxBotCommands          = 32
+	maxBotDescriptionLength = 16000
+)
+
+// knownButtonTypes lists the inline keyboard button types of the Max API.
+var knownButtonTypes = map[string]bool{
+	"callback":             true,
+	"link":                 true,
+	"request_contact":      true,
+	"request_geo_location": true,
+	"chat":                 true,
+	"message":              true,
+	"open_app":             true,
+}
+
+// knownPermissions lists the chat admin permissions of the Max API.
+var knownPermissions = map[maxigo.ChatAdminPermission]bool{
+	maxigo.PermReadAllMessages:       true,
+	maxigo.PermAddRemoveMembers:      true,
+	maxigo.PermAddAdmins:             true,
+	maxigo.PermChangeChatInfo:        true,
+	maxigo.PermPinMessage:            true,
+	maxigo.PermWrite:                 true,
+	maxigo.PermCanCall:               true,
+	maxigo.PermEditLink:              true,
+	maxigo.PermPostEditDeleteMessage: true,
+	maxigo.PermEditMessage:           true,
+	maxigo.PermDeleteMessage:         true,
+}
+
+// invalidField returns a 400 response for an invalid field of the request body.
+func invalidField(field, format string, args ...any) *apiError {
+	if field == "" {
+		return badRequest(format, args...)
+	}
+	return badRequest(field+": "+format, args...)
+}
+
+// textLength returns the length of s in UTF-16 code units, the unit the
+// Max API uses for text limits.
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// checkMessageBody checks a message body sent as a new message
+// (requireContent) or as an edit. A new message needs text, attachments
+// or a forwarded message.
+func checkMessageBody(req *maxigo.NewMessageBody, prefix string, requireContent bool) *apiError {
+	forward := req.Link != nil && req.Link.Type == maxigo.LinkForward
+	if requireContent && req.Text.Value == "" && len(req.Attachments) == 0 && !forward {
+		return invalidField(prefix+"text", "message must have text or attachments")
+	}
+	if n := textLength(req.Text.Value); n > maxigo.MaxTextLength {
+		return invalidField(prefix+"text", "is %d characters, limit is %d", n, maxigo.MaxTextLength)
+	}
+	if req.Format.Set && req.Format.Value != maxigo.FormatMarkdown && req.Format.Value != maxigo.FormatHTML {
+		return invalidField(prefix+"format", "unknown format %q", req.Format.Value)
+	}
+	if req.Link != nil {
+		if req.Link.Type != maxigo.LinkReply && req.Link.Type != maxigo.LinkForward {
+			return invalidField(prefix+"link.type", "unknown link type %q", req.Link.Type)
+		}
+		if req.Link.MID == "" {
+			return invalidField(prefix+"link.mid", "must not be empty")
+		}
+	}
+	for i, att := range req.Attachments {
+		field := prefix + "attachments[" + strconv.Itoa(i) + "]"
+		switch att.Type {
+		case "":
+			return invalidField(field+".type", "must not be empty")
+		case "inline_keyboard":
+			kb, ok := att.Payload.(maxigo.Keyboard)
+			if !ok {
+				return invalidField(field+".payload", "must be an inline keyboard")
+			}
+			if err := checkKeyboard(kb.Buttons, field+".payload.buttons"); err != nil {
+				return err
+			}
+		case "location":
+			if att.Latitude < -90 || att.Latitude > 90 {
+				return invalidField(field+".latitude", "must be between -90 and 90")
+			}
+			if att.Longitude < -180 || att.Longitude > 180 {
+				return invalidField(field+".longitude", "must be between -180 and 180")
+			}
+		case "reply_keyboard":
+			if len(att.Buttons) == 0 {
+				return invalidField(field+".buttons", "must not be empty")
+			}
+		default:
+			if att.Payload == nil {
+				return invalidField(field+".payload", "must not be empty")
+			}
+		}
+	}
+	return nil
+}
+
+// checkKeyboard checks the rows of an inline keyboard.
+func checkKeyboard(rows [][]maxigo.Button, field string) *apiError {
+	if len(rows) == 0 {
+		return invalidField(field, "keyboard has no buttons")
+	}
+	if len(rows) > maxigo.MaxKeyboardRows {
+		return invalidField(field, "has %d rows, limit is %d", len(rows), maxigo.MaxKeyboardRows)
+	}
+	total := 0
+	for i, row := range rows {
+		rowField := field + "[" + strconv.Itoa(i) + "]"
+		if len(row) == 0 {
+			return invalidField(rowField, "row is empty")
+		}
+		limit := maxigo.MaxButtonsPerRow
+		for _, b := range row {
+			switch b.Type {
+			case "link", "open_app", "request_contact", "request_geo_location":
+				limit = maxigo.MaxWideButtonsPerRow
+			}
+		}
+		if len(row) > limit {
+			return invalidField(rowField, "has %d buttons, limit is %d", len(row), limit)
+		}
+		for j, b := range row {
+			if err := checkButton(b, rowField+"["+strconv.Itoa(j)+"]"); err != nil {
+				return err
+			}
+		}
+		total += len(row)
+	}
+	if total > maxigo.MaxKeyboardButtons {
+		return invalidField(field, "has %d buttons, limit is %d", total, maxigo.MaxKeyboardButtons)
+	}
+	return nil
+}
+
+// checkButton checks a single inline keyboard button.
+func checkButton(b maxigo.Button, field string) *apiError {
+	if b.Text == "" {
+		return invalidField(field+".text", "must not be empty")
+	}
+	if n := textLength(b.Text); n > maxigo.MaxButtonTextLength {
+		return invalidField(field+".text", "is %d characters, limit is %d", n, maxigo.MaxButtonTextLength)
+	}
+	if !knownButtonTypes[b.Type] {
+		return invalidField(field+".type", "unknown button type %q", b.Type)
+	}
+	switch b.Type {
+	case "callback":
+		if b.Payload == "" {
+			return invalidField(field+".payload", "must not be empty for callback buttons")
+		}
+		if n := textLength(b.Payload); n > maxigo.MaxCallbackPayloadLength {
+			return invalidField(field+".payload", "is %d characters, limit is %d", n, maxigo.MaxCallbackPayloadLength)
+		}
+	case "link":
+		u, err := url.Parse(b.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalidField(field+".url", "must be an absolute http or https URL")
+		}
+	case "chat":
+		if b.ChatTitle == "" {
+			return invalidField(field+".chat_title", "must not be empty for chat buttons")
+		}
+	case "open_app":
+		if b.WebApp == "" {
+			return invalidField(field+".web_app", "must not be empty for open_app buttons")
+		}
+	}
+	return nil
+}
+
+// checkAnswer checks a callback answer: it must carry a message to edit
+// the original one with, a notification, or both.
+func checkAnswer(answer *maxigo.CallbackAnswer) *apiError {
+	if answer.Message == nil && !answer.Notification.Set {
+		return invalidField("", "either message or notification must be set")
+	}
+	if answer.Notification.Set && answer.Notification.Value == "" {
+		return invalidField("notification", "must not be empty")
+	}
+	if answer.Message != nil {
+		return checkMessageBody(answer.Message, "message.", false)
+	}
+	return nil
+}
+
+// checkChatPatch checks the body of PATCH /chats/{chat}.
+func checkChatPatch(patch *maxigo.ChatPatch) *apiError {
+	if patch.Icon == nil && !patch.Title.Set && !patch.Pin.Set && !patch.Notify.Set {
+		return invalidField("", "nothing to update")
+	}
+	if patch.Title.Set && strings.TrimSpace(patch.Title.Value) == "" {
+		return invalidField("title", "must not be empty")
+	}
+	if icon := patch.Icon; icon != nil && !icon.URL.Set && !icon.Token.Set && len(icon.Photos) == 0 {
+		return invalidField("icon", "must have url, token or photos")
+	}
+	return nil
+}
+
+// checkBotPatch checks the body of PATCH /me.
+func checkBotPatch(patch *maxigo.BotPatch) *apiError {
+	if !patch.Name.Set && !patch.FirstName.Set && !patch.Description.Set && patch.Commands == nil && patch.Photo == nil {
+		return invalidField("", "nothing to update")
+	}
+	if patch.FirstName.Set && strings.TrimSpace(patch.FirstName.Value) == "" {
+		return invalidField("first_name", "must not be empty")
+	}
+	if n := textLength(patch.Description.Value); n > maxBotDescriptionLength {
+		return invalidField("description", "is %d characters, limit is %d", n, maxBotDescriptionLength)
+	}
+	if len(patch.Commands) > maxBotCommands {
+		return invalidField("commands", "has %d commands, limit is %d", len(patch.Commands), maxBotCommands)
+	}
+	for i, cmd := range patch.Commands {
+		if cmd.Name == "" {
+			return invalidField("commands["+strconv.Itoa(i)+"].name", "must not be empty")
+		}
+	}
+	if photo := patch.Photo; photo != nil && !photo.URL.Set && !photo.Token.Set && len(photo.Photos) == 0 {
+		return invalidField("photo", "must have url, token or photos")
+	}
+	return nil
+}
+
+// checkAdmins checks the body of POST /chats/{chat}/members/admins.
+func checkAdmins(list *maxigo.ChatAdminsList) *apiError {
+	if len(list.Admins) == 0 {
+		return invalidField("admins", "must not be empty")
+	}
+	for i, admin := range list.Admins {
+		field := "admins[" + strconv.Itoa(i) + "]"
+		if admin.UserID == 0 {
+			return invalidField(field+".user_id", "must be set")
+		}
+		for j, perm := range admin.Permissions {
+			if !knownPermissions[perm] {
+				return invalidField(field+".permissions["+strconv.Itoa(j)+"]", "unknown permission %q", perm)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package maxigotest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// Answer is a callback answer received by a [Server].
+type Answer struct {
+	// CallbackID identifies the button press.
+	CallbackID string
+	// Answer is the request body.
+	Answer maxigo.CallbackAnswer
+}
+
+// Messages returns the messages currently in a chat, oldest first. Edits
+// are applied and deleted messages are left out; see [Server.Sent] for
+// messages as they were sent.
+func (s *Server) Messages(chatID int64) []maxigo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs, ok := s.chats[chatID]
+	if !ok {
+		return nil
+	}
+	msgs := make([]maxigo.Message, len(cs.mids))
+	for i, mid := range cs.mids {
+		msgs[i] = *s.messages[mid]
+	}
+	return msgs
+}
+
+// Message returns a message by its ID.
+func (s *Server) Message(mid string) (maxigo.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.messages[mid]
+	if !ok {
+		return maxigo.Message{}, false
+	}
+	return *msg, true
+}
+
+// Answers returns all callback answers the bot sent, in order.
+func (s *Server) Answers() []Answer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.answers)
+}
+
+// addMessage stores a new message from sender in a chat.
+func (s *Server) addMessage(cs *chatState, sender maxigo.User, body maxigo.MessageBody, link *maxigo.LinkedMessage) *maxigo.Message {
+	s.nextMID++
+	body.MID = "mid." + strconv.FormatInt(s.nextMID, 10)
+	body.Seq = s.nextMID
+
+	chatID := cs.chat.ChatID
+	msg := &maxigo.Message{
+		Sender:    &sender,
+		Recipient: maxigo.Recipient{ChatID: &chatID, ChatType: cs.chat.Type},
+		Timestamp: s.now(),
+		Link:      link,
+		Body:      body,
+	}
+	if cs.chat.Type == maxigo.ChatDialog {
+		userID := s.bot.UserID
+		if sender.UserID == s.bot.UserID {
+			userID = s.dialogUser(cs)
+		}
+		msg.Recipient.UserID = &userID
+	}
+
+	s.messages[body.MID] = msg
+	cs.mids = append(cs.mids, body.MID)
+	cs.chat.LastEventTime = msg.Timestamp
+	return msg
+}
+
+// decodeMessageBody decodes a new message body, turning inline keyboard
+// payloads into [maxigo.Keyboard] so the body can be checked and stored.
+func decodeMessageBody(body []byte, req *maxigo.NewMessageBody) *apiError {
+	if err := decode(body, req); err != nil {
+		return err
+	}
+	return typeKeyboards(req)
+}
+
+// typeKeyboards replaces the generic inline keyboard payloads of a decoded
+// message body with [maxigo.Keyboard] values.
+func typeKeyboards(req *maxigo.NewMessageBody) *apiError {
+	for i, att := range req.Attachments {
+		if att.Type != "inline_keyboard" || att.Payload == nil {
+			continue
+		}
+		raw, _ := json.Marshal(att.Payload)
+		var kb maxigo.Keyboard
+		if err := json.Unmarshal(raw, &kb); err != nil {
+			return badRequest("Invalid inline keyboard: %v", err)
+		}
+		req.Attachments[i].Payload = kb
+	}
+	return nil
+}
+
+// newBody converts a request body to the body of a stored message.
+func newBody(body *maxigo.NewMessageBody) (maxigo.MessageBody, *apiError) {
+	var mb maxigo.MessageBody
+	if body.Text.Set {
+		mb.Text = strPtr(body.Text.Value)
+	}
+	for _, att := range body.Attachments {
+		raw, err := json.Marshal(att)
+		if err != nil {
+			return mb, badRequest("Invalid attachment: %v", err)
+		}
+		mb.Attachments = append(mb.Attachments, raw)
+	}
+	return mb, nil
+}
+
+// newLink resolves the link of a new message.
+func (s *Server) newLink(link *maxigo.NewMessageLink) (*maxigo.LinkedMessage, *apiError) {
+	if link == nil {
+		return nil, nil
+	}
+	linked, ok := s.messages[link.MID]
+	if !ok {
+		return nil, notFound("Message %s not found", link.MID)
+	}
+	if link.Type != maxigo.LinkForward && link.Type != maxigo.LinkReply {
+		return nil, badRequest("Unknown link type %q", link.Type)
+	}
+	return &maxigo.LinkedMessage{
+		Type:    link.Type,
+		Sender:  linked.Sender,
+		ChatID:  *linked.Recipient.ChatID,
+		Message: linked.Body,
+	}, nil
+}
+
+// recipients resolves the chat_id, user_id or phone_numbers parameter of
+// POST /messages.
+func (s *Server) recipients(r *http.Request) ([]*chatState, *apiError) {
+	q := r.URL.Query()
+	set := 0
+	for _, name := range []string{"chat_id", "user_id", "phone_numbers"} {
+		if q.Get(name) != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, badRequest("Exactly one of chat_id, user_id or phone_numbers is required")
+	}
+
+	switch {
+	case q.Get("chat_id") != "":
+		id, err := strconv.ParseInt(q.Get("chat_id"), 10, 64)
+		if err != nil {
+			return nil, badRequest("Invalid chat_id %q", q.Get("chat_id"))
+		}
+		cs, ok := s.chats[id]
+		if !ok {
+			return nil, notFound("Chat %d not found", id)
+		}
+		if cs.chat.Type == maxigo.ChatChannel {
+			return []*chatState{cs}, s.requireAdmin(cs, maxigo.PermWrite)
+		}
+		return []*chatState{cs}, s.requireMember(cs)
+
+	case q.Get("user_id") != "":
+		id, err := strconv.ParseInt(q.Get("user_id"), 10, 64)
+		if err != nil {
+			return nil, badRequest("Invalid user_id %q", q.Get("user_id"))
+		}
+		if _, ok := s.users[id]; !ok || id == s.bot.UserID {
+			return nil, notFound("User %d not found", id)
+		}
+		return []*chatState{s.dialog(id)}, nil
+
+	default:
+		phones := strings.Split(q.Get("phone_numbers"), ",")
+		if len(phones) > maxigo.MaxPhonesPerRequest {
+			return nil, badRequest("Too many phone_numbers: %d, limit is %d", len(phones), maxigo.MaxPhonesPerRequest)
+		}
+		var chats []*chatState
+		for _, phone := range phones {
+			if id, ok := s.phones[phone]; ok {
+				chats = append(chats, s.dialog(id))
+			}
+		}
+		if len(chats) == 0 {
+			return nil, notFound("No users with phone_numbers %s", q.Get("phone_numbers"))
+		}
+		return chats, nil
+	}
+}
+
+// sendMessage serves POST /messages.
+func (s *Server) sendMessage(r *http.Request, body []byte) (any, *apiError) {
+	var req maxigo.NewMessageBody
+	if err := decodeMessageBody(body, &req); err != nil {
+		return nil, err
+	}
+	if err := checkMessageBody(&req, "", true); err != nil {
+		return nil, err
+	}
+	mb, err := newBody(&req)
+	if err != nil {
+		return nil, err
+	}
+	link, err := s.newLink(req.Link)
+	if err != nil {
+		return nil, err
+	}
+	chats, err := s.recipients(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var first *maxigo.Message
+	for _, cs := range chats {
+		msg := s.addMessage(cs, s.bot.User, mb, link)
+		s.sent = append(s.sent, *msg)
+		if first == nil {
+			first = msg
+		}
+	}
+	return struct {
+		Message maxigo.Message `json:"message"`
+	}{*first}, nil
+}
+
+// messageParam returns the message in the message_id query parameter.
+func (s *Server) messageParam(r *http.Request) (*maxigo.Message, *apiError) {
+	mid := r.URL.Query().Get("message_id")
+	if mid == "" {
+		return nil, badRequest("message_id is required")
+	}
+	msg, ok := s.messages[mid]
+	if !ok {
+		return nil, notFound("Message %s not found", mid)
+	}
+	return msg, nil
+}
+
+// editMessage serves PUT /messages.
+func (s *Server) editMessage(r *http.Request, body []byte) (any, *apiError) {
+	msg, err := s.messageParam(r)
+	if err != nil {
+		return nil, err
+	}
+	var req maxigo.NewMessageBody
+	if err := decodeMessageBody(body, &req); err != nil {
+		return nil, err
+	}
+	return maxigo.SimpleQueryResult{Success: true}, s.edit(msg, &req, "")
+}
+
+// edit applies an edit by the bot to msg. prefix is prepended to the
+// field paths of check errors.
+func (s *Server) edit(msg *maxigo.Message, req *maxigo.NewMessageBody, prefix string) *apiError {
+	if req.Text.Set || req.Attachments != nil {
+		if err := checkMessageBody(req, prefix, false); err != nil {
+			return err
+		}
+	}
+	if msg.Sender == nil || msg.Sender.UserID != s.bot.UserID {
+		return forbidden("Bot can edit only its own messages")
+	}
+	mb, err := newBody(req)
+	if err != nil {
+		return err
+	}
+	if req.Text.Set {
+		msg.Body.Text = mb.Text
+	}
+	if req.Attachments != nil {
+		msg.Body.Attachments = mb.Attachments
+	}
+	return nil
+}
+
+// deleteMessage serves DELETE /messages.
+func (s *Server) deleteMessage(r *http.Request, _ []byte) (any, *apiError) {
+	msg, err := s.messageParam(r)
+	if err != nil {
+		return nil, err
+	}
+	cs := s.chats[*msg.Recipient.ChatID]
+	if msg.Sender == nil || msg.Sender.UserID != s.bot.UserID {
+		if cs.chat.Type == maxigo.ChatDialog {
+			return nil, forbidden("Bot can't delete user messages in dialogs")
+		}
+		if err := s.requireAdmin(cs, maxigo.PermDeleteMessage); err != nil {
+			return nil, err
+		}
+	}
+
+	mid := msg.Body.MID
+	delete(s.messages, mid)
+	cs.mids = slices.DeleteFunc(cs.mids, func(m string) bool { return m == mid })
+	if cs.pinned == mid {
+		cs.pinned = ""
+	}
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// getMessages serves GET /messages.
+func (s *Server) getMessages(r *http.Request, _ []byte) (any, *apiError) {
+	q := r.URL.Query()
+	count, err := intParam(r, "count", 50, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := []maxigo.Message{}
+	if raw := q.Get("message_ids"); raw != "" {
+		for _, mid := range strings.Split(raw, ",") {
+			if msg, ok := s.messages[mid]; ok {
+				msgs = append(msgs, *msg)
+			}
+		}
+		return maxigo.MessageList{Messages: msgs}, nil
+	}
+
+	if q.Get("chat_id") == "" {
+		return nil, badRequest("chat_id or message_ids is required")
+	}
+	id, perr := strconv.ParseInt(q.Get("chat_id"), 10, 64)
+	if perr != nil {
+		return nil, badRequest("Invalid chat_id %q", q.Get("chat_id"))
+	}
+	cs, ok := s.chats[id]
+	if !ok {
+		return nil, notFound("Chat %d not found", id)
+	}
+	if err := s.requireMember(cs); err != nil {
+		return nil, err
+	}
+	from, err := intParam(r, "from", 0, 0, 1<<62)
+	if err != nil {
+		return nil, err
+	}
+	to, err := intParam(r, "to", 0, 0, 1<<62)
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first: from is the upper and to the lower bound.
+	for _, mid := range slices.Backward(cs.mids) {
+		msg := s.messages[mid]
+		if (from != 0 && msg.Timestamp > from) || (to != 0 && msg.Timestamp < to) {
+			continue
+		}
+		msgs = append(msgs, *msg)
+		if len(msgs) == int(count) {
+			break
+		}
+	}
+	return maxigo.MessageList{Messages: msgs}, nil
+}
+
+// getMessage serves GET /messages/{mid}.
+func (s *Server) getMessage(r *http.Request, _ []byte) (any, *apiError) {
+	msg, ok := s.messages[r.PathValue("mid")]
+	if !ok {
+		return nil, notFound("Message %s not found", r.PathValue("mid"))
+	}
+	return *msg, nil
+}
+
+// answerCallback serves POST /answers.
+func (s *Server) answerCallback(r *http.Request, body []byte) (any, *apiError) {
+	id := r.URL.Query().Get("callback_id")
+	if id == "" {
+		return nil, badRequest("callback_id is required")
+	}
+	mid, ok := s.callbacks[id]
+	if !ok {
+		return nil, notFound("Callback %s not found", id)
+	}
+	var answer maxigo.CallbackAnswer
+	if err := decode(body, &answer); err != nil {
+		return nil, err
+	}
+	if answer.Message != nil {
+		if err := typeKeyboards(answer.Message); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkAnswer(&answer); err != nil {
+		return nil, err
+	}
+	if msg, ok := s.messages[mid]; ok && answer.Message != nil {
+		if err := s.edit(msg, answer.Message, "message."); err != nil {
+			return nil, err
+		}
+	}
+	s.answers = append(s.answers, Answer{CallbackID: id, Answer: answer})
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// checkPhones serves GET /notify/exists.
+func (s *Server) checkPhones(r *http.Request, _ []byte) (any, *apiError) {
+	raw := r.URL.Query().Get("phone_numbers")
+	if raw == "" {
+		return nil, badRequest("phone_numbers is required")
+	}
+	phones := strings.Split(raw, ",")
+	if len(phones) > maxigo.MaxPhonesPerRequest {
+		return nil, badRequest("Too many phone_numbers: %d, limit is %d", len(phones), maxigo.MaxPhonesPerRequest)
+	}
+	existing := []string{}
+	for _, phone := range phones {
+		if _, ok := s.phones[phone]; ok && !slices.Contains(existing, phone) {
+			existing = append(existing, phone)
+		}
+	}
+	return struct {
+		ExistingPhoneNumbers []string `json:"existing_phone_numbers"`
+	}{existing}, nil
+}
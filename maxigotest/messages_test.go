@@ -0,0 +1,212 @@
+package maxigotest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+func TestServerMessages(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice := srv.AddUser("Alice")
+	team := srv.AddChat("Team", alice.UserID)
+
+	first, err := c.SendMessage(ctx, team.ChatID, &maxigo.NewMessageBody{Text: maxigo.Some("one")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.SendMessage(ctx, team.ChatID, &maxigo.NewMessageBody{
+		Text: maxigo.Some("two"),
+		Link: &maxigo.NewMessageLink{Type: maxigo.LinkReply, MID: first.Body.MID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Link == nil || *second.Link.Message.Text != "one" || second.Sender.UserID != srv.Bot().UserID {
+		t.Errorf("reply = %+v", second)
+	}
+
+	list, err := c.GetMessages(ctx, maxigo.GetMessagesOpts{ChatID: team.ChatID})
+	if err != nil || len(list.Messages) != 2 || list.Messages[0].Body.MID != second.Body.MID {
+		t.Fatalf("GetMessages = %+v, %v; want newest first", list, err)
+	}
+	list, err = c.GetMessages(ctx, maxigo.GetMessagesOpts{ChatID: team.ChatID, From: first.Timestamp})
+	if err != nil || len(list.Messages) != 1 || list.Messages[0].Body.MID != first.Body.MID {
+		t.Errorf("GetMessages from = %+v, %v", list, err)
+	}
+
+	if _, err := c.EditMessage(ctx, first.Body.MID, &maxigo.NewMessageBody{Text: maxigo.Some("one, edited")}); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := c.GetMessageByID(ctx, first.Body.MID); err != nil || *msg.Body.Text != "one, edited" {
+		t.Errorf("GetMessageByID = %+v, %v", msg, err)
+	}
+	if sent := srv.Sent(); len(sent) != 2 || *sent[0].Body.Text != "one" {
+		t.Errorf("sent = %+v, want original text", sent)
+	}
+
+	if _, err := c.DeleteMessage(ctx, second.Body.MID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.DeleteMessage(ctx, second.Body.MID)
+	wantAPIError(t, err, http.StatusNotFound)
+	_, err = c.EditMessage(ctx, second.Body.MID, &maxigo.NewMessageBody{Text: maxigo.Some("x")})
+	wantAPIError(t, err, http.StatusNotFound)
+	if msgs := srv.Messages(team.ChatID); len(msgs) != 1 || *msgs[0].Body.Text != "one, edited" {
+		t.Errorf("messages = %+v", msgs)
+	}
+}
+
+func TestServerSendErrors(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice := srv.AddUser("Alice")
+	team := srv.AddChat("Team", alice.UserID)
+	text := &maxigo.NewMessageBody{Text: maxigo.Some("hi")}
+
+	tests := []struct {
+		name   string
+		send   func() error
+		status int
+	}{
+		{"unknown chat", func() error { _, err := c.SendMessage(ctx, 42, text); return err }, http.StatusNotFound},
+		{"unknown user", func() error { _, err := c.SendMessageToUser(ctx, 42, text); return err }, http.StatusNotFound},
+		{"no target", func() error { _, err := c.SendMessage(ctx, 0, text); return err }, http.StatusBadRequest},
+		{"empty body", func() error { _, err := c.SendMessage(ctx, team.ChatID, &maxigo.NewMessageBody{}); return err }, http.StatusBadRequest},
+		{"too long", func() error {
+			_, err := c.SendMessage(ctx, team.ChatID, &maxigo.NewMessageBody{Text: maxigo.Some(strings.Repeat("a", maxigo.MaxTextLength+1))})
+			return err
+		}, http.StatusBadRequest},
+		{"empty keyboard", func() error {
+			_, err := c.SendMessage(ctx, team.ChatID, &maxigo.NewMessageBody{Attachments: []maxigo.AttachmentRequest{maxigo.NewInlineKeyboardAttachment(nil)}})
+			return err
+		}, http.StatusBadRequest},
+		{"callback without payload", func() error {
+			_, err := c.SendMessage(ctx, team.ChatID, &maxigo.NewMessageBody{Attachments: []maxigo.AttachmentRequest{
+				maxigo.NewInlineKeyboardAttachment([][]maxigo.Button{{{Type: "callback", Text: "Go"}}}),
+			}})
+			return err
+		}, http.StatusBadRequest},
+		{"unknown phones", func() error { _, err := c.SendMessageToPhones(ctx, []string{"79990000000"}, text); return err }, http.StatusNotFound},
+		{"left chat", func() error {
+			if _, err := c.LeaveChat(ctx, team.ChatID); err != nil {
+				return err
+			}
+			_, err := c.SendMessage(ctx, team.ChatID, text)
+			return err
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantAPIError(t, tt.send(), tt.status)
+		})
+	}
+	if sent := srv.Sent(); len(sent) != 0 {
+		t.Errorf("sent = %+v, want nothing", sent)
+	}
+}
+
+func TestServerForward(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice := srv.AddUser("Alice")
+	team := srv.AddChat("Team", alice.UserID)
+
+	msg, err := c.SendMessageToUser(ctx, alice.UserID, &maxigo.NewMessageBody{Text: maxigo.Some("news")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fwd, err := c.Forward(ctx, msg, team.ChatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fwd.Link == nil || fwd.Link.Type != maxigo.LinkForward || *fwd.Link.Message.Text != "news" {
+		t.Errorf("link = %+v, want forward of %q", fwd.Link, "news")
+	}
+}
+
+func TestServerDialogsAndPhones(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice, bob := srv.AddUser("Alice"), srv.AddUser("Bob")
+	srv.SetPhone(alice.UserID, "79001234567")
+	srv.SetPhone(bob.UserID, "79007654321")
+
+	msg, err := c.SendMessageToUser(ctx, alice.UserID, &maxigo.NewMessageBody{Text: maxigo.Some("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Recipient.ChatType != maxigo.ChatDialog || *msg.Recipient.UserID != alice.UserID {
+		t.Errorf("recipient = %+v", msg.Recipient)
+	}
+
+	existing, err := c.CheckPhoneNumbers(ctx, []string{"79001234567", "79990000000"})
+	if err != nil || len(existing) != 1 || existing[0] != "79001234567" {
+		t.Errorf("CheckPhoneNumbers = %v, %v", existing, err)
+	}
+	if _, err := c.SendMessageToPhones(ctx, []string{"79001234567", "79007654321"}, &maxigo.NewMessageBody{Text: maxigo.Some("news")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice's dialog is reused.
+	if msgs := srv.Messages(*msg.Recipient.ChatID); len(msgs) != 2 {
+		t.Errorf("dialog messages = %d, want 2", len(msgs))
+	}
+	if sent := srv.Sent(); len(sent) != 3 {
+		t.Errorf("sent = %d messages, want 3", len(sent))
+	}
+}
+
+func TestServerAnswerCallback(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice := srv.AddUser("Alice")
+
+	msg, err := c.SendMessageToUser(ctx, alice.UserID, &maxigo.NewMessageBody{Text: maxigo.Some("pick")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.PushUpdate(maxigo.MessageCallbackUpdate{
+		Update:   maxigo.Update{UpdateType: maxigo.UpdateMessageCallback},
+		Callback: maxigo.Callback{CallbackID: "cb.1", Payload: "yes", User: alice},
+		Message:  msg,
+	})
+
+	if _, err := c.AnswerCallback(ctx, "cb.1", &maxigo.CallbackAnswer{
+		Message:      &maxigo.NewMessageBody{Text: maxigo.Some("picked yes")},
+		Notification: maxigo.Some("Done"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := srv.Message(msg.Body.MID); *got.Body.Text != "picked yes" {
+		t.Errorf("text = %q, want edited by answer", *got.Body.Text)
+	}
+	if answers := srv.Answers(); len(answers) != 1 || answers[0].Answer.Notification.Value != "Done" {
+		t.Errorf("answers = %+v", answers)
+	}
+
+	rows := [][]maxigo.Button{{maxigo.NewCallbackButton("Again", "again")}}
+	if _, err := c.AnswerCallback(ctx, "cb.1", &maxigo.CallbackAnswer{
+		Message: &maxigo.NewMessageBody{Attachments: []maxigo.AttachmentRequest{maxigo.NewInlineKeyboardAttachment(rows)}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	answers := srv.Answers()
+	if kb, ok := answers[len(answers)-1].Answer.Message.Attachments[0].Payload.(maxigo.Keyboard); !ok || !reflect.DeepEqual(kb.Buttons, rows) {
+		t.Errorf("answer keyboard = %#v, want %v", answers[len(answers)-1].Answer.Message.Attachments[0].Payload, rows)
+	}
+
+	_, err = c.AnswerCallback(ctx, "cb.404", &maxigo.CallbackAnswer{Notification: maxigo.Some("x")})
+	wantAPIError(t, err, http.StatusNotFound)
+	_, err = c.AnswerCallback(ctx, "cb.1", &maxigo.CallbackAnswer{})
+	wantAPIError(t, err, http.StatusBadRequest)
+	_, err = c.AnswerCallback(ctx, "cb.1", &maxigo.CallbackAnswer{
+		Message: &maxigo.NewMessageBody{Attachments: []maxigo.AttachmentRequest{maxigo.NewInlineKeyboardAttachment(nil)}},
+	})
+	wantAPIError(t, err, http.StatusBadRequest)
+}
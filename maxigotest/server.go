@@ -0,0 +1,484 @@
+// Package maxigotest provides an in-memory fake of the Max Bot API for
+// integration tests of code built on maxigo.
+//
+// The [Server] implements every endpoint the client covers with consistent
+// state: messages sent to a chat can be listed, edited and pinned, members
+// added to a chat show up in its member list, and so on. Requests that the
+// real API rejects, such as messages to unknown chats or admin calls without
+// admin rights, fail with the same HTTP status and error body format:
+//
+//	srv := maxigotest.NewServer()
+//	defer srv.Close()
+//
+//	alice := srv.AddUser("Alice")
+//	chat := srv.AddChat("Team", alice.UserID)
+//
+//	client := srv.Client()
+//	_, err := client.SendMessage(ctx, chat.ChatID, &maxigo.NewMessageBody{Text: maxigo.Some("hi")})
+//
+//	sent := srv.Sent() // everything the bot sent
+//
+// To test a bot end to end, act as a user with [Server.As]: the user's
+// messages, button presses and chat changes reach the bot as updates via
+// GET /updates or its webhook.
+//
+// All methods are safe for concurrent use.
+package maxigotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// DefaultToken is the access token a [Server] accepts unless changed with
+// [WithToken].
+const DefaultToken = "maxigotest-token"
+
+// Request is an API request received by a [Server].
+type Request struct {
+	// Method is the HTTP method, e.g. "POST".
+	Method string
+	// Path is the URL path, e.g. "/messages".
+	Path string
+	// Query holds the query parameters.
+	Query url.Values
+	// Body is the raw request body.
+	Body []byte
+}
+
+// Op returns the request as "METHOD /path", the form used by [Server.FailNext].
+func (r Request) Op() string {
+	return r.Method + " " + r.Path
+}
+
+// ServerOption configures a [Server].
+type ServerOption func(*Server)
+
+// WithToken sets the access token the server accepts.
+func WithToken(token string) ServerOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithBot sets the bot's profile returned by GET /me. The bot keeps its
+// user ID if bot.UserID is 0.
+func WithBot(bot maxigo.BotInfo) ServerOption {
+	return func(s *Server) {
+		if bot.UserID == 0 {
+			bot.UserID = s.bot.UserID
+		}
+		bot.IsBot = true
+		s.bot = bot
+	}
+}
+
+// Server is a fake Max Bot API server backed by in-memory state.
+// Create one with [NewServer] and point a client at it with [Server.Client]
+// or [maxigo.WithBaseURL] and [Server.URL].
+type Server struct {
+	// URL is the base URL of the server.
+	URL string
+
+	ts    *httptest.Server
+	hooks *http.Client
+	token string
+	done  chan struct{}
+	close sync.Once
+
+	mu        sync.Mutex
+	changed   chan struct{} // closed and replaced after every API request
+	lastTime  int64
+	nextID    int64
+	nextMID   int64
+	bot       maxigo.BotInfo
+	users     map[int64]*maxigo.User
+	phones    map[string]int64
+	chats     map[int64]*chatState
+	chatOrder []int64
+	dialogs   map[int64]int64 // user ID -> dialog chat ID
+	messages  map[string]*maxigo.Message
+	sent      []maxigo.Message
+	callbacks map[string]string // callback ID -> message ID
+	answers   []Answer
+	upd       updateQueue
+	subs      []subscription
+	endpoints map[string]uploadEndpoint
+	uploads   []Upload
+	videos    map[string]Upload
+	requests  []Request
+	failures  []failure
+}
+
+// NewServer starts a fake API server. The bot's user ID is 1; users and
+// chats created with [Server.AddUser] and [Server.AddChat] get IDs from 100
+// up. Call [Server.Close] when done.
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{
+		hooks:     &http.Client{Timeout: 10 * time.Second},
+		token:     DefaultToken,
+		done:      make(chan struct{}),
+		nextID:    100,
+		users:     make(map[int64]*maxigo.User),
+		phones:    make(map[string]int64),
+		chats:     make(map[int64]*chatState),
+		dialogs:   make(map[int64]int64),
+		messages:  make(map[string]*maxigo.Message),
+		callbacks: make(map[string]string),
+		endpoints: make(map[string]uploadEndpoint),
+		videos:    make(map[string]Upload),
+	}
+	s.bot = maxigo.BotInfo{UserWithPhoto: maxigo.UserWithPhoto{User: maxigo.User{
+		UserID:    1,
+		FirstName: "Test Bot",
+		Username:  strPtr("maxigotest_bot"),
+		IsBot:     true,
+	}}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.users[s.bot.UserID] = &s.bot.User
+	s.upd.signal = make(chan struct{})
+	s.changed = make(chan struct{})
+
+	s.ts = httptest.NewServer(s.routes())
+	s.URL = s.ts.URL
+	return s
+}
+
+// Close stops the server, ending pending long-polling requests. It is safe
+// to call Close more than once.
+func (s *Server) Close() {
+	s.close.Do(func() {
+		close(s.done)
+		s.ts.Close()
+	})
+}
+
+// Client returns a client for the server with the server's token.
+// opts are applied after [maxigo.WithBaseURL].
+func (s *Server) Client(opts ...maxigo.Option) *maxigo.Client {
+	c, err := maxigo.New(s.token, append([]maxigo.Option{maxigo.WithBaseURL(s.URL)}, opts...)...)
+	if err != nil {
+		panic(fmt.Sprintf("maxigotest: %v", err))
+	}
+	return c
+}
+
+// apiError is an error response in the format of the Max API.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func errorf(status int, code, format string, args ...any) *apiError {
+	return &apiError{status: status, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func badRequest(format string, args ...any) *apiError {
+	return errorf(http.StatusBadRequest, "proto.payload", format, args...)
+}
+
+func notFound(format string, args ...any) *apiError {
+	return errorf(http.StatusNotFound, "not.found", format, args...)
+}
+
+func forbidden(format string, args ...any) *apiError {
+	return errorf(http.StatusForbidden, "access.denied", format, args...)
+}
+
+// failure is an error injected with [Server.FailNext].
+type failure struct {
+	op  string
+	err *apiError
+}
+
+// FailNext makes the next request matching op fail with the given status
+// and error code, before it changes any state. op is "METHOD /path", e.g.
+// "POST /messages", or "" for any request. Injected failures are consumed
+// in the order they were added:
+//
+//	srv.FailNext("POST /messages", http.StatusTooManyRequests, "too.many.requests", "Too many requests")
+func (s *Server) FailNext(op string, status int, code, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{op: op, err: &apiError{status: status, code: code, message: message}})
+}
+
+// handler handles an API request with s.mu held. body is the request body.
+// It returns the response to encode as JSON, or an error response.
+type handler func(r *http.Request, body []byte) (any, *apiError)
+
+// routes returns the server's request router.
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	for pattern, h := range map[string]handler{
+		"GET /me":   s.getBot,
+		"PATCH /me": s.editBot,
+
+		"GET /chats":                                 s.getChats,
+		"GET /chats/{chat}":                          s.getChat,
+		"PATCH /chats/{chat}":                        s.editChat,
+		"DELETE /chats/{chat}":                       s.deleteChat,
+		"GET /chats/{chat}/members":                  s.getMembers,
+		"POST /chats/{chat}/members":                 s.addMembers,
+		"DELETE /chats/{chat}/members":               s.removeMember,
+		"GET /chats/{chat}/members/admins":           s.getAdmins,
+		"POST /chats/{chat}/members/admins":          s.setAdmins,
+		"DELETE /chats/{chat}/members/admins/{user}": s.removeAdmin,
+		"GET /chats/{chat}/members/me":               s.getMembership,
+		"DELETE /chats/{chat}/members/me":            s.leaveChat,
+		"POST /chats/{chat}/actions":                 s.sendAction,
+		"GET /chats/{chat}/pin":                      s.getPinned,
+		"PUT /chats/{chat}/pin":                      s.pinMessage,
+		"DELETE /chats/{chat}/pin":                   s.unpinMessage,
+
+		"GET /messages":         s.getMessages,
+		"POST /messages":        s.sendMessage,
+		"PUT /messages":         s.editMessage,
+		"DELETE /messages":      s.deleteMessage,
+		"GET /messages/{mid}":   s.getMessage,
+		"POST /answers":         s.answerCallback,
+		"GET /notify/exists":    s.checkPhones,
+		"GET /subscriptions":    s.getSubscriptions,
+		"POST /subscriptions":   s.subscribe,
+		"DELETE /subscriptions": s.unsubscribe,
+		"POST /uploads":         s.getUploadURL,
+		"GET /videos/{token}":   s.getVideo,
+	} {
+		mux.Handle(pattern, s.api(h))
+	}
+	mux.Handle("GET /updates", s.api(nil))
+	mux.HandleFunc("POST /upload/{id}", s.upload)
+	mux.Handle("/", s.api(func(r *http.Request, _ []byte) (any, *apiError) {
+		return nil, notFound("Path %s %s not found", r.Method, r.URL.Path)
+	}))
+	return mux
+}
+
+// api wraps h with request recording, authorization and failure injection.
+// A nil h serves GET /updates, which must not hold s.mu while waiting.
+func (s *Server) api(h handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, badRequest("read body: %v", err))
+			return
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Body:   body,
+		})
+		if r.Header.Get("Authorization") != s.token {
+			s.mu.Unlock()
+			writeError(w, errorf(http.StatusUnauthorized, "verify.token", "Invalid access_token"))
+			return
+		}
+		if ferr := s.takeFailure(r); ferr != nil {
+			s.mu.Unlock()
+			writeError(w, ferr)
+			return
+		}
+		if h == nil {
+			s.mu.Unlock()
+			s.getUpdates(w, r)
+			return
+		}
+		result, aerr := h(r, body)
+		s.notify()
+		s.mu.Unlock()
+
+		if aerr != nil {
+			writeError(w, aerr)
+			return
+		}
+		writeJSON(w, result)
+	})
+}
+
+// notify wakes up [Server.Wait] calls. It is called with s.mu held.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Wait blocks until cond reports true or ctx is done. cond is checked
+// now and after every API request the server handles; it may call other
+// methods of s. Use it to wait for a bot that polls for updates:
+//
+//	err := srv.Wait(ctx, func() bool { return len(srv.Messages(chatID)) > 1 })
+func (s *Server) Wait(ctx context.Context, cond func() bool) error {
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+		if cond() {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// WaitSent waits until the bot has sent at least n messages and returns
+// all sent messages, like [Server.Sent].
+func (s *Server) WaitSent(ctx context.Context, n int) ([]maxigo.Message, error) {
+	var sent []maxigo.Message
+	err := s.Wait(ctx, func() bool {
+		sent = s.Sent()
+		return len(sent) >= n
+	})
+	return sent, err
+}
+
+// takeFailure removes and returns the first injected failure matching r.
+func (s *Server) takeFailure(r *http.Request) *apiError {
+	op := r.Method + " " + r.URL.Path
+	i := slices.IndexFunc(s.failures, func(f failure) bool { return f.op == "" || f.op == op })
+	if i < 0 {
+		return nil
+	}
+	err := s.failures[i].err
+	s.failures = slices.Delete(s.failures, i, i+1)
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, errorf(http.StatusInternalServerError, "internal", "encode response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{e.code, e.message})
+}
+
+// decode unmarshals a JSON request body.
+func decode(body []byte, v any) *apiError {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(v); err != nil {
+		return badRequest("Invalid request body: %v", err)
+	}
+	return nil
+}
+
+// now returns the current time in Unix milliseconds. Successive calls
+// return increasing values, so events keep their order.
+func (s *Server) now() int64 {
+	s.lastTime = max(time.Now().UnixMilli(), s.lastTime+1)
+	return s.lastTime
+}
+
+func (s *Server) newID() int64 {
+	s.nextID++
+	return s.nextID - 1
+}
+
+func strPtr(v string) *string { return &v }
+
+// Bot returns the bot's profile.
+func (s *Server) Bot() maxigo.BotInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cloneBot(s.bot)
+}
+
+func cloneBot(b maxigo.BotInfo) maxigo.BotInfo {
+	b.Commands = slices.Clone(b.Commands)
+	return b
+}
+
+// AddUser creates a user with a new ID.
+func (s *Server) AddUser(firstName string) maxigo.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := &maxigo.User{UserID: s.newID(), FirstName: firstName, LastActivityTime: s.now()}
+	s.users[u.UserID] = u
+	return *u
+}
+
+// SetPhone registers a phone number of a user, in international format
+// without "+", for GET /notify/exists and POST /messages with phone numbers.
+func (s *Server) SetPhone(userID int64, phone string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phones[phone] = userID
+}
+
+// Requests returns all API requests received so far, including rejected
+// ones. Uploads to upload URLs are reported by [Server.Uploads].
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Sent returns every message the bot sent, in order, as it was when sent.
+// Later edits and deletions are reflected by [Server.Messages].
+func (s *Server) Sent() []maxigo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.sent)
+}
+
+// getBot serves GET /me.
+func (s *Server) getBot(*http.Request, []byte) (any, *apiError) {
+	return cloneBot(s.bot), nil
+}
+
+// editBot serves PATCH /me.
+func (s *Server) editBot(_ *http.Request, body []byte) (any, *apiError) {
+	var patch maxigo.BotPatch
+	if err := decode(body, &patch); err != nil {
+		return nil, err
+	}
+	if err := checkBotPatch(&patch); err != nil {
+		return nil, err
+	}
+	if patch.Name.Set {
+		s.bot.FirstName = patch.Name.Value
+	}
+	if patch.FirstName.Set {
+		s.bot.FirstName = patch.FirstName.Value
+	}
+	if patch.Description.Set {
+		s.bot.Description = strPtr(patch.Description.Value)
+	}
+	if patch.Commands != nil {
+		s.bot.Commands = slices.Clone(patch.Commands)
+	}
+	if patch.Photo != nil && patch.Photo.URL.Set {
+		s.bot.AvatarURL = patch.Photo.URL.Value
+		s.bot.FullAvatarURL = patch.Photo.URL.Value
+	}
+	s.users[s.bot.UserID] = &s.bot.User
+	return cloneBot(s.bot), nil
+}
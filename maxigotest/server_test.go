@@ -0,0 +1,97 @@
+package maxigotest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// newTestServer starts a server closed at the end of the test.
+func newTestServer(t *testing.T, opts ...ServerOption) (*Server, *maxigo.Client) {
+	t.Helper()
+	srv := NewServer(opts...)
+	t.Cleanup(srv.Close)
+	return srv, srv.Client()
+}
+
+// wantAPIError fails the test unless err is an API error with the status.
+func wantAPIError(t *testing.T, err error, status int) {
+	t.Helper()
+	var e *maxigo.Error
+	if !errors.As(err, &e) || e.Kind != maxigo.ErrAPI || e.StatusCode != status {
+		t.Errorf("err = %v, want API error %d", err, status)
+	}
+}
+
+func TestServerCloseTwice(t *testing.T) {
+	srv := NewServer()
+	srv.Close()
+	srv.Close()
+}
+
+func TestServerAuth(t *testing.T) {
+	srv, _ := newTestServer(t)
+	c, _ := maxigo.New("wrong", maxigo.WithBaseURL(srv.URL))
+
+	_, err := c.GetBot(context.Background())
+	wantAPIError(t, err, http.StatusUnauthorized)
+	if reqs := srv.Requests(); len(reqs) != 1 || reqs[0].Op() != "GET /me" {
+		t.Errorf("requests = %+v", reqs)
+	}
+}
+
+func TestServerBot(t *testing.T) {
+	srv, c := newTestServer(t, WithBot(maxigo.BotInfo{UserWithPhoto: maxigo.UserWithPhoto{User: maxigo.User{FirstName: "Helper"}}}))
+	ctx := context.Background()
+
+	bot, err := c.GetBot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bot.UserID != 1 || bot.FirstName != "Helper" || !bot.IsBot {
+		t.Errorf("bot = %+v", bot)
+	}
+
+	bot, err = c.EditBot(ctx, &maxigo.BotPatch{
+		Description: maxigo.Some("Helps"),
+		Commands:    []maxigo.BotCommand{{Name: "start"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.Bot(); got.Description == nil || *got.Description != "Helps" || len(got.Commands) != 1 {
+		t.Errorf("bot = %+v", got)
+	}
+
+	_, err = c.EditBot(ctx, &maxigo.BotPatch{})
+	wantAPIError(t, err, http.StatusBadRequest)
+}
+
+func TestServerFailNext(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	srv.FailNext("GET /me", http.StatusTooManyRequests, "too.many.requests", "Too many requests")
+
+	_, err := c.GetBot(ctx)
+	wantAPIError(t, err, http.StatusTooManyRequests)
+	if _, err := c.GetBot(ctx); err != nil {
+		t.Errorf("second call = %v, want success", err)
+	}
+}
+
+func TestServerUnknownPath(t *testing.T) {
+	srv, _ := newTestServer(t)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/nope", nil)
+	req.Header.Set("Authorization", DefaultToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,278 @@
+package maxigotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// updateQueue holds the updates served by GET /updates.
+type updateQueue struct {
+	updates   []json.RawMessage
+	delivered int           // updates before this index were returned
+	signal    chan struct{} // closed and replaced when an update is added
+}
+
+// subscription is a webhook subscription with its secret.
+type subscription struct {
+	maxigo.Subscription
+	secret string
+}
+
+// secretPattern is the format of webhook secrets.
+var secretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{5,256}$`)
+
+// SecretHeader is the header that carries the subscription secret in
+// webhook requests.
+const SecretHeader = "X-Max-Bot-Api-Secret"
+
+// PushUpdate delivers an update to the bot like [Server.As] does: to every
+// matching webhook subscription, or to GET /updates if there are none.
+// update is one of the maxigo update types, e.g.
+// [maxigo.MessageCreatedUpdate], or its raw JSON. A zero timestamp is set
+// to the current time. Callbacks of message_callback updates can then be
+// answered with POST /answers. It returns the first webhook error.
+//
+// PushUpdate panics if update cannot be encoded or has no update_type.
+func (s *Server) PushUpdate(update any) error {
+	s.mu.Lock()
+	send := s.deliver(update)
+	s.mu.Unlock()
+	return send()
+}
+
+// deliver queues an update or prepares its webhook requests. It is called
+// with s.mu held; the returned function sends the requests and must be
+// called after unlocking, as webhook handlers call the server back.
+func (s *Server) deliver(update any) func() error {
+	raw, typ := s.encodeUpdate(update)
+
+	var hooks []subscription
+	for _, sub := range s.subs {
+		if len(sub.UpdateTypes) == 0 || slices.Contains(sub.UpdateTypes, string(typ)) {
+			hooks = append(hooks, sub)
+		}
+	}
+	if len(s.subs) == 0 {
+		s.upd.updates = append(s.upd.updates, raw)
+		close(s.upd.signal)
+		s.upd.signal = make(chan struct{})
+	}
+	return func() error {
+		var first error
+		for _, sub := range hooks {
+			if err := s.postWebhook(sub, raw); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+}
+
+// encodeUpdate marshals an update, setting a zero timestamp and
+// registering callbacks.
+func (s *Server) encodeUpdate(update any) (json.RawMessage, maxigo.UpdateType) {
+	raw, ok := update.(json.RawMessage)
+	if b, isBytes := update.([]byte); isBytes {
+		raw, ok = b, true
+	}
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(update); err != nil {
+			panic(fmt.Sprintf("maxigotest: encode update: %v", err))
+		}
+	}
+
+	var fields map[string]json.RawMessage
+	var head maxigo.MessageCallbackUpdate
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		panic(fmt.Sprintf("maxigotest: decode update: %v", err))
+	}
+	if err := json.Unmarshal(raw, &head); err != nil || head.UpdateType == "" {
+		panic(fmt.Sprintf("maxigotest: update without update_type: %s", raw))
+	}
+	if head.Timestamp == 0 {
+		fields["timestamp"] = json.RawMessage(fmt.Sprint(s.now()))
+		raw, _ = json.Marshal(fields)
+	}
+	if head.UpdateType == maxigo.UpdateMessageCallback && head.Message != nil {
+		s.callbacks[head.Callback.CallbackID] = head.Message.Body.MID
+	}
+	return raw, head.UpdateType
+}
+
+// postWebhook sends an update to a webhook subscription.
+func (s *Server) postWebhook(sub subscription, update json.RawMessage) error {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(update))
+	if err != nil {
+		return fmt.Errorf("maxigotest: webhook %s: %w", sub.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sub.secret != "" {
+		req.Header.Set(SecretHeader, sub.secret)
+	}
+	resp, err := s.hooks.Do(req)
+	if err != nil {
+		return fmt.Errorf("maxigotest: webhook %s: %w", sub.URL, err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("maxigotest: webhook %s: status %d", sub.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// Subscriptions returns the active webhook subscriptions.
+func (s *Server) Subscriptions() []maxigo.Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]maxigo.Subscription, len(s.subs))
+	for i, sub := range s.subs {
+		subs[i] = sub.Subscription
+	}
+	return subs
+}
+
+// getUpdates serves GET /updates. Without a marker it returns updates not
+// returned before; with one, updates from that position. It waits up to
+// the timeout for updates to arrive.
+func (s *Server) getUpdates(w http.ResponseWriter, r *http.Request) {
+	limit, aerr := intParam(r, "limit", 100, 1, 1000)
+	if aerr == nil {
+		var timeout int64
+		if timeout, aerr = intParam(r, "timeout", 30, 0, 90); aerr == nil {
+			var marker int64
+			if marker, aerr = intParam(r, "marker", 0, 0, 1<<62); aerr == nil {
+				s.pollUpdates(w, r, int(limit), time.Duration(timeout)*time.Second, int(marker))
+				return
+			}
+		}
+	}
+	writeError(w, aerr)
+}
+
+func (s *Server) pollUpdates(w http.ResponseWriter, r *http.Request, limit int, timeout time.Duration, marker int) {
+	var types []string
+	if raw := r.URL.Query().Get("types"); raw != "" {
+		types = strings.Split(raw, ",")
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	s.mu.Lock()
+	pos := s.upd.delivered
+	if marker > 0 {
+		pos = min(marker, len(s.upd.updates))
+	}
+	for {
+		updates := []json.RawMessage{}
+		for ; pos < len(s.upd.updates) && len(updates) < limit; pos++ {
+			var u maxigo.Update
+			_ = json.Unmarshal(s.upd.updates[pos], &u)
+			if types == nil || slices.Contains(types, string(u.UpdateType)) {
+				updates = append(updates, s.upd.updates[pos])
+			}
+		}
+		if len(updates) > 0 || timeout == 0 {
+			s.upd.delivered = max(s.upd.delivered, pos)
+			next := int64(pos)
+			s.mu.Unlock()
+			writeJSON(w, maxigo.UpdateList{Updates: updates, Marker: &next})
+			return
+		}
+		signal := s.upd.signal
+		s.mu.Unlock()
+
+		select {
+		case <-signal:
+		case <-timer.C:
+			timeout = 0
+		case <-s.done:
+			timeout = 0
+		case <-r.Context().Done():
+			return
+		}
+		s.mu.Lock()
+	}
+}
+
+// getSubscriptions serves GET /subscriptions.
+func (s *Server) getSubscriptions(*http.Request, []byte) (any, *apiError) {
+	subs := make([]maxigo.Subscription, len(s.subs))
+	for i, sub := range s.subs {
+		subs[i] = sub.Subscription
+	}
+	return struct {
+		Subscriptions []maxigo.Subscription `json:"subscriptions"`
+	}{subs}, nil
+}
+
+// subscribe serves POST /subscriptions.
+func (s *Server) subscribe(_ *http.Request, body []byte) (any, *apiError) {
+	var req maxigo.SubscriptionRequestBody
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, badRequest("Invalid url %q", req.URL)
+	}
+	if req.Secret != "" && !secretPattern.MatchString(req.Secret) {
+		return nil, badRequest("secret must be 5 to 256 characters A-Z, a-z, 0-9, _ or -")
+	}
+	for _, t := range req.UpdateTypes {
+		if !knownUpdateTypes[maxigo.UpdateType(t)] {
+			return nil, badRequest("Unknown update type %q", t)
+		}
+	}
+
+	sub := subscription{
+		Subscription: maxigo.Subscription{URL: req.URL, Time: s.now(), UpdateTypes: slices.Clone(req.UpdateTypes)},
+		secret:       req.Secret,
+	}
+	if req.Version != "" {
+		sub.Version = strPtr(req.Version)
+	}
+	s.subs = slices.DeleteFunc(s.subs, func(old subscription) bool { return old.URL == req.URL })
+	s.subs = append(s.subs, sub)
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// unsubscribe serves DELETE /subscriptions.
+func (s *Server) unsubscribe(r *http.Request, _ []byte) (any, *apiError) {
+	target := r.URL.Query().Get("url")
+	if target == "" {
+		return nil, badRequest("url is required")
+	}
+	s.subs = slices.DeleteFunc(s.subs, func(sub subscription) bool { return sub.URL == target })
+	return maxigo.SimpleQueryResult{Success: true}, nil
+}
+
+// knownUpdateTypes lists all [maxigo.UpdateType] values.
+var knownUpdateTypes = map[maxigo.UpdateType]bool{
+	maxigo.UpdateMessageCreated:     true,
+	maxigo.UpdateMessageCallback:    true,
+	maxigo.UpdateMessageEdited:      true,
+	maxigo.UpdateMessageRemoved:     true,
+	maxigo.UpdateBotStarted:         true,
+	maxigo.UpdateBotStopped:         true,
+	maxigo.UpdateBotAdded:           true,
+	maxigo.UpdateBotRemoved:         true,
+	maxigo.UpdateUserAdded:          true,
+	maxigo.UpdateUserRemoved:        true,
+	maxigo.UpdateChatTitleChanged:   true,
+	maxigo.UpdateMessageChatCreated: true,
+	maxigo.UpdateDialogMuted:        true,
+	maxigo.UpdateDialogUnmuted:      true,
+	maxigo.UpdateDialogCleared:      true,
+	maxigo.UpdateDialogRemoved:      true,
+}
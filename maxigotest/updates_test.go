@@ -0,0 +1,95 @@
+package maxigotest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+func TestServerUpdates(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice := srv.AddUser("Alice")
+
+	srv.PushUpdate(maxigo.BotStartedUpdate{
+		Update: maxigo.Update{UpdateType: maxigo.UpdateBotStarted},
+		ChatID: 5,
+		User:   alice,
+	})
+	srv.PushUpdate(json.RawMessage(`{"update_type":"dialog_muted","chat_id":5,"user":{"user_id":100}}`))
+
+	list, err := c.GetUpdates(ctx, maxigo.GetUpdatesOpts{Timeout: 1, Limit: 1})
+	if err != nil || len(list.Updates) != 1 {
+		t.Fatalf("GetUpdates = %+v, %v", list, err)
+	}
+	var started maxigo.BotStartedUpdate
+	if err := json.Unmarshal(list.Updates[0], &started); err != nil || started.User.UserID != alice.UserID || started.Timestamp == 0 {
+		t.Errorf("update = %+v, %v", started, err)
+	}
+
+	// The rest, then the same page again by marker.
+	rest, err := c.GetUpdates(ctx, maxigo.GetUpdatesOpts{Timeout: 1})
+	if err != nil || len(rest.Updates) != 1 || *rest.Marker != 2 {
+		t.Fatalf("GetUpdates = %+v, %v", rest, err)
+	}
+	again, err := c.GetUpdates(ctx, maxigo.GetUpdatesOpts{Timeout: 1, Marker: *list.Marker})
+	if err != nil || len(again.Updates) != 1 {
+		t.Errorf("GetUpdates by marker = %+v, %v", again, err)
+	}
+
+	filtered, err := c.GetUpdates(ctx, maxigo.GetUpdatesOpts{Timeout: 1, Marker: *list.Marker, Types: []string{"bot_started"}})
+	if err != nil || len(filtered.Updates) != 0 {
+		t.Errorf("GetUpdates filtered = %+v, %v", filtered, err)
+	}
+}
+
+func TestServerUpdatesLongPoll(t *testing.T) {
+	srv, c := newTestServer(t)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		srv.PushUpdate(maxigo.DialogClearedUpdate{Update: maxigo.Update{UpdateType: maxigo.UpdateDialogCleared}, ChatID: 5})
+	}()
+	start := time.Now()
+	list, err := c.GetUpdates(context.Background(), maxigo.GetUpdatesOpts{Timeout: 5})
+	if err != nil || len(list.Updates) != 1 {
+		t.Fatalf("GetUpdates = %+v, %v", list, err)
+	}
+	if d := time.Since(start); d > 3*time.Second {
+		t.Errorf("GetUpdates took %v, want to return on the update", d)
+	}
+}
+
+func TestServerSubscriptions(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+
+	if _, err := c.Subscribe(ctx, "https://bot.example.com/hook", []string{"message_created"}, "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	subs, err := c.GetSubscriptions(ctx)
+	if err != nil || len(subs) != 1 || subs[0].URL != "https://bot.example.com/hook" {
+		t.Fatalf("GetSubscriptions = %+v, %v", subs, err)
+	}
+
+	for _, tt := range []struct{ url, secret string }{
+		{"not a url", ""},
+		{"https://bot.example.com/hook", "bad secret!"},
+	} {
+		_, err := c.Subscribe(ctx, tt.url, nil, tt.secret)
+		wantAPIError(t, err, http.StatusBadRequest)
+	}
+	_, err = c.Subscribe(ctx, "https://bot.example.com/hook", []string{"message_sent"}, "")
+	wantAPIError(t, err, http.StatusBadRequest)
+
+	if _, err := c.Unsubscribe(ctx, "https://bot.example.com/hook"); err != nil {
+		t.Fatal(err)
+	}
+	if subs := srv.Subscriptions(); len(subs) != 0 {
+		t.Errorf("subscriptions = %+v", subs)
+	}
+}
@@ -0,0 +1,112 @@
+package maxigotest
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// maxUploadSize is the largest upload the server accepts (50 MB).
+const maxUploadSize = 50 << 20
+
+// Upload is a file uploaded to a [Server].
+type Upload struct {
+	// Type is the upload type requested with POST /uploads.
+	Type maxigo.UploadType
+	// Filename is the name of the uploaded file.
+	Filename string
+	// Data is the file content.
+	Data []byte
+	// Token is the token issued for the file.
+	Token string
+}
+
+// uploadEndpoint is an upload URL issued by POST /uploads.
+type uploadEndpoint struct {
+	typ   maxigo.UploadType
+	token string
+}
+
+// Uploads returns all files uploaded so far, in order.
+func (s *Server) Uploads() []Upload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.uploads)
+}
+
+// getUploadURL serves POST /uploads. Video and audio tokens are issued
+// with the URL, like the real API does.
+func (s *Server) getUploadURL(r *http.Request, _ []byte) (any, *apiError) {
+	typ := maxigo.UploadType(r.URL.Query().Get("type"))
+	switch typ {
+	case maxigo.UploadImage, maxigo.UploadVideo, maxigo.UploadAudio, maxigo.UploadFile:
+	default:
+		return nil, badRequest("Unknown upload type %q", typ)
+	}
+
+	id := strconv.FormatInt(s.newID(), 10)
+	ep := uploadEndpoint{typ: typ, token: string(typ) + "." + id}
+	s.endpoints[id] = ep
+	result := maxigo.UploadEndpoint{URL: s.URL + "/upload/" + id}
+	if typ == maxigo.UploadVideo || typ == maxigo.UploadAudio {
+		result.Token = strPtr(ep.token)
+	}
+	return result, nil
+}
+
+// upload serves POST /upload/{id}, an upload URL. Each URL accepts one
+// multipart upload with the file in the "data" field.
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	file, header, err := r.FormFile("data")
+	if err != nil {
+		writeError(w, badRequest("Invalid upload: %v", err))
+		return
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, badRequest("Invalid upload: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	id := r.PathValue("id")
+	ep, ok := s.endpoints[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, notFound("Upload URL %s not found", r.URL.Path))
+		return
+	}
+	delete(s.endpoints, id)
+	up := Upload{Type: ep.typ, Filename: header.Filename, Data: data, Token: ep.token}
+	s.uploads = append(s.uploads, up)
+	if ep.typ == maxigo.UploadVideo {
+		s.videos[up.Token] = up
+	}
+	s.notify()
+	s.mu.Unlock()
+
+	switch ep.typ {
+	case maxigo.UploadImage:
+		writeJSON(w, maxigo.PhotoTokens{Photos: map[string]maxigo.PhotoToken{id: {Token: up.Token}}})
+	case maxigo.UploadFile:
+		writeJSON(w, maxigo.UploadedInfo{Token: up.Token})
+	default:
+		// Video and audio tokens come with the upload URL; the upload
+		// server's response is empty.
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// getVideo serves GET /videos/{token}.
+func (s *Server) getVideo(r *http.Request, _ []byte) (any, *apiError) {
+	token := r.PathValue("token")
+	if _, ok := s.videos[token]; !ok {
+		return nil, notFound("Video %s not found", token)
+	}
+	return maxigo.VideoAttachmentDetails{Token: token}, nil
+}
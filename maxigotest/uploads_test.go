@@ -0,0 +1,42 @@
+package maxigotest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+func TestServerUploads(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+
+	photo, err := c.UploadPhoto(ctx, "cat.png", bytes.NewReader([]byte("png")))
+	if err != nil || len(photo.Photos) != 1 {
+		t.Fatalf("UploadPhoto = %+v, %v", photo, err)
+	}
+	file, err := c.UploadMedia(ctx, maxigo.UploadFile, "report.pdf", bytes.NewReader([]byte("pdf")))
+	if err != nil || file.Token == "" {
+		t.Fatalf("UploadMedia(file) = %+v, %v", file, err)
+	}
+	video, err := c.UploadMedia(ctx, maxigo.UploadVideo, "clip.mp4", bytes.NewReader([]byte("mp4")))
+	if err != nil || video.Token == "" {
+		t.Fatalf("UploadMedia(video) = %+v, %v", video, err)
+	}
+
+	uploads := srv.Uploads()
+	if len(uploads) != 3 || uploads[0].Filename != "cat.png" || string(uploads[2].Data) != "mp4" || uploads[1].Token != file.Token {
+		t.Errorf("uploads = %+v", uploads)
+	}
+
+	details, err := c.GetVideoDetails(ctx, video.Token)
+	if err != nil || details.Token != video.Token {
+		t.Errorf("GetVideoDetails = %+v, %v", details, err)
+	}
+	_, err = c.GetVideoDetails(ctx, file.Token)
+	wantAPIError(t, err, http.StatusNotFound)
+	_, err = c.GetUploadURL(ctx, "sticker")
+	wantAPIError(t, err, http.StatusBadRequest)
+}
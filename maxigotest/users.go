@@ -0,0 +1,388 @@
+package maxigotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// Actor simulates a user of the bot. Its methods change the server state
+// the way the user's app would and deliver the resulting update to the bot:
+// to every matching webhook subscription with the secret in [SecretHeader],
+// or to GET /updates if the bot has no subscriptions. Updates from chats
+// the bot is not a member of are not delivered.
+//
+// Webhooks are called synchronously, so replies sent by a webhook handler
+// are visible in [Server.Sent] when the method returns. A bot that polls
+// for updates replies later; use [Server.WaitSent] or [Server.Wait]:
+//
+//	alice := srv.As(srv.AddUser("Alice").UserID)
+//	chatID, _ := alice.StartBot("ref-42")
+//	_, _ = alice.SendText(chatID, "/help")
+//	sent, err := srv.WaitSent(ctx, 1)
+//
+// Methods return an error if the action is not possible, e.g. a message
+// to a chat the user is not a member of, or if a webhook fails.
+type Actor struct {
+	srv  *Server
+	user maxigo.User
+}
+
+// As returns an [Actor] that acts as the user with the given ID.
+// It panics if there is no such user.
+func (s *Server) As(userID int64) *Actor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[userID]
+	if !ok || userID == s.bot.UserID {
+		panic(fmt.Sprintf("maxigotest: unknown user %d", userID))
+	}
+	return &Actor{srv: s, user: *u}
+}
+
+// User returns the simulated user.
+func (a *Actor) User() maxigo.User {
+	return a.user
+}
+
+// Dialog returns the ID of the user's dialog with the bot, creating it if
+// needed. No update is delivered; see [Actor.StartBot].
+func (a *Actor) Dialog() int64 {
+	a.srv.mu.Lock()
+	defer a.srv.mu.Unlock()
+	return a.srv.dialog(a.user.UserID).chat.ChatID
+}
+
+// act runs fn with the server locked and delivers the update it returns,
+// if any, after unlocking.
+func (a *Actor) act(fn func(s *Server) (any, error)) error {
+	s := a.srv
+	s.mu.Lock()
+	update, err := fn(s)
+	send := func() error { return nil }
+	if err == nil && update != nil {
+		send = s.deliver(update)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return send()
+}
+
+// StartBot presses Start in the dialog with the bot, delivering a
+// bot_started update with payload (from a deep link; may be empty). It
+// returns the dialog's chat ID.
+func (a *Actor) StartBot(payload string) (int64, error) {
+	var chatID int64
+	err := a.act(func(s *Server) (any, error) {
+		chatID = s.dialog(a.user.UserID).chat.ChatID
+		u := maxigo.BotStartedUpdate{
+			Update: maxigo.Update{UpdateType: maxigo.UpdateBotStarted},
+			ChatID: chatID,
+			User:   a.user,
+		}
+		if payload != "" {
+			u.Payload = &payload
+		}
+		return u, nil
+	})
+	return chatID, err
+}
+
+// SendText sends a text message to a chat, delivering a message_created
+// update. Use [Actor.Dialog] for the chat ID of the dialog with the bot.
+func (a *Actor) SendText(chatID int64, text string) (maxigo.Message, error) {
+	return a.Send(chatID, text)
+}
+
+// Send sends a message with optional text and attachments to a chat,
+// delivering a message_created update. Attachments are given as the bot
+// receives them:
+//
+//	alice.Send(chatID, "", &maxigo.PhotoAttachment{
+//	    AttachmentType: maxigo.AttachmentType{Type: "image"},
+//	    Payload:        maxigo.PhotoAttachmentPayload{URL: "https://example.com/cat.jpg"},
+//	})
+func (a *Actor) Send(chatID int64, text string, attachments ...maxigo.Attachment) (maxigo.Message, error) {
+	var msg maxigo.Message
+	err := a.act(func(s *Server) (any, error) {
+		cs, err := a.memberOf(s, chatID)
+		if err != nil {
+			return nil, err
+		}
+		if cs.chat.Type == maxigo.ChatChannel && !cs.member(a.user.UserID).admin {
+			return nil, fmt.Errorf("maxigotest: user %d can't post in channel %d", a.user.UserID, chatID)
+		}
+		if text == "" && len(attachments) == 0 {
+			return nil, fmt.Errorf("maxigotest: empty message")
+		}
+
+		var body maxigo.MessageBody
+		if text != "" {
+			body.Text = &text
+		}
+		for _, att := range attachments {
+			if att.GetType() == "" {
+				return nil, fmt.Errorf("maxigotest: attachment %T without type", att)
+			}
+			raw, err := json.Marshal(att)
+			if err != nil {
+				return nil, fmt.Errorf("maxigotest: encode attachment: %w", err)
+			}
+			body.Attachments = append(body.Attachments, raw)
+		}
+		msg = *s.addMessage(cs, a.user, body, nil)
+		return s.botUpdate(cs, maxigo.MessageCreatedUpdate{
+			Update:  maxigo.Update{UpdateType: maxigo.UpdateMessageCreated, Timestamp: msg.Timestamp},
+			Message: msg,
+		}), nil
+	})
+	return msg, err
+}
+
+// PressButton presses the callback button with payload in the inline
+// keyboard of a message, delivering a message_callback update. It returns
+// the callback ID, which the bot answers with [maxigo.Client.AnswerCallback].
+func (a *Actor) PressButton(mid, payload string) (string, error) {
+	var callbackID string
+	err := a.act(func(s *Server) (any, error) {
+		msg, ok := s.messages[mid]
+		if !ok {
+			return nil, fmt.Errorf("maxigotest: message %s not found", mid)
+		}
+		cs, err := a.memberOf(s, *msg.Recipient.ChatID)
+		if err != nil {
+			return nil, err
+		}
+		if !hasCallbackButton(msg, payload) {
+			return nil, fmt.Errorf("maxigotest: message %s has no callback button with payload %q", mid, payload)
+		}
+
+		callbackID = "cb." + strconv.FormatInt(s.newID(), 10)
+		now := s.now()
+		pressed := *msg
+		return s.botUpdate(cs, maxigo.MessageCallbackUpdate{
+			Update:   maxigo.Update{UpdateType: maxigo.UpdateMessageCallback, Timestamp: now},
+			Callback: maxigo.Callback{Timestamp: now, CallbackID: callbackID, Payload: payload, User: a.user},
+			Message:  &pressed,
+		}), nil
+	})
+	return callbackID, err
+}
+
+// hasCallbackButton reports whether msg has an inline keyboard with a
+// callback button with payload.
+func hasCallbackButton(msg *maxigo.Message, payload string) bool {
+	atts, _ := msg.Body.ParseAttachments()
+	for _, att := range atts {
+		kb, ok := att.(*maxigo.InlineKeyboardAttachment)
+		if !ok {
+			continue
+		}
+		for _, row := range kb.Payload.Buttons {
+			if slices.ContainsFunc(row, func(b maxigo.Button) bool { return b.Type == "callback" && b.Payload == payload }) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Join joins a group chat or channel, e.g. by link, delivering a
+// user_added update without an inviter.
+func (a *Actor) Join(chatID int64) error {
+	return a.addMember(chatID, a.user.UserID, nil)
+}
+
+// Leave leaves a group chat or channel, delivering a user_removed update
+// without an admin.
+func (a *Actor) Leave(chatID int64) error {
+	return a.removeMember(chatID, a.user.UserID, false)
+}
+
+// AddMember adds another user to a chat the actor is a member of,
+// delivering a user_added update with the actor as inviter.
+func (a *Actor) AddMember(chatID, userID int64) error {
+	return a.addMember(chatID, userID, &a.user.UserID)
+}
+
+// RemoveMember removes another user from a chat the actor administers,
+// delivering a user_removed update with the actor as admin.
+func (a *Actor) RemoveMember(chatID, userID int64) error {
+	return a.removeMember(chatID, userID, true)
+}
+
+func (a *Actor) addMember(chatID, userID int64, inviter *int64) error {
+	return a.act(func(s *Server) (any, error) {
+		cs, err := a.group(s, chatID)
+		if err != nil {
+			return nil, err
+		}
+		if inviter != nil {
+			if _, err := a.memberOf(s, chatID); err != nil {
+				return nil, err
+			}
+		}
+		u, ok := s.users[userID]
+		if !ok || userID == s.bot.UserID {
+			return nil, fmt.Errorf("maxigotest: unknown user %d", userID)
+		}
+		if cs.member(userID) != nil {
+			return nil, fmt.Errorf("maxigotest: user %d is already a member of chat %d", userID, chatID)
+		}
+		if cs.blocked[userID] {
+			return nil, fmt.Errorf("maxigotest: user %d is blocked in chat %d", userID, chatID)
+		}
+
+		now := s.now()
+		cs.members = append(cs.members, &member{userID: userID, joinTime: now})
+		cs.chat.LastEventTime = now
+		return s.botUpdate(cs, maxigo.UserAddedUpdate{
+			Update:    maxigo.Update{UpdateType: maxigo.UpdateUserAdded, Timestamp: now},
+			ChatID:    chatID,
+			User:      *u,
+			InviterID: inviter,
+			IsChannel: cs.chat.Type == maxigo.ChatChannel,
+		}), nil
+	})
+}
+
+func (a *Actor) removeMember(chatID, userID int64, byAdmin bool) error {
+	return a.act(func(s *Server) (any, error) {
+		cs, err := a.group(s, chatID)
+		if err != nil {
+			return nil, err
+		}
+		self, err := a.memberOf(s, chatID)
+		if err != nil {
+			return nil, err
+		}
+		m := cs.member(userID)
+		if m == nil {
+			return nil, fmt.Errorf("maxigotest: user %d is not a member of chat %d", userID, chatID)
+		}
+		var admin *int64
+		if byAdmin {
+			if !canManageMembers(self.member(a.user.UserID)) {
+				return nil, fmt.Errorf("maxigotest: user %d can't remove members of chat %d", a.user.UserID, chatID)
+			}
+			if m.owner {
+				return nil, fmt.Errorf("maxigotest: chat owner can't be removed")
+			}
+			admin = &a.user.UserID
+		}
+
+		now := s.now()
+		cs.members = slices.DeleteFunc(cs.members, func(m *member) bool { return m.userID == userID })
+		cs.chat.LastEventTime = now
+		return s.botUpdate(cs, maxigo.UserRemovedUpdate{
+			Update:    maxigo.Update{UpdateType: maxigo.UpdateUserRemoved, Timestamp: now},
+			ChatID:    chatID,
+			User:      *s.users[userID],
+			AdminID:   admin,
+			IsChannel: cs.chat.Type == maxigo.ChatChannel,
+		}), nil
+	})
+}
+
+// AddBot adds the bot to a group chat or channel, delivering a bot_added
+// update. The bot joins as a regular member; grant it rights with
+// [Server.SetAdmin].
+func (a *Actor) AddBot(chatID int64) error {
+	return a.act(func(s *Server) (any, error) {
+		cs, err := a.group(s, chatID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := a.memberOf(s, chatID); err != nil {
+			return nil, err
+		}
+		if cs.member(s.bot.UserID) != nil {
+			return nil, fmt.Errorf("maxigotest: bot is already a member of chat %d", chatID)
+		}
+
+		now := s.now()
+		cs.members = append(cs.members, &member{userID: s.bot.UserID, joinTime: now})
+		cs.chat.Status = maxigo.ChatStatusActive
+		cs.chat.LastEventTime = now
+		return maxigo.BotAddedUpdate{
+			Update:    maxigo.Update{UpdateType: maxigo.UpdateBotAdded, Timestamp: now},
+			ChatID:    chatID,
+			User:      a.user,
+			IsChannel: cs.chat.Type == maxigo.ChatChannel,
+		}, nil
+	})
+}
+
+// RemoveBot removes the bot from a chat the actor administers, delivering
+// a bot_removed update.
+func (a *Actor) RemoveBot(chatID int64) error {
+	return a.act(func(s *Server) (any, error) {
+		cs, err := a.group(s, chatID)
+		if err != nil {
+			return nil, err
+		}
+		self, err := a.memberOf(s, chatID)
+		if err != nil {
+			return nil, err
+		}
+		if !canManageMembers(self.member(a.user.UserID)) {
+			return nil, fmt.Errorf("maxigotest: user %d can't remove members of chat %d", a.user.UserID, chatID)
+		}
+		if cs.member(s.bot.UserID) == nil {
+			return nil, fmt.Errorf("maxigotest: bot is not a member of chat %d", chatID)
+		}
+
+		now := s.now()
+		cs.members = slices.DeleteFunc(cs.members, func(m *member) bool { return m.userID == s.bot.UserID })
+		cs.chat.Status = maxigo.ChatStatusRemoved
+		cs.chat.LastEventTime = now
+		return maxigo.BotRemovedUpdate{
+			Update:    maxigo.Update{UpdateType: maxigo.UpdateBotRemoved, Timestamp: now},
+			ChatID:    chatID,
+			User:      a.user,
+			IsChannel: cs.chat.Type == maxigo.ChatChannel,
+		}, nil
+	})
+}
+
+// memberOf returns a chat the actor is a member of.
+func (a *Actor) memberOf(s *Server, chatID int64) (*chatState, error) {
+	cs, ok := s.chats[chatID]
+	if !ok {
+		return nil, fmt.Errorf("maxigotest: chat %d not found", chatID)
+	}
+	if cs.member(a.user.UserID) == nil {
+		return nil, fmt.Errorf("maxigotest: user %d is not a member of chat %d", a.user.UserID, chatID)
+	}
+	return cs, nil
+}
+
+// group returns a group chat or channel.
+func (a *Actor) group(s *Server, chatID int64) (*chatState, error) {
+	cs, ok := s.chats[chatID]
+	if !ok {
+		return nil, fmt.Errorf("maxigotest: chat %d not found", chatID)
+	}
+	if cs.chat.Type == maxigo.ChatDialog {
+		return nil, fmt.Errorf("maxigotest: chat %d is a dialog", chatID)
+	}
+	return cs, nil
+}
+
+// canManageMembers reports whether a member may remove others.
+func canManageMembers(m *member) bool {
+	return m.owner || (m.admin && slices.Contains(m.perms, maxigo.PermAddRemoveMembers))
+}
+
+// botUpdate returns update if the bot is a member of the chat, or nil.
+func (s *Server) botUpdate(cs *chatState, update any) any {
+	if cs.member(s.bot.UserID) == nil {
+		return nil
+	}
+	return update
+}
@@ -0,0 +1,282 @@
+package maxigotest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxigo-bot/maxigo-client"
+)
+
+// echoBot replies to text messages with "echo: <text>" and answers
+// callbacks with their payload.
+func echoBot(ctx context.Context, c *maxigo.Client, raw json.RawMessage) error {
+	var u maxigo.MessageCallbackUpdate
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return err
+	}
+	switch u.UpdateType {
+	case maxigo.UpdateMessageCreated:
+		var created maxigo.MessageCreatedUpdate
+		if err := json.Unmarshal(raw, &created); err != nil {
+			return err
+		}
+		if created.Message.Body.Text == nil {
+			return nil
+		}
+		_, err := c.SendMessage(ctx, *created.Message.Recipient.ChatID, &maxigo.NewMessageBody{
+			Text: maxigo.Some("echo: " + *created.Message.Body.Text),
+		})
+		return err
+	case maxigo.UpdateMessageCallback:
+		_, err := c.AnswerCallback(ctx, u.Callback.CallbackID, &maxigo.CallbackAnswer{Notification: maxigo.Some(u.Callback.Payload)})
+		return err
+	}
+	return nil
+}
+
+// queuedTypes returns the types of the updates queued for GET /updates.
+func queuedTypes(srv *Server) []maxigo.UpdateType {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	var types []maxigo.UpdateType
+	for _, raw := range srv.upd.updates {
+		var u maxigo.Update
+		_ = json.Unmarshal(raw, &u)
+		types = append(types, u.UpdateType)
+	}
+	return types
+}
+
+func TestActorWebhook(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var received []json.RawMessage
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(SecretHeader) != "hook-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, raw)
+		mu.Unlock()
+		if err := echoBot(r.Context(), c, raw); err != nil {
+			t.Errorf("bot: %v", err)
+		}
+	}))
+	defer hook.Close()
+	if _, err := c.Subscribe(ctx, hook.URL, nil, "hook-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := srv.As(srv.AddUser("Alice").UserID)
+	chatID, err := alice.StartBot("ref-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alice.SendText(chatID, "hi"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Webhooks are synchronous: the reply is already there.
+	if sent := srv.Sent(); len(sent) != 1 || *sent[0].Body.Text != "echo: hi" || *sent[0].Recipient.ChatID != chatID {
+		t.Errorf("sent = %+v", sent)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	var started maxigo.BotStartedUpdate
+	if len(received) != 2 || json.Unmarshal(received[0], &started) != nil || started.Payload == nil || *started.Payload != "ref-42" {
+		t.Errorf("received = %s", received)
+	}
+	if types := queuedTypes(srv); len(types) != 0 {
+		t.Errorf("queued = %v, want none with a webhook", types)
+	}
+}
+
+func TestActorWebhookError(t *testing.T) {
+	srv, c := newTestServer(t)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer hook.Close()
+	if _, err := c.Subscribe(context.Background(), hook.URL, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := srv.As(srv.AddUser("Alice").UserID)
+	if _, err := alice.StartBot(""); err == nil {
+		t.Error("StartBot = nil, want webhook error")
+	}
+}
+
+func TestActorLongPolling(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		var marker int64
+		for ctx.Err() == nil {
+			list, err := c.GetUpdates(ctx, maxigo.GetUpdatesOpts{Timeout: 1, Marker: marker})
+			if err != nil {
+				return
+			}
+			for _, raw := range list.Updates {
+				_ = echoBot(ctx, c, raw)
+			}
+			marker = *list.Marker
+		}
+	}()
+
+	alice := srv.As(srv.AddUser("Alice").UserID)
+	if _, err := alice.SendText(alice.Dialog(), "ping"); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := srv.WaitSent(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *sent[0].Body.Text != "echo: ping" {
+		t.Errorf("reply = %q", *sent[0].Body.Text)
+	}
+}
+
+func TestActorPressButton(t *testing.T) {
+	srv, c := newTestServer(t)
+	ctx := context.Background()
+	alice := srv.As(srv.AddUser("Alice").UserID)
+
+	msg, err := c.SendMessageToUser(ctx, alice.User().UserID, &maxigo.NewMessageBody{
+		Text: maxigo.Some("Continue?"),
+		Attachments: []maxigo.AttachmentRequest{maxigo.NewInlineKeyboardAttachment([][]maxigo.Button{
+			{maxigo.NewCallbackButton("Yes", "yes"), maxigo.NewCallbackButton("No", "no")},
+		})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := alice.PressButton(msg.Body.MID, "maybe"); err == nil {
+		t.Error("PressButton(maybe) = nil, want error")
+	}
+	id, err := alice.PressButton(msg.Body.MID, "yes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := c.GetUpdates(ctx, maxigo.GetUpdatesOpts{Timeout: 1})
+	if err != nil || len(list.Updates) != 1 {
+		t.Fatalf("GetUpdates = %+v, %v", list, err)
+	}
+	var u maxigo.MessageCallbackUpdate
+	if err := json.Unmarshal(list.Updates[0], &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Callback.CallbackID != id || u.Callback.Payload != "yes" || u.Callback.User.UserID != alice.User().UserID || u.Message.Body.MID != msg.Body.MID {
+		t.Errorf("update = %+v", u)
+	}
+	if err := echoBot(ctx, c, list.Updates[0]); err != nil {
+		t.Fatal(err)
+	}
+	if answers := srv.Answers(); len(answers) != 1 || answers[0].CallbackID != id {
+		t.Errorf("answers = %+v", answers)
+	}
+}
+
+func TestActorSendAttachment(t *testing.T) {
+	srv, _ := newTestServer(t)
+	alice := srv.As(srv.AddUser("Alice").UserID)
+
+	msg, err := alice.Send(alice.Dialog(), "look", &maxigo.PhotoAttachment{
+		AttachmentType: maxigo.AttachmentType{Type: "image"},
+		Payload:        maxigo.PhotoAttachmentPayload{URL: "https://example.com/cat.jpg"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	atts, err := msg.Body.ParseAttachments()
+	if err != nil || len(atts) != 1 {
+		t.Fatalf("attachments = %v, %v", atts, err)
+	}
+	if photo, ok := atts[0].(*maxigo.PhotoAttachment); !ok || photo.Payload.URL != "https://example.com/cat.jpg" {
+		t.Errorf("attachment = %+v", atts[0])
+	}
+	if _, err := alice.Send(alice.Dialog(), ""); err == nil {
+		t.Error("empty Send = nil, want error")
+	}
+}
+
+func TestActorMembership(t *testing.T) {
+	srv, _ := newTestServer(t)
+	alice := srv.As(srv.AddUser("Alice").UserID)
+	bob := srv.As(srv.AddUser("Bob").UserID)
+	carol := srv.AddUser("Carol")
+	team := srv.AddChat("Team", alice.User().UserID, bob.User().UserID)
+
+	if err := bob.AddMember(team.ChatID, carol.UserID); err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.RemoveMember(team.ChatID, carol.UserID); err == nil {
+		t.Error("RemoveMember by non-admin = nil, want error")
+	}
+	if err := alice.RemoveMember(team.ChatID, carol.UserID); err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.Leave(team.ChatID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.SendText(team.ChatID, "hi"); err == nil {
+		t.Error("SendText after Leave = nil, want error")
+	}
+	if err := bob.Join(team.ChatID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the bot, nothing is delivered.
+	if err := alice.RemoveBot(team.ChatID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alice.SendText(team.ChatID, "unseen"); err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.AddBot(team.ChatID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []maxigo.UpdateType{
+		maxigo.UpdateUserAdded, maxigo.UpdateUserRemoved, maxigo.UpdateUserRemoved,
+		maxigo.UpdateUserAdded, maxigo.UpdateBotRemoved, maxigo.UpdateBotAdded,
+	}
+	got := queuedTypes(srv)
+	if len(got) != len(want) {
+		t.Fatalf("updates = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("updates = %v, want %v", got, want)
+			break
+		}
+	}
+
+	var added maxigo.UserAddedUpdate
+	srv.mu.Lock()
+	_ = json.Unmarshal(srv.upd.updates[0], &added)
+	srv.mu.Unlock()
+	if added.User.UserID != carol.UserID || added.InviterID == nil || *added.InviterID != bob.User().UserID {
+		t.Errorf("user_added = %+v", added)
+	}
+	if chat, _ := srv.Chat(team.ChatID); chat.Status != maxigo.ChatStatusActive {
+		t.Errorf("status = %q, want active", chat.Status)
+	}
+}
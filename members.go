@@ -0,0 +1,216 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// defaultBulkConcurrency is the number of parallel requests used by bulk
+// operations when no concurrency is set.
+const defaultBulkConcurrency = 4
+
+// BulkMembersOpts holds optional parameters for [Client.AddMembersBulk] and
+// [Client.RemoveMembersBulk].
+type BulkMembersOpts struct {
+	// ChunkSize is the number of users per AddMembers request.
+	// 0 or values above [MaxMembersPerRequest] use MaxMembersPerRequest.
+	ChunkSize int
+	// Concurrency limits the number of parallel requests. 0 uses the default (4).
+	Concurrency int
+	// RequestsPerSecond limits the request rate. 0 means no limit.
+	RequestsPerSecond float64
+	// Block also blocks removed users (RemoveMembersBulk only).
+	// See [Client.RemoveMember].
+	Block bool
+}
+
+// MemberResult is the outcome of a bulk operation for one user.
+type MemberResult struct {
+	UserID int64
+	// Err is nil on success. API failures are [*Error] values.
+	Err error
+}
+
+// MembersReport is the result of a bulk member operation. Results are in
+// the order of the input user IDs, with duplicates removed.
+type MembersReport struct {
+	Results []MemberResult
+}
+
+// Succeeded returns the IDs of users processed successfully.
+func (r *MembersReport) Succeeded() []int64 {
+	var ids []int64
+	for _, res := range r.Results {
+		if res.Err == nil {
+			ids = append(ids, res.UserID)
+		}
+	}
+	return ids
+}
+
+// Failed returns the results of users that could not be processed.
+func (r *MembersReport) Failed() []MemberResult {
+	var failed []MemberResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// AddMembersBulk adds any number of users to a chat. Users are sent in chunks
+// of opts.ChunkSize with bounded concurrency and rate. If a chunk is rejected
+// by the API, its users are retried one by one so that a single invalid user
+// does not fail the others. Network errors and timeouts fail the whole chunk.
+//
+// The report has one result per unique user ID. The returned error is non-nil
+// only if ctx is done before all chunks were attempted; users that were not
+// attempted are reported with the context error.
+func (c *Client) AddMembersBulk(ctx context.Context, chatID int64, userIDs []int64, opts BulkMembersOpts) (*MembersReport, error) {
+	ids := uniqueIDs(userIDs)
+	size := opts.ChunkSize
+	if size <= 0 || size > MaxMembersPerRequest {
+		size = MaxMembersPerRequest
+	}
+
+	var chunks [][]int64
+	for start := 0; start < len(ids); start += size {
+		chunks = append(chunks, ids[start:min(start+size, len(ids))])
+	}
+
+	results := make(map[int64]error, len(ids))
+	var mu sync.Mutex
+	setResult := func(id int64, err error) {
+		mu.Lock()
+		results[id] = err
+		mu.Unlock()
+	}
+
+	limiter := newRateLimiter(opts.RequestsPerSecond)
+	err := runBulk(ctx, len(chunks), opts.Concurrency, func(i int) {
+		chunk := chunks[i]
+		err := c.addMembersChunk(ctx, limiter, chatID, chunk)
+		if err == nil || len(chunk) == 1 || !isRejection(err) {
+			for _, id := range chunk {
+				setResult(id, err)
+			}
+			return
+		}
+		for _, id := range chunk {
+			setResult(id, c.addMembersChunk(ctx, limiter, chatID, []int64{id}))
+		}
+	})
+
+	return membersReport("AddMembersBulk", ids, results, err), err
+}
+
+// RemoveMembersBulk removes any number of users from a chat, one request per
+// user, with bounded concurrency and rate. See [Client.AddMembersBulk] for
+// the report and error semantics.
+func (c *Client) RemoveMembersBulk(ctx context.Context, chatID int64, userIDs []int64, opts BulkMembersOpts) (*MembersReport, error) {
+	ids := uniqueIDs(userIDs)
+	results := make(map[int64]error, len(ids))
+	var mu sync.Mutex
+	limiter := newRateLimiter(opts.RequestsPerSecond)
+
+	err := runBulk(ctx, len(ids), opts.Concurrency, func(i int) {
+		err := c.removeMember(ctx, limiter, chatID, ids[i], opts.Block)
+		mu.Lock()
+		results[ids[i]] = err
+		mu.Unlock()
+	})
+
+	return membersReport("RemoveMembersBulk", ids, results, err), err
+}
+
+func (c *Client) addMembersChunk(ctx context.Context, limiter *rateLimiter, chatID int64, ids []int64) error {
+	if err := limiter.Wait(ctx); err != nil {
+		return timeoutError("AddMembersBulk", err)
+	}
+	result, err := c.AddMembers(ctx, chatID, ids)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return apiError("AddMembers", http.StatusOK, result.Message)
+	}
+	return nil
+}
+
+func (c *Client) removeMember(ctx context.Context, limiter *rateLimiter, chatID, userID int64, block bool) error {
+	if err := limiter.Wait(ctx); err != nil {
+		return timeoutError("RemoveMembersBulk", err)
+	}
+	result, err := c.RemoveMember(ctx, chatID, userID, block)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return apiError("RemoveMember", http.StatusOK, result.Message)
+	}
+	return nil
+}
+
+// isRejection reports whether err is an API error for the request content,
+// as opposed to a transport failure or rate limiting.
+func isRejection(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == ErrAPI && e.StatusCode != http.StatusTooManyRequests && e.StatusCode < 500
+}
+
+// runBulk calls fn for indexes 0..n-1 with at most concurrency calls in
+// parallel. It stops starting new calls when ctx is done and returns ctx.Err().
+func runBulk(ctx context.Context, n, concurrency int, fn func(i int)) error {
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for i := range n {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	return nil
+}
+
+func membersReport(op string, ids []int64, results map[int64]error, ctxErr error) *MembersReport {
+	report := &MembersReport{Results: make([]MemberResult, len(ids))}
+	for i, id := range ids {
+		err, ok := results[id]
+		if !ok {
+			err = timeoutError(op, ctxErr)
+		}
+		report.Results[i] = MemberResult{UserID: id, Err: err}
+	}
+	return report
+}
+
+// uniqueIDs returns ids without duplicates, keeping the first occurrence.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
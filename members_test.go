@@ -0,0 +1,142 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestAddMembersBulk(t *testing.T) {
+	var mu sync.Mutex
+	var requests [][]int64
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chats/9/members" || r.Method != http.MethodPost {
+			t.Errorf("%s %s, want POST /chats/9/members", r.Method, r.URL.Path)
+		}
+		var body UserIDsList
+		readJSON(t, r, &body)
+		mu.Lock()
+		requests = append(requests, body.UserIDs)
+		mu.Unlock()
+
+		if slices.Contains(body.UserIDs, 13) {
+			writeError(t, w, http.StatusBadRequest, `{"code":"user.not.found","message":"User 13 not found"}`)
+			return
+		}
+		writeJSON(t, w, SimpleQueryResult{Success: true})
+	})
+
+	ids := []int64{1, 2, 3, 4, 13, 6, 7, 2}
+	report, err := c.AddMembersBulk(context.Background(), 9, ids, BulkMembersOpts{ChunkSize: 3, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Results) != 7 {
+		t.Fatalf("results = %d, want 7 unique users", len(report.Results))
+	}
+	if got := report.Succeeded(); !slices.Equal(got, []int64{1, 2, 3, 4, 6, 7}) {
+		t.Errorf("Succeeded = %v", got)
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].UserID != 13 {
+		t.Fatalf("Failed = %v, want user 13", failed)
+	}
+	var e *Error
+	if !errors.As(failed[0].Err, &e) || e.StatusCode != http.StatusBadRequest {
+		t.Errorf("err = %v, want 400 API error", failed[0].Err)
+	}
+
+	// Chunks: [1 2 3] ok, [4 13 6] rejected and retried as 3 singles, [7] ok.
+	if len(requests) != 6 {
+		t.Errorf("requests = %v, want 6", requests)
+	}
+}
+
+func TestAddMembersBulkNetworkFailure(t *testing.T) {
+	c, srv := testClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	srv.Close()
+
+	report, err := c.AddMembersBulk(context.Background(), 9, []int64{1, 2}, BulkMembersOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, res := range report.Results {
+		var e *Error
+		if !errors.As(res.Err, &e) || e.Kind != ErrNetwork {
+			t.Errorf("user %d: err = %v, want network error", res.UserID, res.Err)
+		}
+	}
+}
+
+func TestRemoveMembersBulk(t *testing.T) {
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("method = %s, want DELETE", r.Method)
+		}
+		if r.URL.Query().Get("block") != "true" {
+			t.Error("block should be set")
+		}
+		if r.URL.Query().Get("user_id") == "13" {
+			writeError(t, w, http.StatusNotFound, `{"code":"not.found","message":"Member not found"}`)
+			return
+		}
+		writeJSON(t, w, SimpleQueryResult{Success: true})
+	})
+
+	report, err := c.RemoveMembersBulk(context.Background(), 9, []int64{1, 13, 3}, BulkMembersOpts{Block: true, RequestsPerSecond: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := report.Succeeded(); !slices.Equal(got, []int64{1, 3}) {
+		t.Errorf("Succeeded = %v, want [1 3]", got)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0].UserID != 13 {
+		t.Errorf("Failed = %v, want user 13", failed)
+	}
+}
+
+func TestRemoveMembersBulkNotSuccessful(t *testing.T) {
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("user_id") == "5" {
+			writeJSON(t, w, SimpleQueryResult{Success: false, Message: "Cannot remove chat owner"})
+			return
+		}
+		writeJSON(t, w, SimpleQueryResult{Success: true})
+	})
+
+	report, err := c.RemoveMembersBulk(context.Background(), 9, []int64{5, 6}, BulkMembersOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := report.Succeeded(); !slices.Equal(got, []int64{6}) {
+		t.Errorf("Succeeded = %v, want [6]", got)
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].UserID != 5 {
+		t.Fatalf("Failed = %v, want user 5", failed)
+	}
+	var e *Error
+	if !errors.As(failed[0].Err, &e) || e.Kind != ErrAPI || e.Op != "RemoveMember" || e.Message != "Cannot remove chat owner" {
+		t.Errorf("Err = %v, want API error with server message", failed[0].Err)
+	}
+}
+
+func TestRemoveMembersBulkCanceled(t *testing.T) {
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, SimpleQueryResult{Success: true})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := c.RemoveMembersBulk(ctx, 9, []int64{1, 2, 3}, BulkMembersOpts{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(report.Succeeded()) != 0 || len(report.Failed()) != 3 {
+		t.Errorf("report = %+v, want all users failed", report.Results)
+	}
+}
@@ -13,6 +13,10 @@ import (
 // Set body.DisableLinkPreview to true to prevent the server from generating link previews.
 // Corresponds to POST /messages.
 func (c *Client) SendMessage(ctx context.Context, chatID int64, body *NewMessageBody) (*Message, error) {
+	if err := c.validateRequest("SendMessage", func() *Error { return body.validate(true) }); err != nil {
+		return nil, err
+	}
+
 	q := make(url.Values)
 	if chatID != 0 {
 		q.Set("chat_id", strconv.FormatInt(chatID, 10))
@@ -32,6 +36,10 @@ func (c *Client) SendMessage(ctx context.Context, chatID int64, body *NewMessage
 // Set body.DisableLinkPreview to true to prevent the server from generating link previews.
 // Corresponds to POST /messages with user_id query parameter.
 func (c *Client) SendMessageToUser(ctx context.Context, userID int64, body *NewMessageBody) (*Message, error) {
+	if err := c.validateRequest("SendMessageToUser", func() *Error { return body.validate(true) }); err != nil {
+		return nil, err
+	}
+
 	q := make(url.Values)
 	q.Set("user_id", strconv.FormatInt(userID, 10))
 	if body != nil && body.DisableLinkPreview {
@@ -51,6 +59,10 @@ func (c *Client) SendMessageToUser(ctx context.Context, userID int64, body *NewM
 // the message. Use [Client.CheckPhoneNumbers] to verify numbers beforehand.
 // Corresponds to POST /messages with phone_numbers query parameter.
 func (c *Client) SendMessageToPhones(ctx context.Context, phoneNumbers []string, body *NewMessageBody) (*Message, error) {
+	if err := c.validateRequest("SendMessageToPhones", func() *Error { return body.validate(true) }); err != nil {
+		return nil, err
+	}
+
 	q := make(url.Values)
 	q.Set("phone_numbers", strings.Join(phoneNumbers, ","))
 	if body != nil && body.DisableLinkPreview {
@@ -67,6 +79,10 @@ func (c *Client) SendMessageToPhones(ctx context.Context, phoneNumbers []string,
 // EditMessage edits an existing message.
 // Corresponds to PUT /messages.
 func (c *Client) EditMessage(ctx context.Context, messageID string, body *NewMessageBody) (*SimpleQueryResult, error) {
+	if err := c.validateRequest("EditMessage", func() *Error { return body.validate(false) }); err != nil {
+		return nil, err
+	}
+
 	q := make(url.Values)
 	q.Set("message_id", messageID)
 
@@ -146,6 +162,10 @@ func (c *Client) GetMessageByID(ctx context.Context, messageID string) (*Message
 // AnswerCallback sends a response to a callback button press.
 // Corresponds to POST /answers.
 func (c *Client) AnswerCallback(ctx context.Context, callbackID string, answer *CallbackAnswer) (*SimpleQueryResult, error) {
+	if err := c.validateRequest("AnswerCallback", func() *Error { return answer.validate() }); err != nil {
+		return nil, err
+	}
+
 	q := make(url.Values)
 	q.Set("callback_id", callbackID)
 
@@ -64,3 +64,51 @@ func WithRetry(intervals ...time.Duration) Option {
 		}
 	}
 }
+
+// WithImagePreprocessing enables image preprocessing for photo uploads.
+// Every image passed to [Client.UploadPhoto] (and the FromFile/FromURL helpers)
+// is decoded, downscaled to opts.MaxDimension, stripped of metadata and
+// re-encoded as JPEG before upload. See [PreprocessImage] for details.
+//
+//	client, err := maxigo.New("token", maxigo.WithImagePreprocessing(maxigo.ImageOpts{
+//	    MaxDimension: 1920,
+//	    Quality:      80,
+//	}))
+func WithImagePreprocessing(opts ImageOpts) Option {
+	return func(cl *Client) {
+		cl.imageOpts = &opts
+	}
+}
+
+// WithValidation enables offline validation of request bodies.
+// [Client.SendMessage], [Client.EditMessage], [Client.AnswerCallback],
+// [Client.EditChat], [Client.EditBot], [Client.SetAdmins] and related methods
+// call the body's Validate method before sending and return an [*Error]
+// with Kind [ErrValidation] instead of making a request that the API
+// would reject with 400.
+func WithValidation() Option {
+	return func(cl *Client) {
+		cl.validate = true
+	}
+}
+
+// WithCache enables caching of [Client.GetChat], [Client.GetMembership] and
+// [Client.GetAdmins] results for ttl. Entries are dropped when the client
+// edits the chat ([Client.EditChat], [Client.SetAdmins], [Client.RemoveAdmin]
+// and similar) and when [Client.GetUpdates] returns an update that changes
+// the chat. Webhook updates must be passed to [Cache.Observe]:
+//
+//	client, err := maxigo.New("token", maxigo.WithCache(time.Minute))
+//	// in the webhook handler:
+//	_ = client.Cache().Observe(rawUpdate)
+//
+// A ttl of 0 or less disables the cache.
+func WithCache(ttl time.Duration) Option {
+	return func(cl *Client) {
+		if ttl <= 0 {
+			cl.cache = nil
+			return
+		}
+		cl.cache = newCache(ttl)
+	}
+}
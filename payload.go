@@ -0,0 +1,200 @@
+package maxigo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidPayload is returned by [CallbackCodec.Decode] when a payload
+	// is malformed, belongs to another codec, or has a wrong signature.
+	ErrInvalidPayload = errors.New("invalid callback payload")
+
+	// ErrStalePayload is returned by [CallbackCodec.Decode] when a payload was
+	// encoded with another version or is older than [CallbackCodecOpts.MaxAge].
+	ErrStalePayload = errors.New("stale callback payload")
+
+	// ErrPayloadTooLong is returned by [CallbackCodec.Encode] when the encoded
+	// payload exceeds [MaxCallbackPayloadLength].
+	ErrPayloadTooLong = errors.New("callback payload is too long")
+)
+
+// payloadMACSize is the length of the truncated HMAC-SHA256 in bytes.
+const payloadMACSize = 12
+
+// CallbackCodecOpts configures a [CallbackCodec].
+type CallbackCodecOpts struct {
+	// Version is written into every payload. Payloads with a different
+	// version are rejected with [ErrStalePayload]; bump it when T changes
+	// incompatibly.
+	Version int
+	// Key signs payloads with HMAC-SHA256. Payloads with a missing or wrong
+	// signature are rejected with [ErrInvalidPayload]. Nil disables signing.
+	Key []byte
+	// MaxAge rejects payloads older than the given duration with
+	// [ErrStalePayload]. 0 disables expiry and omits the timestamp.
+	MaxAge time.Duration
+}
+
+// CallbackCodec encodes values of type T into callback button payloads and
+// decodes them back from [MessageCallbackUpdate].
+//
+// A payload has the form prefix:version[:time]:data[:signature], where data is
+// T encoded as JSON and base64url. Use short JSON field names to keep payloads
+// compact.
+//
+//	type voteData struct {
+//	    PollID int64 `json:"p"`
+//	    Option int   `json:"o"`
+//	}
+//
+//	votes := maxigo.NewCallbackCodec[voteData]("vote", maxigo.CallbackCodecOpts{
+//	    Version: 1,
+//	    Key:     secret,
+//	})
+//	btn, err := votes.Button("Yes", voteData{PollID: 42, Option: 1})
+//	...
+//	if votes.Match(upd.Callback.Payload) {
+//	    v, err := votes.DecodeUpdate(upd)
+//	}
+//
+// A CallbackCodec is safe for concurrent use.
+type CallbackCodec[T any] struct {
+	prefix string
+	opts   CallbackCodecOpts
+	now    func() time.Time
+}
+
+// NewCallbackCodec creates a codec whose payloads start with prefix.
+// Prefixes let several codecs share one bot; see [CallbackCodec.Match].
+func NewCallbackCodec[T any](prefix string, opts CallbackCodecOpts) *CallbackCodec[T] {
+	return &CallbackCodec[T]{prefix: prefix, opts: opts, now: time.Now}
+}
+
+// Match reports whether payload was produced by a codec with the same prefix.
+// It does not verify the payload.
+func (c *CallbackCodec[T]) Match(payload string) bool {
+	return strings.HasPrefix(payload, c.prefix+":")
+}
+
+// Encode returns the payload for v. The error wraps [ErrPayloadTooLong] if
+// the result exceeds [MaxCallbackPayloadLength].
+func (c *CallbackCodec[T]) Encode(v T) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encode callback payload: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(c.prefix)
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(c.opts.Version))
+	if c.opts.MaxAge > 0 {
+		sb.WriteByte(':')
+		sb.WriteString(strconv.FormatInt(c.now().Unix(), 36))
+	}
+	sb.WriteByte(':')
+	sb.WriteString(base64.RawURLEncoding.EncodeToString(data))
+	if c.opts.Key != nil {
+		mac := c.sign(sb.String())
+		sb.WriteByte(':')
+		sb.WriteString(base64.RawURLEncoding.EncodeToString(mac))
+	}
+
+	payload := sb.String()
+	if n := textLength(payload); n > MaxCallbackPayloadLength {
+		return "", fmt.Errorf("%w: %d characters, limit is %d", ErrPayloadTooLong, n, MaxCallbackPayloadLength)
+	}
+	return payload, nil
+}
+
+// Decode verifies payload and decodes it into a value of type T.
+// The error wraps [ErrInvalidPayload] or [ErrStalePayload].
+func (c *CallbackCodec[T]) Decode(payload string) (T, error) {
+	var zero T
+
+	if !c.Match(payload) {
+		return zero, fmt.Errorf("%w: prefix mismatch", ErrInvalidPayload)
+	}
+	rest := payload[len(c.prefix)+1:]
+
+	if c.opts.Key != nil {
+		i := strings.LastIndexByte(rest, ':')
+		if i < 0 {
+			return zero, fmt.Errorf("%w: missing signature", ErrInvalidPayload)
+		}
+		mac, err := base64.RawURLEncoding.DecodeString(rest[i+1:])
+		if err != nil || !hmac.Equal(mac, c.sign(payload[:len(c.prefix)+1+i])) {
+			return zero, fmt.Errorf("%w: bad signature", ErrInvalidPayload)
+		}
+		rest = rest[:i]
+	}
+
+	want := 2
+	if c.opts.MaxAge > 0 {
+		want = 3
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) != want {
+		return zero, fmt.Errorf("%w: malformed", ErrInvalidPayload)
+	}
+
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return zero, fmt.Errorf("%w: malformed version", ErrInvalidPayload)
+	}
+	if version != c.opts.Version {
+		return zero, fmt.Errorf("%w: version %d, want %d", ErrStalePayload, version, c.opts.Version)
+	}
+
+	if c.opts.MaxAge > 0 {
+		ts, err := strconv.ParseInt(parts[1], 36, 64)
+		if err != nil {
+			return zero, fmt.Errorf("%w: malformed timestamp", ErrInvalidPayload)
+		}
+		if age := c.now().Sub(time.Unix(ts, 0)); age > c.opts.MaxAge {
+			return zero, fmt.Errorf("%w: issued %s ago", ErrStalePayload, age.Truncate(time.Second))
+		}
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil {
+		return zero, fmt.Errorf("%w: malformed data", ErrInvalidPayload)
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return zero, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return v, nil
+}
+
+// DecodeUpdate decodes the payload of a pressed button. See [CallbackCodec.Decode].
+func (c *CallbackCodec[T]) DecodeUpdate(upd *MessageCallbackUpdate) (T, error) {
+	if upd == nil {
+		var zero T
+		return zero, fmt.Errorf("%w: nil update", ErrInvalidPayload)
+	}
+	return c.Decode(upd.Callback.Payload)
+}
+
+// Button creates a callback button with v encoded as its payload.
+func (c *CallbackCodec[T]) Button(text string, v T) (Button, error) {
+	payload, err := c.Encode(v)
+	if err != nil {
+		return Button{}, err
+	}
+	return NewCallbackButton(text, payload), nil
+}
+
+func (c *CallbackCodec[T]) sign(s string) []byte {
+	h := hmac.New(sha256.New, c.opts.Key)
+	h.Write([]byte(s))
+	return h.Sum(nil)[:payloadMACSize]
+}
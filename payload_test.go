@@ -0,0 +1,133 @@
+package maxigo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPayload struct {
+	Action string `json:"a"`
+	ID     int64  `json:"i"`
+	Page   int    `json:"p,omitempty"`
+}
+
+func TestCallbackCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CallbackCodecOpts
+	}{
+		{"plain", CallbackCodecOpts{}},
+		{"versioned", CallbackCodecOpts{Version: 3}},
+		{"signed", CallbackCodecOpts{Key: []byte("secret")}},
+		{"signed with expiry", CallbackCodecOpts{Version: 1, Key: []byte("secret"), MaxAge: time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := NewCallbackCodec[testPayload]("item", tt.opts)
+			want := testPayload{Action: "del", ID: 42, Page: 2}
+
+			payload, err := codec.Encode(want)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if !strings.HasPrefix(payload, "item:") {
+				t.Errorf("payload = %q, want item: prefix", payload)
+			}
+			if !codec.Match(payload) {
+				t.Error("Match = false for own payload")
+			}
+
+			got, err := codec.DecodeUpdate(&MessageCallbackUpdate{Callback: Callback{Payload: payload}})
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got != want {
+				t.Errorf("Decode = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCallbackCodecRejects(t *testing.T) {
+	key := []byte("secret")
+	signed := NewCallbackCodec[testPayload]("item", CallbackCodecOpts{Version: 1, Key: key})
+	payload, err := signed.Encode(testPayload{Action: "del", ID: 1})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	// Replace the data part with another value, keeping the signature.
+	other, _ := signed.Encode(testPayload{Action: "del", ID: 2})
+	parts := strings.Split(payload, ":")
+	forgedParts := strings.Split(other, ":")
+	parts[2] = forgedParts[2]
+	forged := strings.Join(parts, ":")
+
+	tests := []struct {
+		name    string
+		codec   *CallbackCodec[testPayload]
+		payload string
+		wantErr error
+	}{
+		{"other prefix", NewCallbackCodec[testPayload]("user", CallbackCodecOpts{Version: 1, Key: key}), payload, ErrInvalidPayload},
+		{"forged data", signed, forged, ErrInvalidPayload},
+		{"wrong key", NewCallbackCodec[testPayload]("item", CallbackCodecOpts{Version: 1, Key: []byte("other")}), payload, ErrInvalidPayload},
+		{"unsigned", signed, "item:1:eyJhIjoiZGVsIiwiaSI6MX0", ErrInvalidPayload},
+		{"garbage", NewCallbackCodec[testPayload]("item", CallbackCodecOpts{}), "item:0:!!!", ErrInvalidPayload},
+		{"old version", NewCallbackCodec[testPayload]("item", CallbackCodecOpts{Version: 2, Key: key}), payload, ErrStalePayload},
+		{"raw string", signed, "del:1", ErrInvalidPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.codec.Decode(tt.payload)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallbackCodecExpiry(t *testing.T) {
+	codec := NewCallbackCodec[testPayload]("item", CallbackCodecOpts{Key: []byte("k"), MaxAge: time.Minute})
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	codec.now = func() time.Time { return now }
+
+	payload, err := codec.Encode(testPayload{Action: "ok"})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, err := codec.Decode(payload); err != nil {
+		t.Fatalf("fresh payload: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := codec.Decode(payload); !errors.Is(err, ErrStalePayload) {
+		t.Errorf("err = %v, want ErrStalePayload", err)
+	}
+}
+
+func TestCallbackCodecButton(t *testing.T) {
+	codec := NewCallbackCodec[testPayload]("item", CallbackCodecOpts{Key: []byte("k")})
+
+	btn, err := codec.Button("Delete", testPayload{Action: "del", ID: 7})
+	if err != nil {
+		t.Fatalf("Button: %v", err)
+	}
+	if btn.Type != "callback" || btn.Text != "Delete" {
+		t.Errorf("button = %+v, want callback button Delete", btn)
+	}
+	if err := btn.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	_, err = codec.Button("Big", testPayload{Action: strings.Repeat("x", MaxCallbackPayloadLength)})
+	if !errors.Is(err, ErrPayloadTooLong) {
+		t.Errorf("err = %v, want ErrPayloadTooLong", err)
+	}
+}
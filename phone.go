@@ -0,0 +1,211 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidPhone is wrapped by errors returned from [NormalizePhone].
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// Phone number length limits in digits, including the country code (E.164).
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// NormalizePhone converts a phone number to the format expected by
+// [Client.CheckPhoneNumbers] and [Client.SendMessageToPhones]: international
+// digits without "+". Spaces, dashes, dots and parentheses are removed and the
+// "00" international prefix is dropped. Russian numbers written with the
+// trunk prefix 8 ("8 900 123-45-67") or without a country code
+// ("9001234567") get the country code 7.
+//
+//	NormalizePhone("+7 (900) 123-45-67") // "79001234567"
+//	NormalizePhone("8-900-123-45-67")    // "79001234567"
+//	NormalizePhone("0049 30 1234567")    // "49301234567"
+func NormalizePhone(s string) (string, error) {
+	trimmed := strings.TrimSpace(s)
+	international := strings.HasPrefix(trimmed, "+")
+	digits := make([]byte, 0, len(trimmed))
+	for i, r := range trimmed {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '\u00a0':
+		default:
+			return "", fmt.Errorf("%w: %q: unexpected character %q", ErrInvalidPhone, s, r)
+		}
+	}
+
+	switch {
+	case len(digits) > 2 && digits[0] == '0' && digits[1] == '0':
+		digits = digits[2:]
+	case len(digits) == 11 && digits[0] == '8' && !international:
+		digits[0] = '7'
+	case len(digits) == 10 && digits[0] == '9' && !international:
+		digits = append([]byte{'7'}, digits...)
+	}
+
+	if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits {
+		return "", fmt.Errorf("%w: %q: must have %d to %d digits", ErrInvalidPhone, s, minPhoneDigits, maxPhoneDigits)
+	}
+	if digits[0] == '0' {
+		return "", fmt.Errorf("%w: %q: missing country code", ErrInvalidPhone, s)
+	}
+	return string(digits), nil
+}
+
+// NormalizePhones normalizes phone numbers with [NormalizePhone] and removes
+// duplicates, keeping the order of first occurrence. Numbers that cannot be
+// normalized are returned in invalid as given.
+func NormalizePhones(phones []string) (valid, invalid []string) {
+	seen := make(map[string]bool, len(phones))
+	for _, p := range phones {
+		n, err := NormalizePhone(p)
+		if err != nil {
+			invalid = append(invalid, p)
+			continue
+		}
+		if !seen[n] {
+			seen[n] = true
+			valid = append(valid, n)
+		}
+	}
+	return valid, invalid
+}
+
+// PhoneBatchOpts holds optional parameters for [Client.CheckPhoneNumbersBatch]
+// and [Client.SendMessageToPhonesBatch].
+type PhoneBatchOpts struct {
+	// ChunkSize is the number of phone numbers per request.
+	// 0 or values above [MaxPhonesPerRequest] use MaxPhonesPerRequest.
+	ChunkSize int
+	// Concurrency limits the number of parallel requests. 0 uses the default (4).
+	Concurrency int
+	// RequestsPerSecond limits the request rate. 0 means no limit.
+	RequestsPerSecond float64
+}
+
+// PhoneBatchError is a failed request of a batched phone operation.
+type PhoneBatchError struct {
+	// Phones are the normalized numbers of the failed chunk.
+	Phones []string
+	Err    error
+}
+
+// PhoneBatchResult is the merged result of a batched phone operation.
+type PhoneBatchResult struct {
+	// Phones are the normalized, deduplicated numbers that were processed.
+	Phones []string
+	// Invalid are the input numbers rejected by [NormalizePhone].
+	// They are not sent to the API.
+	Invalid []string
+	// Existing are the registered numbers, in the order of Phones
+	// (CheckPhoneNumbersBatch only).
+	Existing []string
+	// Messages are the sent messages, one per successful chunk in chunk
+	// order (SendMessageToPhonesBatch only).
+	Messages []*Message
+	// Failed lists the chunks whose request failed.
+	Failed []PhoneBatchError
+}
+
+// CheckPhoneNumbersBatch normalizes and deduplicates phone numbers, then
+// checks them with [Client.CheckPhoneNumbers] in chunks of opts.ChunkSize
+// with bounded concurrency and rate, and merges the results.
+//
+// Failed chunks are reported in the result and do not stop the others. The
+// returned error is non-nil only if ctx is done before all chunks were
+// attempted; chunks that were not attempted are reported with the context
+// error.
+func (c *Client) CheckPhoneNumbersBatch(ctx context.Context, phones []string, opts PhoneBatchOpts) (*PhoneBatchResult, error) {
+	existing := make(map[string]bool)
+	var mu sync.Mutex
+	result, err := c.runPhoneBatch(ctx, "CheckPhoneNumbersBatch", phones, opts, func(_ int, chunk []string) error {
+		found, err := c.CheckPhoneNumbers(ctx, chunk)
+		mu.Lock()
+		for _, p := range found {
+			existing[p] = true
+		}
+		mu.Unlock()
+		return err
+	})
+	for _, p := range result.Phones {
+		if existing[p] {
+			result.Existing = append(result.Existing, p)
+		}
+	}
+	return result, err
+}
+
+// SendMessageToPhonesBatch normalizes and deduplicates phone numbers, then
+// sends body with [Client.SendMessageToPhones] in chunks of opts.ChunkSize
+// with bounded concurrency and rate. See [Client.CheckPhoneNumbersBatch] for
+// the error semantics.
+func (c *Client) SendMessageToPhonesBatch(ctx context.Context, phones []string, body *NewMessageBody, opts PhoneBatchOpts) (*PhoneBatchResult, error) {
+	if err := c.validateRequest("SendMessageToPhonesBatch", func() *Error { return body.validate(true) }); err != nil {
+		return nil, err
+	}
+
+	sent := make(map[int]*Message)
+	var mu sync.Mutex
+	result, err := c.runPhoneBatch(ctx, "SendMessageToPhonesBatch", phones, opts, func(i int, chunk []string) error {
+		msg, err := c.SendMessageToPhones(ctx, chunk, body)
+		if err == nil {
+			mu.Lock()
+			sent[i] = msg
+			mu.Unlock()
+		}
+		return err
+	})
+	for _, i := range slices.Sorted(maps.Keys(sent)) {
+		result.Messages = append(result.Messages, sent[i])
+	}
+	return result, err
+}
+
+// runPhoneBatch normalizes phones, splits them into chunks and calls fn for
+// each chunk. Chunk errors are collected in the result in chunk order.
+func (c *Client) runPhoneBatch(ctx context.Context, op string, phones []string, opts PhoneBatchOpts, fn func(i int, chunk []string) error) (*PhoneBatchResult, error) {
+	valid, invalid := NormalizePhones(phones)
+	result := &PhoneBatchResult{Phones: valid, Invalid: invalid}
+
+	size := opts.ChunkSize
+	if size <= 0 || size > MaxPhonesPerRequest {
+		size = MaxPhonesPerRequest
+	}
+	var chunks [][]string
+	for start := 0; start < len(valid); start += size {
+		chunks = append(chunks, valid[start:min(start+size, len(valid))])
+	}
+
+	errs := make([]error, len(chunks))
+	done := make([]bool, len(chunks))
+	limiter := newRateLimiter(opts.RequestsPerSecond)
+	ctxErr := runBulk(ctx, len(chunks), opts.Concurrency, func(i int) {
+		if err := limiter.Wait(ctx); err != nil {
+			errs[i] = timeoutError(op, err)
+		} else {
+			errs[i] = fn(i, chunks[i])
+		}
+		done[i] = true
+	})
+
+	for i, chunk := range chunks {
+		err := errs[i]
+		if !done[i] {
+			err = timeoutError(op, ctxErr)
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, PhoneBatchError{Phones: chunk, Err: err})
+		}
+	}
+	return result, ctxErr
+}
@@ -0,0 +1,145 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"79001234567", "79001234567"},
+		{"+7 (900) 123-45-67", "79001234567"},
+		{"8 900 123 45 67", "79001234567"},
+		{"8-900-123-45-67", "79001234567"},
+		{"9001234567", "79001234567"},
+		{" +7.900.123.45.67 ", "79001234567"},
+		{"+1 (415) 555-2671", "14155552671"},
+		{"0049 30 1234567", "49301234567"},
+		{"+44 20 7946 0958", "442079460958"},
+		{"+7 900 1234567", "79001234567"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.in)
+		if err != nil {
+			t.Errorf("NormalizePhone(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePhoneInvalid(t *testing.T) {
+	for _, in := range []string{"", "12345", "+7 900 abc 45 67", "7900+1234567", "0123456789", "1234567890123456"} {
+		_, err := NormalizePhone(in)
+		if !errors.Is(err, ErrInvalidPhone) {
+			t.Errorf("NormalizePhone(%q) err = %v, want ErrInvalidPhone", in, err)
+		}
+	}
+}
+
+func TestNormalizePhones(t *testing.T) {
+	valid, invalid := NormalizePhones([]string{"+7 900 123-45-67", "89001234567", "bad", "+1 415 555 2671", "9001234567"})
+	if !slices.Equal(valid, []string{"79001234567", "14155552671"}) {
+		t.Errorf("valid = %v", valid)
+	}
+	if !slices.Equal(invalid, []string{"bad"}) {
+		t.Errorf("invalid = %v, want [bad]", invalid)
+	}
+}
+
+func TestCheckPhoneNumbersBatch(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		phones := r.URL.Query().Get("phone_numbers")
+		mu.Lock()
+		requests = append(requests, phones)
+		mu.Unlock()
+		if strings.Contains(phones, "79000000005") {
+			writeError(t, w, http.StatusInternalServerError, `{"code":"internal","message":"Oops"}`)
+			return
+		}
+		var existing []string
+		for p := range strings.SplitSeq(phones, ",") {
+			if p != "79000000002" {
+				existing = append(existing, p)
+			}
+		}
+		writeJSON(t, w, map[string][]string{"existing_phone_numbers": existing})
+	})
+
+	phones := []string{"+7 900 000-00-01", "89000000002", "79000000003", "x", "9000000004", "79000000005", "+79000000001"}
+	result, err := c.CheckPhoneNumbersBatch(context.Background(), phones, PhoneBatchOpts{ChunkSize: 2, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Errorf("requests = %v, want 3 chunks", requests)
+	}
+	if !slices.Equal(result.Existing, []string{"79000000001", "79000000003", "79000000004"}) {
+		t.Errorf("Existing = %v", result.Existing)
+	}
+	if !slices.Equal(result.Invalid, []string{"x"}) {
+		t.Errorf("Invalid = %v, want [x]", result.Invalid)
+	}
+	if len(result.Failed) != 1 || !slices.Equal(result.Failed[0].Phones, []string{"79000000005"}) {
+		t.Fatalf("Failed = %+v, want chunk [79000000005]", result.Failed)
+	}
+	var e *Error
+	if !errors.As(result.Failed[0].Err, &e) || e.StatusCode != http.StatusInternalServerError {
+		t.Errorf("err = %v, want 500 API error", result.Failed[0].Err)
+	}
+}
+
+func TestSendMessageToPhonesBatch(t *testing.T) {
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		phones := r.URL.Query().Get("phone_numbers")
+		writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: phones}}})
+	})
+
+	result, err := c.SendMessageToPhonesBatch(context.Background(),
+		[]string{"79000000001", "79000000002", "79000000003"},
+		&NewMessageBody{Text: Some("hi")},
+		PhoneBatchOpts{ChunkSize: 2, RequestsPerSecond: 1000},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var mids []string
+	for _, m := range result.Messages {
+		mids = append(mids, m.Body.MID)
+	}
+	if !slices.Equal(mids, []string{"79000000001,79000000002", "79000000003"}) {
+		t.Errorf("messages = %v, want one per chunk in order", mids)
+	}
+	if len(result.Failed) != 0 {
+		t.Errorf("Failed = %+v", result.Failed)
+	}
+}
+
+func TestSendMessageToPhonesBatchCanceled(t *testing.T) {
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := c.SendMessageToPhonesBatch(ctx, []string{"79000000001"}, &NewMessageBody{Text: Some("hi")}, PhoneBatchOpts{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(result.Failed) != 1 {
+		t.Errorf("Failed = %+v, want the unsent chunk", result.Failed)
+	}
+}
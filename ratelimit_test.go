@@ -0,0 +1,33 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(100) // one event per 10ms
+
+	start := time.Now()
+	for range 5 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("5 events took %v, want at least 40ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := newRateLimiter(0.001).Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	var unlimited *rateLimiter
+	if newRateLimiter(0) != nil || unlimited.Wait(context.Background()) != nil {
+		t.Error("zero rate should not limit")
+	}
+}
@@ -0,0 +1,232 @@
+package maxigo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+var (
+	// ErrMessageNotRegistered is returned by [MessageRegistry.EditByKey] when
+	// no message is registered under the key.
+	ErrMessageNotRegistered = errors.New("no message registered under key")
+	// ErrMessageDeleted is wrapped by errors from [MessageRegistry.EditByKey]
+	// when the registered message no longer exists. The registration is
+	// removed.
+	ErrMessageDeleted = errors.New("registered message was deleted")
+)
+
+// MessageKey identifies a registered message by a caller-chosen key
+// within a chat, e.g. {ChatID: 42, Key: "daily-status"}.
+type MessageKey struct {
+	ChatID int64  `json:"chat_id"`
+	Key    string `json:"key"`
+}
+
+// String returns the key as "chatID/key".
+func (k MessageKey) String() string {
+	return fmt.Sprintf("%d/%s", k.ChatID, k.Key)
+}
+
+// MessageStore persists message IDs of a [MessageRegistry].
+// Implementations must be safe for concurrent use.
+type MessageStore interface {
+	// MessageID returns the message ID registered under key, or "" if none.
+	MessageID(ctx context.Context, key MessageKey) (string, error)
+	// SetMessageID registers mid under key, replacing any previous message.
+	SetMessageID(ctx context.Context, key MessageKey, mid string) error
+	// DeleteMessageID removes the registration. Removing a missing key is
+	// not an error.
+	DeleteMessageID(ctx context.Context, key MessageKey) error
+}
+
+// MemoryMessageStore is a [MessageStore] kept in memory.
+type MemoryMessageStore struct {
+	mu   sync.Mutex
+	mids map[MessageKey]string
+}
+
+// NewMemoryMessageStore creates an empty in-memory store.
+func NewMemoryMessageStore() *MemoryMessageStore {
+	return &MemoryMessageStore{mids: make(map[MessageKey]string)}
+}
+
+// MessageID implements [MessageStore].
+func (s *MemoryMessageStore) MessageID(_ context.Context, key MessageKey) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mids[key], nil
+}
+
+// SetMessageID implements [MessageStore].
+func (s *MemoryMessageStore) SetMessageID(_ context.Context, key MessageKey, mid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mids[key] = mid
+	return nil
+}
+
+// DeleteMessageID implements [MessageStore].
+func (s *MemoryMessageStore) DeleteMessageID(_ context.Context, key MessageKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mids, key)
+	return nil
+}
+
+// FileMessageStore is a [MessageStore] that keeps all registrations in one
+// JSON file, rewritten atomically on every change.
+type FileMessageStore struct {
+	store *fileStore[MessageKey, registeredMessage]
+}
+
+// registeredMessage is the on-disk form of a [FileMessageStore] entry.
+type registeredMessage struct {
+	MessageKey
+	MID string `json:"mid"`
+}
+
+// OpenFileMessageStore loads the registrations stored at path. A missing
+// file is an empty store; it is created on the first change.
+func OpenFileMessageStore(path string) (*FileMessageStore, error) {
+	store, err := openFileStore(path, "message store",
+		func(e registeredMessage) MessageKey { return e.MessageKey },
+		func(a, b registeredMessage) int {
+			return cmp.Or(cmp.Compare(a.ChatID, b.ChatID), cmp.Compare(a.Key, b.Key))
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &FileMessageStore{store: store}, nil
+}
+
+// MessageID implements [MessageStore].
+func (s *FileMessageStore) MessageID(_ context.Context, key MessageKey) (string, error) {
+	e, _ := s.store.get(key)
+	return e.MID, nil
+}
+
+// SetMessageID implements [MessageStore].
+func (s *FileMessageStore) SetMessageID(_ context.Context, key MessageKey, mid string) error {
+	return s.store.put(registeredMessage{MessageKey: key, MID: mid})
+}
+
+// DeleteMessageID implements [MessageStore].
+func (s *FileMessageStore) DeleteMessageID(_ context.Context, key MessageKey) error {
+	return s.store.remove(key)
+}
+
+// MessageRegistry records sent messages under logical keys so they can be
+// edited or deleted later without keeping message IDs around:
+//
+//	reg := client.NewMessageRegistry(nil)
+//	key := maxigo.MessageKey{ChatID: chatID, Key: "daily-status"}
+//	_, err := reg.Upsert(ctx, key, &maxigo.NewMessageBody{Text: maxigo.Some(status)})
+//
+// Create one with [Client.NewMessageRegistry].
+type MessageRegistry struct {
+	client *Client
+	store  MessageStore
+}
+
+// NewMessageRegistry creates a registry that keeps message IDs in store.
+// A nil store uses a [MemoryMessageStore].
+func (c *Client) NewMessageRegistry(store MessageStore) *MessageRegistry {
+	if store == nil {
+		store = NewMemoryMessageStore()
+	}
+	return &MessageRegistry{client: c, store: store}
+}
+
+// Send sends body to key.ChatID and registers the message under key,
+// replacing any previous registration. The previous message is not changed.
+func (r *MessageRegistry) Send(ctx context.Context, key MessageKey, body *NewMessageBody) (*Message, error) {
+	msg, err := r.client.SendMessage(ctx, key.ChatID, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Register(ctx, key, msg); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// Register records an already sent message under key.
+func (r *MessageRegistry) Register(ctx context.Context, key MessageKey, msg *Message) error {
+	if err := r.store.SetMessageID(ctx, key, msg.Body.MID); err != nil {
+		return fmt.Errorf("message registry: %w", err)
+	}
+	return nil
+}
+
+// MessageID returns the ID of the message registered under key, or "" if
+// there is none.
+func (r *MessageRegistry) MessageID(ctx context.Context, key MessageKey) (string, error) {
+	mid, err := r.store.MessageID(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("message registry: %w", err)
+	}
+	return mid, nil
+}
+
+// EditByKey edits the message registered under key with [Client.EditMessage].
+// It returns [ErrMessageNotRegistered] if there is no registration. If the
+// message was deleted, the registration is removed and the returned error
+// wraps both [ErrMessageDeleted] and the API error.
+func (r *MessageRegistry) EditByKey(ctx context.Context, key MessageKey, body *NewMessageBody) error {
+	mid, err := r.MessageID(ctx, key)
+	if err != nil {
+		return err
+	}
+	if mid == "" {
+		return fmt.Errorf("%w: %s", ErrMessageNotRegistered, key)
+	}
+
+	result, err := r.client.EditMessage(ctx, mid, body)
+	if err == nil && !result.Success {
+		err = apiError("EditMessage", http.StatusOK, result.Message)
+	}
+	if isNotFound(err) {
+		if derr := r.store.DeleteMessageID(ctx, key); derr != nil {
+			return fmt.Errorf("message registry: %w", derr)
+		}
+		return fmt.Errorf("%w: %s: %w", ErrMessageDeleted, key, err)
+	}
+	return err
+}
+
+// Upsert edits the message registered under key, or sends a new one and
+// registers it if there is no registration or the message was deleted.
+// It returns the new message, or nil if the existing one was edited.
+func (r *MessageRegistry) Upsert(ctx context.Context, key MessageKey, body *NewMessageBody) (*Message, error) {
+	err := r.EditByKey(ctx, key, body)
+	if errors.Is(err, ErrMessageNotRegistered) || errors.Is(err, ErrMessageDeleted) {
+		return r.Send(ctx, key, body)
+	}
+	return nil, err
+}
+
+// DeleteByKey deletes the message registered under key with
+// [Client.DeleteMessage] and removes the registration. A missing
+// registration or an already deleted message is not an error.
+func (r *MessageRegistry) DeleteByKey(ctx context.Context, key MessageKey) error {
+	mid, err := r.MessageID(ctx, key)
+	if err != nil || mid == "" {
+		return err
+	}
+
+	result, err := r.client.DeleteMessage(ctx, mid)
+	if err == nil && !result.Success {
+		err = apiError("DeleteMessage", http.StatusOK, result.Message)
+	}
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err := r.store.DeleteMessageID(ctx, key); err != nil {
+		return fmt.Errorf("message registry: %w", err)
+	}
+	return nil
+}
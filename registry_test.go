@@ -0,0 +1,186 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// registryServer is a fake API with a set of existing messages.
+type registryServer struct {
+	mu       sync.Mutex
+	next     int
+	messages map[string]string // mid -> text
+}
+
+func newRegistryServer(t *testing.T) (*Client, *registryServer) {
+	t.Helper()
+	s := &registryServer{messages: make(map[string]string)}
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		mid := r.URL.Query().Get("message_id")
+		switch r.Method {
+		case http.MethodPost:
+			var body NewMessageBody
+			readJSON(t, r, &body)
+			s.next++
+			mid = fmt.Sprintf("mid.%d", s.next)
+			s.messages[mid] = body.Text.Value
+			writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: mid}}})
+		case http.MethodPut:
+			if _, ok := s.messages[mid]; !ok {
+				writeError(t, w, http.StatusNotFound, `{"code":"not.found","message":"Message not found"}`)
+				return
+			}
+			var body NewMessageBody
+			readJSON(t, r, &body)
+			s.messages[mid] = body.Text.Value
+			writeJSON(t, w, SimpleQueryResult{Success: true})
+		case http.MethodDelete:
+			if _, ok := s.messages[mid]; !ok {
+				writeError(t, w, http.StatusNotFound, `{"code":"not.found","message":"Message not found"}`)
+				return
+			}
+			delete(s.messages, mid)
+			writeJSON(t, w, SimpleQueryResult{Success: true})
+		}
+	})
+	return c, s
+}
+
+func (s *registryServer) text(mid string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text, ok := s.messages[mid]
+	return text, ok
+}
+
+func (s *registryServer) remove(mid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.messages, mid)
+}
+
+func TestMessageRegistry(t *testing.T) {
+	c, srv := newRegistryServer(t)
+	reg := c.NewMessageRegistry(nil)
+	ctx := context.Background()
+	key := MessageKey{ChatID: 7, Key: "status"}
+
+	if err := reg.EditByKey(ctx, key, &NewMessageBody{Text: Some("x")}); !errors.Is(err, ErrMessageNotRegistered) {
+		t.Fatalf("err = %v, want ErrMessageNotRegistered", err)
+	}
+
+	msg, err := reg.Upsert(ctx, key, &NewMessageBody{Text: Some("v1")})
+	if err != nil || msg == nil {
+		t.Fatalf("Upsert = %v, %v; want new message", msg, err)
+	}
+	msg, err = reg.Upsert(ctx, key, &NewMessageBody{Text: Some("v2")})
+	if err != nil || msg != nil {
+		t.Fatalf("Upsert = %v, %v; want edit in place", msg, err)
+	}
+	if text, _ := srv.text("mid.1"); text != "v2" {
+		t.Errorf("text = %q, want v2", text)
+	}
+
+	// The message is deleted by someone else.
+	srv.remove("mid.1")
+	err = reg.EditByKey(ctx, key, &NewMessageBody{Text: Some("v3")})
+	var e *Error
+	if !errors.Is(err, ErrMessageDeleted) || !errors.As(err, &e) || e.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want ErrMessageDeleted wrapping 404", err)
+	}
+	if mid, _ := reg.MessageID(ctx, key); mid != "" {
+		t.Errorf("registration = %q, want removed", mid)
+	}
+
+	msg, err = reg.Upsert(ctx, key, &NewMessageBody{Text: Some("v4")})
+	if err != nil || msg == nil || msg.Body.MID != "mid.2" {
+		t.Fatalf("Upsert = %v, %v; want new message mid.2", msg, err)
+	}
+
+	if err := reg.DeleteByKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.text("mid.2"); ok {
+		t.Error("message was not deleted")
+	}
+	if err := reg.DeleteByKey(ctx, key); err != nil {
+		t.Errorf("second DeleteByKey = %v, want nil", err)
+	}
+}
+
+func TestMessageRegistryDeleteGone(t *testing.T) {
+	c, srv := newRegistryServer(t)
+	reg := c.NewMessageRegistry(nil)
+	ctx := context.Background()
+	key := MessageKey{ChatID: 7, Key: "loading"}
+
+	if _, err := reg.Send(ctx, key, &NewMessageBody{Text: Some("loading…")}); err != nil {
+		t.Fatal(err)
+	}
+	srv.remove("mid.1")
+	if err := reg.DeleteByKey(ctx, key); err != nil {
+		t.Fatalf("DeleteByKey = %v, want nil for deleted message", err)
+	}
+	if mid, _ := reg.MessageID(ctx, key); mid != "" {
+		t.Errorf("registration = %q, want removed", mid)
+	}
+}
+
+func TestMessageRegistryKeysPerChat(t *testing.T) {
+	c, srv := newRegistryServer(t)
+	reg := c.NewMessageRegistry(nil)
+	ctx := context.Background()
+
+	for _, chatID := range []int64{1, 2} {
+		if _, err := reg.Send(ctx, MessageKey{ChatID: chatID, Key: "status"}, &NewMessageBody{Text: Some("new")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := reg.EditByKey(ctx, MessageKey{ChatID: 2, Key: "status"}, &NewMessageBody{Text: Some("edited")}); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := srv.text("mid.1"); a != "new" {
+		t.Errorf("chat 1 text = %q, want unchanged", a)
+	}
+	if b, _ := srv.text("mid.2"); b != "edited" {
+		t.Errorf("chat 2 text = %q, want edited", b)
+	}
+}
+
+func TestFileMessageStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.json")
+	ctx := context.Background()
+	key := MessageKey{ChatID: -5, Key: "daily"}
+
+	store, err := OpenFileMessageStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMessageID(ctx, key, "mid.9"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMessageID(ctx, MessageKey{ChatID: 1, Key: "x"}, "mid.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteMessageID(ctx, MessageKey{ChatID: 1, Key: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = OpenFileMessageStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mid, _ := store.MessageID(ctx, key); mid != "mid.9" {
+		t.Errorf("MessageID = %q, want mid.9", mid)
+	}
+	if mid, _ := store.MessageID(ctx, MessageKey{ChatID: 1, Key: "x"}); mid != "" {
+		t.Errorf("deleted key = %q, want empty", mid)
+	}
+}
@@ -0,0 +1,70 @@
+package maxigo
+
+import "context"
+
+// Helpers in this file act on a received [Message], e.g. from
+// [MessageCreatedUpdate.Message] or [MessageCallbackUpdate.Message].
+
+// Reply sends body to the conversation msg came from, as a reply to msg.
+// body is not modified; its Link is replaced with a reply link.
+//
+// The destination is the message's chat. For dialogs where Recipient.ChatID
+// is nil, the message is sent to the other user: the sender of msg, or the
+// recipient if msg was sent by a bot.
+func (c *Client) Reply(ctx context.Context, msg *Message, body *NewMessageBody) (*Message, error) {
+	if msg == nil {
+		return nil, validationError("Reply", "message", "must not be nil")
+	}
+	reply := NewMessageBody{}
+	if body != nil {
+		reply = *body
+	}
+	reply.Link = &NewMessageLink{Type: LinkReply, MID: msg.Body.MID}
+	return c.sendTo(ctx, "Reply", msg, &reply)
+}
+
+// ReplyText replies to msg with plain text. See [Client.Reply].
+func (c *Client) ReplyText(ctx context.Context, msg *Message, text string) (*Message, error) {
+	return c.Reply(ctx, msg, &NewMessageBody{Text: Some(text)})
+}
+
+// Forward forwards msg to a chat.
+func (c *Client) Forward(ctx context.Context, msg *Message, chatID int64) (*Message, error) {
+	if msg == nil {
+		return nil, validationError("Forward", "message", "must not be nil")
+	}
+	return c.SendMessage(ctx, chatID, &NewMessageBody{
+		Link: &NewMessageLink{Type: LinkForward, MID: msg.Body.MID},
+	})
+}
+
+// EditText replaces the text of msg, keeping its attachments.
+// msg must have been sent by the bot.
+func (c *Client) EditText(ctx context.Context, msg *Message, text string) (*SimpleQueryResult, error) {
+	if msg == nil {
+		return nil, validationError("EditText", "message", "must not be nil")
+	}
+	return c.EditMessage(ctx, msg.Body.MID, &NewMessageBody{Text: Some(text)})
+}
+
+// Delete deletes msg.
+func (c *Client) Delete(ctx context.Context, msg *Message) (*SimpleQueryResult, error) {
+	if msg == nil {
+		return nil, validationError("Delete", "message", "must not be nil")
+	}
+	return c.DeleteMessage(ctx, msg.Body.MID)
+}
+
+// sendTo sends body to the chat or user that msg came from.
+func (c *Client) sendTo(ctx context.Context, op string, msg *Message, body *NewMessageBody) (*Message, error) {
+	if id := msg.Recipient.ChatID; id != nil && *id != 0 {
+		return c.SendMessage(ctx, *id, body)
+	}
+	if msg.Sender != nil && !msg.Sender.IsBot {
+		return c.SendMessageToUser(ctx, msg.Sender.UserID, body)
+	}
+	if id := msg.Recipient.UserID; id != nil && *id != 0 {
+		return c.SendMessageToUser(ctx, *id, body)
+	}
+	return nil, validationError(op, "recipient", "message has no chat or user to reply to")
+}
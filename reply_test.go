@@ -0,0 +1,149 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestReply(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       *Message
+		wantQuery string
+	}{
+		{
+			"group chat",
+			&Message{
+				Sender:    &User{UserID: 7},
+				Recipient: Recipient{ChatID: int64Ptr(100), ChatType: ChatGroup},
+				Body:      MessageBody{MID: "mid.1"},
+			},
+			"chat_id=100",
+		},
+		{
+			"dialog without chat ID",
+			&Message{
+				Sender:    &User{UserID: 7},
+				Recipient: Recipient{ChatType: ChatDialog, UserID: int64Ptr(1)},
+				Body:      MessageBody{MID: "mid.1"},
+			},
+			"user_id=7",
+		},
+		{
+			"own message in dialog",
+			&Message{
+				Sender:    &User{UserID: 1, IsBot: true},
+				Recipient: Recipient{ChatType: ChatDialog, UserID: int64Ptr(7)},
+				Body:      MessageBody{MID: "mid.1"},
+			},
+			"user_id=7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("query = %q, want %q", r.URL.RawQuery, tt.wantQuery)
+				}
+				var body NewMessageBody
+				readJSON(t, r, &body)
+				if body.Link == nil || body.Link.Type != LinkReply || body.Link.MID != "mid.1" {
+					t.Errorf("link = %+v, want reply to mid.1", body.Link)
+				}
+				if body.Text.Value != "ok" {
+					t.Errorf("text = %q, want ok", body.Text.Value)
+				}
+				writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: "mid.2"}}})
+			})
+
+			orig := &NewMessageBody{Text: Some("ok")}
+			msg, err := c.Reply(context.Background(), tt.msg, orig)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if msg.Body.MID != "mid.2" {
+				t.Errorf("MID = %q, want mid.2", msg.Body.MID)
+			}
+			if orig.Link != nil {
+				t.Error("Reply modified the body")
+			}
+		})
+	}
+
+	t.Run("no destination", func(t *testing.T) {
+		c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("request should not be sent")
+		})
+		_, err := c.ReplyText(context.Background(), &Message{Body: MessageBody{MID: "mid.1"}}, "ok")
+		var e *Error
+		if !errors.As(err, &e) || e.Kind != ErrValidation || e.Op != "Reply" {
+			t.Errorf("err = %v, want Reply validation error", err)
+		}
+	})
+}
+
+func TestForwardEditDelete(t *testing.T) {
+	msg := &Message{Recipient: Recipient{ChatID: int64Ptr(100)}, Body: MessageBody{MID: "mid.1"}}
+
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			if r.URL.Query().Get("chat_id") != "200" {
+				t.Errorf("chat_id = %q, want 200", r.URL.Query().Get("chat_id"))
+			}
+			var body NewMessageBody
+			readJSON(t, r, &body)
+			if body.Link == nil || body.Link.Type != LinkForward || body.Link.MID != "mid.1" {
+				t.Errorf("link = %+v, want forward of mid.1", body.Link)
+			}
+			writeJSON(t, w, sendMessageResult{})
+		case http.MethodPut:
+			if r.URL.Query().Get("message_id") != "mid.1" {
+				t.Errorf("message_id = %q, want mid.1", r.URL.Query().Get("message_id"))
+			}
+			var body map[string]any
+			readJSON(t, r, &body)
+			if body["text"] != "new" {
+				t.Errorf("text = %v, want new", body["text"])
+			}
+			if _, ok := body["attachments"]; ok {
+				t.Error("attachments should be omitted to keep them")
+			}
+			writeJSON(t, w, SimpleQueryResult{Success: true})
+		case http.MethodDelete:
+			if r.URL.Query().Get("message_id") != "mid.1" {
+				t.Errorf("message_id = %q, want mid.1", r.URL.Query().Get("message_id"))
+			}
+			writeJSON(t, w, SimpleQueryResult{Success: true})
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := c.Forward(ctx, msg, 200); err != nil {
+		t.Errorf("Forward: %v", err)
+	}
+	if _, err := c.EditText(ctx, msg, "new"); err != nil {
+		t.Errorf("EditText: %v", err)
+	}
+	if _, err := c.Delete(ctx, msg); err != nil {
+		t.Errorf("Delete: %v", err)
+	}
+	if _, err := c.Delete(ctx, nil); err == nil {
+		t.Error("Delete(nil) should fail")
+	}
+}
+
+func TestForwardWithValidation(t *testing.T) {
+	msg := &Message{Recipient: Recipient{ChatID: int64Ptr(100)}, Body: MessageBody{MID: "mid.1"}}
+
+	c, _ := testClientWithOpts(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, sendMessageResult{})
+	}, WithValidation())
+
+	if _, err := c.Forward(context.Background(), msg, 200); err != nil {
+		t.Errorf("Forward: %v", err)
+	}
+}
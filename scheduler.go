@@ -0,0 +1,387 @@
+package maxigo
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CatchUpPolicy decides what a [Scheduler] does with runs that were missed,
+// e.g. because the process was down at the scheduled time. A run is missed
+// when it is due more than [SchedulerOpts.Grace] ago.
+type CatchUpPolicy int
+
+const (
+	// CatchUpSkip drops missed runs. Recurring jobs continue with their next
+	// run; missed one-off jobs are removed without sending.
+	CatchUpSkip CatchUpPolicy = iota
+	// CatchUpOnce sends a single message for all missed runs of a job.
+	CatchUpOnce
+	// CatchUpAll sends one message per missed run, up to 100 per job.
+	CatchUpAll
+)
+
+// maxCatchUpRuns limits the messages sent for one job with [CatchUpAll].
+const maxCatchUpRuns = 100
+
+// ScheduledJob is a message to send at a fixed time or on a cron schedule.
+// Set exactly one of ChatID and UserID, and exactly one of At and Cron.
+type ScheduledJob struct {
+	// ID identifies the job. [Scheduler.Add] generates one if empty.
+	ID string `json:"id"`
+	// ChatID is the target chat. See [Client.SendMessage].
+	ChatID int64 `json:"chat_id,omitempty"`
+	// UserID is the target user. See [Client.SendMessageToUser].
+	UserID int64 `json:"user_id,omitempty"`
+	// Body is the message to send.
+	Body *NewMessageBody `json:"body"`
+	// DisableLinkPreview is stored separately because it is not part of the
+	// JSON body. [Scheduler.Add] copies it from Body.
+	DisableLinkPreview bool `json:"disable_link_preview,omitempty"`
+
+	// At is the time of a one-off job.
+	At time.Time `json:"at,omitzero"`
+	// Cron is the schedule of a recurring job. See [ParseCron].
+	Cron string `json:"cron,omitempty"`
+	// TimeZone is the IANA time zone the Cron schedule is evaluated in,
+	// e.g. "Europe/Moscow". Empty means UTC.
+	TimeZone string `json:"time_zone,omitempty"`
+
+	// Next is the time of the next run, maintained by the scheduler.
+	Next time.Time `json:"next"`
+}
+
+// next returns the first run of a recurring job after t, or the zero time
+// for one-off jobs.
+func (j *ScheduledJob) next(t time.Time) (time.Time, error) {
+	if j.Cron == "" {
+		return time.Time{}, nil
+	}
+	sched, err := ParseCron(j.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(j.TimeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(t.In(loc)), nil
+}
+
+// JobStore persists scheduled jobs. Implementations must be safe for
+// concurrent use.
+type JobStore interface {
+	// Jobs returns all stored jobs.
+	Jobs(ctx context.Context) ([]ScheduledJob, error)
+	// SaveJob creates or replaces the job with job.ID.
+	SaveJob(ctx context.Context, job ScheduledJob) error
+	// DeleteJob removes a job. Deleting a missing job is not an error.
+	DeleteJob(ctx context.Context, id string) error
+}
+
+// MemoryJobStore is a [JobStore] kept in memory.
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]ScheduledJob
+}
+
+// NewMemoryJobStore creates an empty in-memory store.
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]ScheduledJob)}
+}
+
+// Jobs implements [JobStore]. Jobs are ordered by ID.
+func (s *MemoryJobStore) Jobs(context.Context) ([]ScheduledJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]ScheduledJob, 0, len(s.jobs))
+	for _, id := range slices.Sorted(maps.Keys(s.jobs)) {
+		jobs = append(jobs, s.jobs[id])
+	}
+	return jobs, nil
+}
+
+// SaveJob implements [JobStore].
+func (s *MemoryJobStore) SaveJob(_ context.Context, job ScheduledJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// DeleteJob implements [JobStore].
+func (s *MemoryJobStore) DeleteJob(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+// FileJobStore is a [JobStore] that keeps all jobs in one JSON file,
+// rewritten atomically on every change.
+type FileJobStore struct {
+	store *fileStore[string, ScheduledJob]
+}
+
+// OpenFileJobStore loads the jobs stored at path. A missing file is an
+// empty store; it is created on the first change.
+func OpenFileJobStore(path string) (*FileJobStore, error) {
+	store, err := openFileStore(path, "job store",
+		func(job ScheduledJob) string { return job.ID },
+		func(a, b ScheduledJob) int { return cmp.Compare(a.ID, b.ID) })
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range store.items {
+		if err := decodeKeyboards(job.Body); err != nil {
+			return nil, fmt.Errorf("open job store %s: job %s: %w", path, job.ID, err)
+		}
+	}
+	return &FileJobStore{store: store}, nil
+}
+
+// decodeKeyboards turns inline keyboard payloads loaded from JSON, which
+// decode as generic maps, back into [Keyboard] values, so that a reloaded
+// body is sent and validated like the original one.
+func decodeKeyboards(body *NewMessageBody) error {
+	if body == nil {
+		return nil
+	}
+	for i, att := range body.Attachments {
+		if att.Type != "inline_keyboard" || att.Payload == nil {
+			continue
+		}
+		raw, err := json.Marshal(att.Payload)
+		if err != nil {
+			return err
+		}
+		var kb Keyboard
+		if err := json.Unmarshal(raw, &kb); err != nil {
+			return fmt.Errorf("inline keyboard: %w", err)
+		}
+		body.Attachments[i].Payload = kb
+	}
+	return nil
+}
+
+// Jobs implements [JobStore]. Jobs are ordered by ID.
+func (s *FileJobStore) Jobs(context.Context) ([]ScheduledJob, error) {
+	return s.store.all(), nil
+}
+
+// SaveJob implements [JobStore].
+func (s *FileJobStore) SaveJob(_ context.Context, job ScheduledJob) error {
+	return s.store.put(job)
+}
+
+// DeleteJob implements [JobStore].
+func (s *FileJobStore) DeleteJob(_ context.Context, id string) error {
+	return s.store.remove(id)
+}
+
+// SchedulerOpts holds optional parameters for [Client.NewScheduler].
+type SchedulerOpts struct {
+	// Store persists jobs. nil uses a [MemoryJobStore].
+	Store JobStore
+	// CatchUp decides what happens to missed runs. Default is [CatchUpSkip].
+	CatchUp CatchUpPolicy
+	// Grace is how late a run may fire and still be sent normally.
+	// 0 uses the default (1 minute).
+	Grace time.Duration
+	// PollInterval is how often due jobs are checked. 0 uses the default (1s).
+	PollInterval time.Duration
+	// OnRun, if set, is called after every send attempt.
+	OnRun func(job ScheduledJob, msg *Message, err error)
+}
+
+// Scheduler sends [ScheduledJob] messages at their scheduled times.
+// Create one with [Client.NewScheduler] and start it with [Scheduler.Run].
+//
+// Before a message is sent, the job's next run is saved (or a finished
+// one-off job deleted), so a crash never sends a run twice; it may lose the
+// run that was in progress.
+type Scheduler struct {
+	client *Client
+	opts   SchedulerOpts
+	now    func() time.Time
+
+	// mu serializes store updates of Add, Remove and the run loop.
+	mu sync.Mutex
+}
+
+// NewScheduler creates a scheduler that sends messages with c.
+func (c *Client) NewScheduler(opts SchedulerOpts) *Scheduler {
+	if opts.Store == nil {
+		opts.Store = NewMemoryJobStore()
+	}
+	opts.Grace = cmp.Or(opts.Grace, time.Minute)
+	opts.PollInterval = cmp.Or(opts.PollInterval, time.Second)
+	return &Scheduler{client: c, opts: opts, now: time.Now}
+}
+
+// Add validates job, computes its first run and stores it. It returns the
+// stored job with ID and Next set. Adding a job with an existing ID
+// replaces it.
+func (s *Scheduler) Add(ctx context.Context, job ScheduledJob) (ScheduledJob, error) {
+	const op = "Scheduler.Add"
+	switch {
+	case job.Body == nil:
+		return job, validationError(op, "body", "must not be empty")
+	case (job.ChatID == 0) == (job.UserID == 0):
+		return job, validationError(op, "chat_id", "exactly one of chat_id and user_id must be set")
+	case job.At.IsZero() == (job.Cron == ""):
+		return job, validationError(op, "at", "exactly one of at and cron must be set")
+	}
+
+	if job.ID == "" {
+		job.ID = newJobID()
+	}
+	job.DisableLinkPreview = job.DisableLinkPreview || job.Body.DisableLinkPreview
+	if job.Cron == "" {
+		job.Next = job.At
+	} else {
+		next, err := job.next(s.now())
+		if err != nil {
+			return job, validationError(op, "cron", err.Error())
+		}
+		if next.IsZero() {
+			return job, validationError(op, "cron", "schedule never fires")
+		}
+		job.Next = next
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.opts.Store.SaveJob(ctx, job); err != nil {
+		return job, fmt.Errorf("scheduler: %w", err)
+	}
+	return job, nil
+}
+
+// Remove deletes a job.
+func (s *Scheduler) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.opts.Store.DeleteJob(ctx, id); err != nil {
+		return fmt.Errorf("scheduler: %w", err)
+	}
+	return nil
+}
+
+// Jobs returns all scheduled jobs.
+func (s *Scheduler) Jobs(ctx context.Context) ([]ScheduledJob, error) {
+	return s.opts.Store.Jobs(ctx)
+}
+
+// Run sends due jobs every [SchedulerOpts.PollInterval] until ctx is done.
+// Runs missed while the scheduler was stopped are handled on the first check
+// according to [SchedulerOpts.CatchUp]. Send errors are reported to
+// [SchedulerOpts.OnRun] and do not stop the scheduler; store errors do.
+func (s *Scheduler) Run(ctx context.Context) error {
+	t := time.NewTicker(s.opts.PollInterval)
+	defer t.Stop()
+	for {
+		if err := s.runDue(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// runDue advances all due jobs in the store, then sends their messages.
+func (s *Scheduler) runDue(ctx context.Context) error {
+	type send struct {
+		job  ScheduledJob
+		runs int
+	}
+	var sends []send
+
+	s.mu.Lock()
+	now := s.now()
+	jobs, err := s.opts.Store.Jobs(ctx)
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("scheduler: %w", err)
+	}
+	for _, job := range jobs {
+		if job.Next.IsZero() || job.Next.After(now) {
+			continue
+		}
+		runs := s.runsDue(job, now)
+		next, err := job.next(now)
+		if err == nil && !next.IsZero() {
+			updated := job
+			updated.Next = next
+			err = s.opts.Store.SaveJob(ctx, updated)
+		} else {
+			err = s.opts.Store.DeleteJob(ctx, job.ID)
+		}
+		if err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("scheduler: %w", err)
+		}
+		if runs > 0 {
+			sends = append(sends, send{job, runs})
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sd := range sends {
+		for range sd.runs {
+			msg, err := s.send(ctx, sd.job)
+			if s.opts.OnRun != nil {
+				s.opts.OnRun(sd.job, msg, err)
+			}
+		}
+	}
+	return nil
+}
+
+// runsDue returns the number of messages to send for a job due at or before
+// now, applying the catch-up policy to missed runs.
+func (s *Scheduler) runsDue(job ScheduledJob, now time.Time) int {
+	if now.Sub(job.Next) <= s.opts.Grace {
+		return 1
+	}
+	switch s.opts.CatchUp {
+	case CatchUpOnce:
+		return 1
+	case CatchUpAll:
+		runs := 0
+		for t := job.Next; !t.IsZero() && !t.After(now) && runs < maxCatchUpRuns; runs++ {
+			next, err := job.next(t)
+			if err != nil {
+				return runs + 1
+			}
+			t = next
+		}
+		return runs
+	}
+	return 0
+}
+
+func (s *Scheduler) send(ctx context.Context, job ScheduledJob) (*Message, error) {
+	body := *job.Body
+	body.DisableLinkPreview = job.DisableLinkPreview
+	if job.UserID != 0 {
+		return s.client.SendMessageToUser(ctx, job.UserID, &body)
+	}
+	return s.client.SendMessage(ctx, job.ChatID, &body)
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
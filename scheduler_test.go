@@ -0,0 +1,334 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// schedulerServer records the targets and texts of sent messages.
+func schedulerServer(t *testing.T) (*Client, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var sent []string
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body NewMessageBody
+		readJSON(t, r, &body)
+		q := r.URL.Query()
+		target := "user" + q.Get("user_id")
+		if q.Get("chat_id") != "" {
+			target = "chat" + q.Get("chat_id")
+		}
+		if q.Get("disable_link_preview") == "true" {
+			target += "!"
+		}
+		mu.Lock()
+		sent = append(sent, target+":"+body.Text.Value)
+		mu.Unlock()
+		writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: "mid"}}})
+	})
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		s := sent
+		sent = nil
+		return s
+	}
+}
+
+func TestSchedulerOneOff(t *testing.T) {
+	c, sent := schedulerServer(t)
+	s := c.NewScheduler(SchedulerOpts{})
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, ScheduledJob{
+		ChatID: 7,
+		Body:   &NewMessageBody{Text: Some("in two hours"), DisableLinkPreview: true},
+		At:     now.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID == "" || !job.Next.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("job = %+v", job)
+	}
+
+	if err := s.runDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent(); len(got) != 0 {
+		t.Errorf("sent early: %v", got)
+	}
+
+	now = now.Add(2*time.Hour + time.Second)
+	if err := s.runDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent(); len(got) != 1 || got[0] != "chat7!:in two hours" {
+		t.Errorf("sent = %v", got)
+	}
+	if jobs, _ := s.Jobs(ctx); len(jobs) != 0 {
+		t.Errorf("jobs = %+v, want one-off job removed", jobs)
+	}
+}
+
+func TestSchedulerRecurring(t *testing.T) {
+	c, sent := schedulerServer(t)
+	var runs []error
+	s := c.NewScheduler(SchedulerOpts{OnRun: func(_ ScheduledJob, _ *Message, err error) {
+		runs = append(runs, err)
+	}})
+	// Friday 08:00 in Moscow.
+	now := time.Date(2026, 10, 16, 5, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	job, err := s.Add(ctx, ScheduledJob{
+		UserID:   3,
+		Body:     &NewMessageBody{Text: Some("standup")},
+		Cron:     "0 9 * * mon-fri",
+		TimeZone: "Europe/Moscow",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC); !job.Next.Equal(want) {
+		t.Fatalf("Next = %v, want %v", job.Next, want)
+	}
+
+	now = job.Next
+	if err := s.runDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent(); len(got) != 1 || got[0] != "user3:standup" || len(runs) != 1 {
+		t.Errorf("sent = %v, runs = %v", got, runs)
+	}
+	jobs, _ := s.Jobs(ctx)
+	if want := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC); len(jobs) != 1 || !jobs[0].Next.Equal(want) {
+		t.Errorf("jobs = %+v, want next run on Monday", jobs)
+	}
+
+	if err := s.Remove(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if jobs, _ := s.Jobs(ctx); len(jobs) != 0 {
+		t.Errorf("jobs = %+v after Remove", jobs)
+	}
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy CatchUpPolicy
+		// Hourly job, down for 3 runs; then a one-off job missed by an hour.
+		wantHourly, wantOneOff int
+	}{
+		{CatchUpSkip, 0, 0},
+		{CatchUpOnce, 1, 1},
+		{CatchUpAll, 3, 1},
+	}
+	for _, tt := range tests {
+		c, sent := schedulerServer(t)
+		s := c.NewScheduler(SchedulerOpts{CatchUp: tt.policy})
+		now := start
+		s.now = func() time.Time { return now }
+		ctx := context.Background()
+
+		if _, err := s.Add(ctx, ScheduledJob{ChatID: 1, Body: &NewMessageBody{Text: Some("hourly")}, Cron: "0 * * * *"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Add(ctx, ScheduledJob{ChatID: 2, Body: &NewMessageBody{Text: Some("once")}, At: start.Add(2 * time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+
+		now = start.Add(3*time.Hour + 30*time.Minute)
+		if err := s.runDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var hourly, oneOff int
+		for _, m := range sent() {
+			switch m {
+			case "chat1:hourly":
+				hourly++
+			case "chat2:once":
+				oneOff++
+			}
+		}
+		if hourly != tt.wantHourly || oneOff != tt.wantOneOff {
+			t.Errorf("policy %d: hourly = %d, one-off = %d, want %d and %d", tt.policy, hourly, oneOff, tt.wantHourly, tt.wantOneOff)
+		}
+
+		jobs, _ := s.Jobs(ctx)
+		if len(jobs) != 1 || !jobs[0].Next.Equal(start.Add(4*time.Hour)) {
+			t.Errorf("policy %d: jobs = %+v, want hourly job due at 14:00", tt.policy, jobs)
+		}
+	}
+}
+
+func TestSchedulerFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	c, sent := schedulerServer(t)
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	store, err := OpenFileJobStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.NewScheduler(SchedulerOpts{Store: store})
+	s.now = func() time.Time { return now }
+	if _, err := s.Add(ctx, ScheduledJob{ID: "reminder", UserID: 5, Body: &NewMessageBody{Text: Some("hi")}, At: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart: a new store and scheduler see the saved job.
+	store, err = OpenFileJobStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = c.NewScheduler(SchedulerOpts{Store: store})
+	now = now.Add(time.Hour)
+	s.now = func() time.Time { return now }
+	if err := s.runDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent(); len(got) != 1 || got[0] != "user5:hi" {
+		t.Errorf("sent = %v", got)
+	}
+
+	store, err = OpenFileJobStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobs, _ := store.Jobs(ctx); len(jobs) != 0 {
+		t.Errorf("jobs = %+v, want none", jobs)
+	}
+}
+
+func TestSchedulerFileStoreKeyboard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	var mu sync.Mutex
+	var got []Keyboard
+	c, _ := testClientWithOpts(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Attachments []struct {
+				Payload Keyboard `json:"payload"`
+			} `json:"attachments"`
+		}
+		readJSON(t, r, &body)
+		mu.Lock()
+		for _, att := range body.Attachments {
+			got = append(got, att.Payload)
+		}
+		mu.Unlock()
+		writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: "mid"}}})
+	}, WithValidation())
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	rows := [][]Button{{NewCallbackButton("Yes", "yes"), NewLinkButton("Docs", "https://example.com")}}
+	store, err := OpenFileJobStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.NewScheduler(SchedulerOpts{Store: store})
+	s.now = func() time.Time { return now }
+	if _, err := s.Add(ctx, ScheduledJob{ID: "poll", ChatID: 7, At: now.Add(time.Hour), Body: &NewMessageBody{
+		Text:        Some("Ready?"),
+		Attachments: []AttachmentRequest{NewInlineKeyboardAttachment(rows)},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart and run the reloaded job with validation on.
+	store, err = OpenFileJobStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runErr error
+	s = c.NewScheduler(SchedulerOpts{Store: store, OnRun: func(_ ScheduledJob, _ *Message, err error) { runErr = err }})
+	now = now.Add(time.Hour)
+	s.now = func() time.Time { return now }
+	if err := s.runDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if runErr != nil {
+		t.Fatalf("run: %v", runErr)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Buttons, rows) {
+		t.Errorf("sent keyboards = %+v, want %+v", got, rows)
+	}
+}
+
+func TestSchedulerAddInvalid(t *testing.T) {
+	c, _ := schedulerServer(t)
+	s := c.NewScheduler(SchedulerOpts{})
+	body := &NewMessageBody{Text: Some("x")}
+	at := time.Now()
+
+	tests := []struct {
+		name  string
+		job   ScheduledJob
+		field string
+	}{
+		{"no body", ScheduledJob{ChatID: 1, At: at}, "body"},
+		{"no target", ScheduledJob{Body: body, At: at}, "chat_id"},
+		{"two targets", ScheduledJob{ChatID: 1, UserID: 2, Body: body, At: at}, "chat_id"},
+		{"no time", ScheduledJob{ChatID: 1, Body: body}, "at"},
+		{"at and cron", ScheduledJob{ChatID: 1, Body: body, At: at, Cron: "* * * * *"}, "at"},
+		{"bad cron", ScheduledJob{ChatID: 1, Body: body, Cron: "bad"}, "cron"},
+		{"bad zone", ScheduledJob{ChatID: 1, Body: body, Cron: "* * * * *", TimeZone: "Mars/Olympus"}, "cron"},
+		{"never", ScheduledJob{ChatID: 1, Body: body, Cron: "0 0 31 2 *"}, "cron"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Add(context.Background(), tt.job)
+			var e *Error
+			if !errors.As(err, &e) || e.Kind != ErrValidation || e.Field != tt.field {
+				t.Errorf("err = %v, want validation error for %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	c, sent := schedulerServer(t)
+	ran := make(chan error, 1)
+	s := c.NewScheduler(SchedulerOpts{
+		PollInterval: 5 * time.Millisecond,
+		OnRun:        func(_ ScheduledJob, _ *Message, err error) { ran <- err },
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := s.Add(ctx, ScheduledJob{ChatID: 1, Body: &NewMessageBody{Text: Some("now")}, At: time.Now().Add(20 * time.Millisecond)}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	select {
+	case err := <-ran:
+		if err != nil {
+			t.Errorf("send: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+	if got := sent(); len(got) != 1 {
+		t.Errorf("sent = %v, want one message", got)
+	}
+}
@@ -0,0 +1,463 @@
+package maxigo
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitText splits text into chunks of at most limit UTF-16 code units
+// (0 means [MaxTextLength]).
+//
+// Chunks end at the best available boundary: a paragraph break, then a line
+// break, then the end of a sentence, then a space. Surrogate pairs, HTML
+// entities and tags, markdown escapes and links are never cut. Formatting
+// that is open at a split point (e.g. <b> or **) is closed at the end of the
+// chunk and reopened at the start of the next one.
+//
+// With an empty format the text is treated as plain text.
+func SplitText(text string, format TextFormat, limit int) []string {
+	if limit <= 0 {
+		limit = MaxTextLength
+	}
+	if textLength(text) <= limit {
+		return []string{text}
+	}
+
+	pieces := tokenizeForSplit(text, format, limit)
+
+	var chunks []string
+	var stack []splitPiece
+	start := 0
+	for start < len(pieces) {
+		// Skip whitespace at the start of a chunk, unless it is code content.
+		for start < len(pieces) && pieces[start].isSpace() && !inCode(stack) {
+			start++
+		}
+		if start == len(pieces) {
+			break
+		}
+
+		prefix := openers(stack)
+		cur := stack
+		length := textLength(prefix)
+		var cands [splitAny + 1]splitCandidate
+		for i := range cands {
+			cands[i].pos = -1
+		}
+
+		i := start
+		for ; i < len(pieces); i++ {
+			next := applyPiece(cur, pieces[i])
+			nextLen := length + pieces[i].units
+			if nextLen+closersLength(next) > limit && i > start {
+				break
+			}
+			cur, length = next, nextLen
+
+			c := splitCandidate{pos: i + 1, length: length, stack: cur}
+			cands[splitAny] = c
+			if level := breakLevel(pieces, i); level < splitAny {
+				cands[level] = c
+			}
+		}
+
+		if i == len(pieces) {
+			chunks = appendChunk(chunks, prefix, pieces[start:], cur)
+			break
+		}
+
+		c := chooseCandidate(cands, limit)
+		chunks = appendChunk(chunks, prefix, pieces[start:c.pos], c.stack)
+		stack = c.stack
+		start = c.pos
+	}
+
+	return chunks
+}
+
+// SplitMessage splits a message body whose text exceeds [MaxTextLength] into
+// several bodies using [SplitText]. The format, notification and link-preview
+// settings are copied to every chunk. The reply link is kept only on the first
+// chunk; attachments, including keyboards, are kept only on the last one.
+//
+// A body that fits is returned as the only element. A nil body yields nil.
+func SplitMessage(body *NewMessageBody) []*NewMessageBody {
+	if body == nil {
+		return nil
+	}
+
+	texts := SplitText(body.Text.Value, body.Format.Value, MaxTextLength)
+	if !body.Text.Set || len(texts) <= 1 {
+		b := *body
+		return []*NewMessageBody{&b}
+	}
+
+	result := make([]*NewMessageBody, len(texts))
+	for i, text := range texts {
+		b := *body
+		b.Text = Some(text)
+		if i > 0 {
+			b.Link = nil
+		}
+		if i < len(texts)-1 {
+			b.Attachments = nil
+		}
+		result[i] = &b
+	}
+	return result
+}
+
+// SendLongMessage splits body with [SplitMessage] and sends the chunks to a
+// chat in order. On failure, the messages sent so far are returned together
+// with the error.
+func (c *Client) SendLongMessage(ctx context.Context, chatID int64, body *NewMessageBody) ([]*Message, error) {
+	chunks := SplitMessage(body)
+	if chunks == nil {
+		chunks = []*NewMessageBody{nil}
+	}
+
+	sent := make([]*Message, 0, len(chunks))
+	for _, chunk := range chunks {
+		msg, err := c.SendMessage(ctx, chatID, chunk)
+		if err != nil {
+			return sent, err
+		}
+		sent = append(sent, msg)
+	}
+	return sent, nil
+}
+
+// Split boundary levels, from most to least preferred.
+const (
+	splitParagraph = iota
+	splitLine
+	splitSentence
+	splitWord
+	splitAny
+)
+
+type splitPieceKind int
+
+const (
+	pieceText   splitPieceKind = iota // a single rune
+	pieceAtomic                       // must not be cut (entity, escape, link)
+	pieceOpen                         // opens formatting
+	pieceClose                        // closes formatting
+)
+
+// splitPiece is the smallest unit SplitText works with.
+type splitPiece struct {
+	kind   splitPieceKind
+	text   string
+	units  int
+	key    string // formatting key for open/close pieces
+	closer string // text that closes an open piece
+}
+
+func (p splitPiece) isSpace() bool {
+	if p.kind != pieceText {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(p.text)
+	return unicode.IsSpace(r)
+}
+
+type splitCandidate struct {
+	pos    int
+	length int
+	stack  []splitPiece
+}
+
+var (
+	htmlTagRe      = regexp.MustCompile(`^</?([a-zA-Z][a-zA-Z0-9]*)[^<>]*>`)
+	htmlEntityRe   = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+	markdownLinkRe = regexp.MustCompile(`^\[[^\[\]\n]*\]\([^()\s]*\)`)
+	codeFenceRe    = regexp.MustCompile("^```[^\n`]*\n?")
+)
+
+// tokenizeForSplit turns text into pieces for the given format.
+// Markdown links longer than half of limit are not kept intact, so that
+// they cannot make a chunk exceed the limit.
+func tokenizeForSplit(text string, format TextFormat, limit int) []splitPiece {
+	orig := text
+	var pieces []splitPiece
+	var stack []string // open formatting keys (markdown)
+
+	addText := func(s string) {
+		for _, r := range s {
+			rs := string(r)
+			pieces = append(pieces, splitPiece{kind: pieceText, text: rs, units: textLength(rs)})
+		}
+	}
+	add := func(p splitPiece) {
+		p.units = textLength(p.text)
+		pieces = append(pieces, p)
+	}
+
+	for len(text) > 0 {
+		switch format {
+		case FormatHTML:
+			if m := htmlTagRe.FindStringSubmatch(text); m != nil {
+				name := strings.ToLower(m[1])
+				switch {
+				case strings.HasPrefix(m[0], "</"):
+					add(splitPiece{kind: pieceClose, text: m[0], key: name})
+				case strings.HasSuffix(m[0], "/>") || name == "br":
+					add(splitPiece{kind: pieceAtomic, text: m[0]})
+				default:
+					add(splitPiece{kind: pieceOpen, text: m[0], key: name, closer: "</" + name + ">"})
+				}
+				text = text[len(m[0]):]
+				continue
+			}
+			if m := htmlEntityRe.FindString(text); m != "" {
+				add(splitPiece{kind: pieceAtomic, text: m})
+				text = text[len(m):]
+				continue
+			}
+
+		case FormatMarkdown:
+			inCode := len(stack) > 0 && isCodeKey(stack[len(stack)-1])
+
+			if inCode {
+				key := stack[len(stack)-1]
+				if strings.HasPrefix(text, key) {
+					add(splitPiece{kind: pieceClose, text: key, key: key})
+					stack = stack[:len(stack)-1]
+					text = text[len(key):]
+					continue
+				}
+				if strings.HasPrefix(text, `\`) && len(text) > 1 {
+					_, size := utf8.DecodeRuneInString(text[1:])
+					add(splitPiece{kind: pieceAtomic, text: text[:1+size]})
+					text = text[1+size:]
+					continue
+				}
+				break
+			}
+
+			if strings.HasPrefix(text, `\`) && len(text) > 1 {
+				_, size := utf8.DecodeRuneInString(text[1:])
+				add(splitPiece{kind: pieceAtomic, text: text[:1+size]})
+				text = text[1+size:]
+				continue
+			}
+			if m := codeFenceRe.FindString(text); m != "" {
+				add(splitPiece{kind: pieceOpen, text: m, key: "```", closer: "\n```"})
+				stack = append(stack, "```")
+				text = text[len(m):]
+				continue
+			}
+			if m := markdownLinkRe.FindString(text); m != "" && textLength(m) <= limit/2 {
+				add(splitPiece{kind: pieceAtomic, text: m})
+				text = text[len(m):]
+				continue
+			}
+
+			matched := false
+			for _, marker := range []string{"**", "~~", "++", "_", "`"} {
+				if !strings.HasPrefix(text, marker) {
+					continue
+				}
+				idx := lastIndexOf(stack, marker)
+				if marker == "_" && !underscoreMarker(orig[:len(orig)-len(text)], text[1:], idx >= 0) {
+					continue
+				}
+				if idx >= 0 {
+					add(splitPiece{kind: pieceClose, text: marker, key: marker})
+					stack = append(stack[:idx], stack[idx+1:]...)
+				} else {
+					add(splitPiece{kind: pieceOpen, text: marker, key: marker, closer: marker})
+					stack = append(stack, marker)
+				}
+				text = text[len(marker):]
+				matched = true
+				break
+			}
+			if matched {
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(text)
+		addText(text[:size])
+		text = text[size:]
+	}
+
+	return pieces
+}
+
+// underscoreMarker reports whether an underscore between before and after
+// opens (or, if closing, closes) italic. Like in Max markdown, an underscore
+// inside a word, as in snake_case, is plain text.
+func underscoreMarker(before, after string, closing bool) bool {
+	if closing {
+		r, _ := utf8.DecodeRuneInString(after)
+		return after == "" || !isWordRune(r)
+	}
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return before == "" || !isWordRune(r)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func lastIndexOf(stack []string, key string) int {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyPiece returns the formatting stack after p. The input is not modified.
+func applyPiece(stack []splitPiece, p splitPiece) []splitPiece {
+	switch p.kind {
+	case pieceOpen:
+		next := make([]splitPiece, len(stack), len(stack)+1)
+		copy(next, stack)
+		return append(next, p)
+	case pieceClose:
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].key == p.key {
+				next := make([]splitPiece, 0, len(stack)-1)
+				next = append(next, stack[:i]...)
+				return append(next, stack[i+1:]...)
+			}
+		}
+	}
+	return stack
+}
+
+func openers(stack []splitPiece) string {
+	var sb strings.Builder
+	for _, p := range stack {
+		sb.WriteString(p.text)
+	}
+	return sb.String()
+}
+
+func closers(stack []splitPiece) string {
+	var sb strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		sb.WriteString(stack[i].closer)
+	}
+	return sb.String()
+}
+
+func closersLength(stack []splitPiece) int {
+	n := 0
+	for _, p := range stack {
+		n += textLength(p.closer)
+	}
+	return n
+}
+
+// breakLevel returns the boundary level of the position right after pieces[i].
+func breakLevel(pieces []splitPiece, i int) int {
+	p := pieces[i]
+	if p.kind != pieceText {
+		return splitAny
+	}
+	switch p.text {
+	case "\n":
+		if i > 0 && pieces[i-1].text == "\n" {
+			return splitParagraph
+		}
+		return splitLine
+	case " ", "\t":
+		if i > 0 && pieces[i-1].kind == pieceText && strings.ContainsAny(pieces[i-1].text, ".!?…") {
+			return splitSentence
+		}
+		return splitWord
+	}
+	return splitAny
+}
+
+// chooseCandidate picks the most preferred boundary that leaves the chunk at
+// least half full. Otherwise it takes the latest natural boundary, and as a
+// last resort cuts right before the piece that did not fit.
+func chooseCandidate(cands [splitAny + 1]splitCandidate, limit int) splitCandidate {
+	for level := splitParagraph; level < splitAny; level++ {
+		if cands[level].pos >= 0 && cands[level].length >= limit/2 {
+			return cands[level]
+		}
+	}
+
+	best := cands[splitAny]
+	natural := splitCandidate{pos: -1}
+	for level := splitParagraph; level < splitAny; level++ {
+		if cands[level].pos > natural.pos {
+			natural = cands[level]
+		}
+	}
+	if natural.pos >= 0 {
+		return natural
+	}
+	return best
+}
+
+// isCodeKey reports whether a formatting key is code, whose whitespace is
+// content.
+func isCodeKey(key string) bool {
+	switch key {
+	case "```", "`", "pre", "code":
+		return true
+	}
+	return false
+}
+
+// inCode reports whether stack has an open code span or block.
+func inCode(stack []splitPiece) bool {
+	for _, p := range stack {
+		if isCodeKey(p.key) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendChunk renders a chunk and appends it unless it is blank. Trailing
+// whitespace is dropped, including whitespace right before closing markers,
+// which Max would not render as formatting otherwise. Inside code the
+// whitespace is content and is kept.
+func appendChunk(chunks []string, prefix string, pieces []splitPiece, stack []splitPiece) []string {
+	if inCode(stack) {
+		var sb strings.Builder
+		for _, p := range pieces {
+			sb.WriteString(p.text)
+		}
+		body, tail := sb.String(), closers(stack)
+		if strings.HasSuffix(body, "\n") && strings.HasPrefix(tail, "\n") {
+			// A code fence is closed on its own line already.
+			tail = tail[1:]
+		}
+		return append(chunks, prefix+body+tail)
+	}
+
+	end := len(pieces)
+	var tail strings.Builder
+	for end > 0 && (pieces[end-1].isSpace() || pieces[end-1].kind == pieceClose && !isCodeKey(pieces[end-1].key)) {
+		end--
+	}
+	for _, p := range pieces[end:] {
+		if p.kind == pieceClose {
+			tail.WriteString(p.text)
+		}
+	}
+
+	var sb strings.Builder
+	for _, p := range pieces[:end] {
+		sb.WriteString(p.text)
+	}
+	body := strings.TrimRightFunc(sb.String(), unicode.IsSpace) + tail.String()
+	if strings.TrimSpace(body) == "" && prefix == "" {
+		return chunks
+	}
+	return append(chunks, prefix+body+closers(stack))
+}
@@ -0,0 +1,281 @@
+package maxigo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestSplitTextShort(t *testing.T) {
+	got := SplitText("hello", "", 10)
+	if len(got) != 1 || got[0] != "hello" {
+		t.Errorf("SplitText() = %q, want [hello]", got)
+	}
+}
+
+func TestSplitTextBoundaries(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "paragraph preferred over line",
+			text:  "aaaa bbbb\n\ncccc\ndddd eeee",
+			limit: 16,
+			want:  []string{"aaaa bbbb", "cccc\ndddd eeee"},
+		},
+		{
+			name:  "sentence preferred over word",
+			text:  "One two. Three four five",
+			limit: 14,
+			want:  []string{"One two.", "Three four", "five"},
+		},
+		{
+			name:  "word boundary",
+			text:  "alpha beta gamma delta",
+			limit: 11,
+			want:  []string{"alpha beta", "gamma delta"},
+		},
+		{
+			name:  "hard cut without spaces",
+			text:  strings.Repeat("x", 25),
+			limit: 10,
+			want:  []string{strings.Repeat("x", 10), strings.Repeat("x", 10), strings.Repeat("x", 5)},
+		},
+		{
+			name:  "surrogate pairs are not cut",
+			text:  strings.Repeat("😀", 5),
+			limit: 3,
+			want:  []string{"😀", "😀", "😀", "😀", "😀"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.text, "", tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+			for _, chunk := range got {
+				if n := textLength(chunk); n > tt.limit {
+					t.Errorf("chunk %q has length %d > %d", chunk, n, tt.limit)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTextHTML(t *testing.T) {
+	text := "<b>bold text that is long</b> &amp; more"
+	got := SplitText(text, FormatHTML, 20)
+	for _, chunk := range got {
+		if textLength(chunk) > 20 {
+			t.Errorf("chunk %q exceeds limit", chunk)
+		}
+		if strings.Count(chunk, "<b>") != strings.Count(chunk, "</b>") {
+			t.Errorf("chunk %q has unbalanced <b>", chunk)
+		}
+		if strings.Contains(chunk, "&") && !strings.Contains(chunk, "&amp;") {
+			t.Errorf("chunk %q cuts an entity", chunk)
+		}
+	}
+	want := []string{"<b>bold text</b>", "<b>that is long</b>", "&amp; more"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSplitTextMarkdown(t *testing.T) {
+	text := "**bold words here and there and everywhere you look** then [a link](https://ex.com) and \\* star"
+	got := SplitText(text, FormatMarkdown, 50)
+	want := []string{
+		"**bold words here and there and everywhere you**",
+		`**look** then [a link](https://ex.com) and \* star`,
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	// Escapes are never cut.
+	for _, chunk := range SplitText(strings.Repeat(`\*`, 20), FormatMarkdown, 7) {
+		if chunk != `\*\*\*` && chunk != `\*\*` {
+			t.Errorf("chunk %q cuts an escape", chunk)
+		}
+	}
+}
+
+func TestSplitTextMarkdownItalic(t *testing.T) {
+	text := "_" + strings.Repeat("word ", 30) + "_"
+	got := SplitText(text, FormatMarkdown, 50)
+	if len(got) < 2 {
+		t.Fatalf("expected several chunks, got %q", got)
+	}
+	for _, chunk := range got {
+		if !strings.HasPrefix(chunk, "_word") || !strings.HasSuffix(chunk, "word_") {
+			t.Errorf("chunk %q is not a complete italic span", chunk)
+		}
+		if textLength(chunk) > 50 {
+			t.Errorf("chunk %q exceeds limit", chunk)
+		}
+	}
+}
+
+func TestSplitTextMarkdownTrailingSpace(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"bold", "**" + strings.Repeat("word ", 30) + "**"},
+		{"nested", "**~~" + strings.Repeat("word ", 30) + "~~**"},
+		{"double spaces", "**" + strings.Repeat("word  ", 30) + "**"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, chunk := range SplitText(tt.text, FormatMarkdown, 40) {
+				if body := strings.TrimRight(chunk, "*~"); strings.TrimRightFunc(body, unicode.IsSpace) != body {
+					t.Errorf("chunk %q has whitespace before its closing markers", chunk)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTextMarkdownLongLink(t *testing.T) {
+	// A link longer than the limit cannot stay intact; chunks must still fit.
+	text := "see [docs](https://example.com/a/very/long/path/to/the/page) now"
+	for _, chunk := range SplitText(text, FormatMarkdown, 20) {
+		if textLength(chunk) > 20 {
+			t.Errorf("chunk %q exceeds limit", chunk)
+		}
+	}
+}
+
+func TestSplitTextMarkdownCodeBlock(t *testing.T) {
+	text := "```go\nline one\nline two\nline three\n```"
+	got := SplitText(text, FormatMarkdown, 26)
+	if len(got) < 2 {
+		t.Fatalf("expected several chunks, got %q", got)
+	}
+	for _, chunk := range got {
+		if !strings.HasPrefix(chunk, "```go\n") || !strings.HasSuffix(chunk, "\n```") {
+			t.Errorf("chunk %q is not a complete code block", chunk)
+		}
+		if textLength(chunk) > 26 {
+			t.Errorf("chunk %q exceeds limit", chunk)
+		}
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	keyboard := NewInlineKeyboardAttachment([][]Button{{NewCallbackButton("OK", "ok")}})
+	body := &NewMessageBody{
+		Text:        Some(strings.Repeat("word ", MaxTextLength/5*2)),
+		Format:      Some(FormatMarkdown),
+		Link:        &NewMessageLink{Type: LinkReply, MID: "mid-1"},
+		Attachments: []AttachmentRequest{keyboard},
+		Notify:      Some(false),
+	}
+
+	chunks := SplitMessage(body)
+	if len(chunks) != 2 {
+		t.Fatalf("len(chunks) = %d, want 2", len(chunks))
+	}
+	if chunks[0].Link == nil || chunks[1].Link != nil {
+		t.Error("reply link should be kept only on the first chunk")
+	}
+	if len(chunks[0].Attachments) != 0 || len(chunks[1].Attachments) != 1 {
+		t.Error("keyboard should be kept only on the last chunk")
+	}
+	for i, c := range chunks {
+		if c.Format.Value != FormatMarkdown || c.Notify != Some(false) {
+			t.Errorf("chunk %d lost format or notify: %+v", i, c)
+		}
+		if textLength(c.Text.Value) > MaxTextLength {
+			t.Errorf("chunk %d exceeds MaxTextLength", i)
+		}
+	}
+	if len(body.Attachments) != 1 || body.Link == nil {
+		t.Error("original body must not be modified")
+	}
+}
+
+func TestSendLongMessage(t *testing.T) {
+	var texts []string
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body NewMessageBody
+		readJSON(t, r, &body)
+		texts = append(texts, body.Text.Value)
+		writeJSON(t, w, sendMessageResult{Message: Message{Body: MessageBody{MID: "mid"}}})
+	})
+
+	text := strings.Repeat("a", MaxTextLength) + "\n\n" + "tail"
+	msgs, err := c.SendLongMessage(context.Background(), 1, &NewMessageBody{Text: Some(text)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msgs) != 2 || len(texts) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(texts))
+	}
+	if texts[1] != "tail" {
+		t.Errorf("second chunk = %q, want tail", texts[1])
+	}
+}
+
+func TestSplitTextMarkdownSnakeCase(t *testing.T) {
+	// Underscores inside words are not italic markers, so no chunk gets
+	// stray "_" closers or openers.
+	text := strings.Repeat("call some_long_function_name now ", 6)
+	got := SplitText(text, FormatMarkdown, 40)
+	if len(got) < 2 {
+		t.Fatalf("expected several chunks, got %q", got)
+	}
+	if joined := strings.Join(got, " "); joined != strings.TrimSpace(text) {
+		t.Errorf("chunks changed the text:\ngot  %q\nwant %q", joined, strings.TrimSpace(text))
+	}
+
+	// A real italic span still works next to snake_case.
+	got = SplitText("_"+strings.Repeat("a_b ", 20)+"_", FormatMarkdown, 30)
+	for _, chunk := range got {
+		if !strings.HasPrefix(chunk, "_a_b") || !strings.HasSuffix(chunk, "a_b_") {
+			t.Errorf("chunk %q is not a complete italic span", chunk)
+		}
+	}
+}
+
+func TestSplitTextMarkdownIndentedCode(t *testing.T) {
+	// Indentation and trailing spaces inside a code block are content and
+	// survive at chunk boundaries.
+	line := "    x := 1  "
+	text := "```\n" + strings.Repeat(line+"\n", 8) + "```"
+	got := SplitText(text, FormatMarkdown, 40)
+	if len(got) < 2 {
+		t.Fatalf("expected several chunks, got %q", got)
+	}
+	lines := 0
+	for _, chunk := range got {
+		if textLength(chunk) > 40 {
+			t.Errorf("chunk %q exceeds limit", chunk)
+		}
+		body, ok := strings.CutPrefix(chunk, "```\n")
+		if !ok {
+			t.Fatalf("chunk %q does not open the code block", chunk)
+		}
+		body, ok = strings.CutSuffix(body, "\n```")
+		if !ok {
+			t.Fatalf("chunk %q does not close the code block", chunk)
+		}
+		for _, l := range strings.Split(body, "\n") {
+			if l != line {
+				t.Errorf("chunk %q has line %q, want %q", chunk, l, line)
+			}
+			lines++
+		}
+	}
+	if lines != 8 {
+		t.Errorf("got %d code lines, want 8", lines)
+	}
+}
@@ -32,6 +32,8 @@ type GetUpdatesOpts struct {
 // GetUpdates fetches updates using long polling.
 // Corresponds to GET /updates.
 //
+// Updates are passed to [Cache.Observe] when [WithCache] is enabled.
+//
 // The client automatically adjusts the HTTP timeout to accommodate the
 // server-side long-polling duration, preventing spurious timeout errors.
 func (c *Client) GetUpdates(ctx context.Context, opts GetUpdatesOpts) (*UpdateList, error) {
@@ -69,5 +71,8 @@ func (c *Client) GetUpdates(ctx context.Context, opts GetUpdatesOpts) (*UpdateLi
 	if err := c.do(ctx, "GetUpdates", http.MethodGet, "/updates", q, nil, &result); err != nil {
 		return nil, err
 	}
+	for _, update := range result.Updates {
+		_ = c.cache.Observe(update)
+	}
 	return &result, nil
 }
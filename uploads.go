@@ -1,11 +1,13 @@
 package maxigo
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime"
 	"net/http"
 	"net/url"
@@ -40,7 +42,19 @@ func (c *Client) GetUploadURL(ctx context.Context, uploadType UploadType) (*Uplo
 
 // UploadPhoto uploads an image and returns photo tokens.
 // This is a two-step operation: get upload URL, then upload the file.
+//
+// If the client was created with [WithImagePreprocessing], the image is
+// converted to JPEG first and the filename extension is changed to ".jpg".
 func (c *Client) UploadPhoto(ctx context.Context, filename string, reader io.Reader) (*PhotoTokens, error) {
+	if c.imageOpts != nil {
+		data, err := PreprocessImage(reader, *c.imageOpts)
+		if err != nil {
+			return nil, decodeError("UploadPhoto", err)
+		}
+		reader = bytes.NewReader(data)
+		filename = jpegFilename(filename)
+	}
+
 	endpoint, err := c.GetUploadURL(ctx, UploadImage)
 	if err != nil {
 		return nil, err
@@ -60,6 +74,10 @@ func (c *Client) UploadPhoto(ctx context.Context, filename string, reader io.Rea
 
 // UploadMedia uploads a video, audio, or file and returns the token.
 // This is a two-step operation: get upload URL, then upload the file.
+//
+// For video and audio, the Max API issues the token together with the upload
+// URL ([UploadEndpoint.Token]); it is preferred over the upload server's
+// response, which may be empty or not JSON for these types.
 func (c *Client) UploadMedia(ctx context.Context, uploadType UploadType, filename string, reader io.Reader) (*UploadedInfo, error) {
 	endpoint, err := c.GetUploadURL(ctx, uploadType)
 	if err != nil {
@@ -71,6 +89,13 @@ func (c *Client) UploadMedia(ctx context.Context, uploadType UploadType, filenam
 		return nil, err
 	}
 
+	if (uploadType == UploadVideo || uploadType == UploadAudio) && endpoint.Token != nil && *endpoint.Token != "" {
+		return &UploadedInfo{Token: *endpoint.Token}, nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, decodeError("UploadMedia", errors.New("empty upload response and no token in upload endpoint"))
+	}
+
 	var result UploadedInfo
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, decodeError("UploadMedia", fmt.Errorf("unmarshal upload response: %w", err))
@@ -86,7 +111,7 @@ func (c *Client) UploadPhotoFromFile(ctx context.Context, filePath string) (*Pho
 	}
 	defer func() { _ = f.Close() }()
 
-	return c.UploadPhoto(ctx, filepath.Base(filePath), f)
+	return c.UploadPhoto(ctx, uploadFilename(filePath), f)
 }
 
 // UploadMediaFromFile opens a local file and uploads it as the given media type.
@@ -97,7 +122,59 @@ func (c *Client) UploadMediaFromFile(ctx context.Context, uploadType UploadType,
 	}
 	defer func() { _ = f.Close() }()
 
-	return c.UploadMedia(ctx, uploadType, filepath.Base(filePath), f)
+	return c.UploadMedia(ctx, uploadType, uploadFilename(filePath), f)
+}
+
+// UploadPhotoFromFS opens a file from fsys (e.g. an [embed.FS]) and uploads it as a photo.
+func (c *Client) UploadPhotoFromFS(ctx context.Context, fsys fs.FS, name string) (*PhotoTokens, error) {
+	f, err := openFS("UploadPhotoFromFS", fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return c.UploadPhoto(ctx, uploadFilename(name), f)
+}
+
+// UploadMediaFromFS opens a file from fsys (e.g. an [embed.FS]) and uploads it
+// as the given media type.
+func (c *Client) UploadMediaFromFS(ctx context.Context, uploadType UploadType, fsys fs.FS, name string) (*UploadedInfo, error) {
+	f, err := openFS("UploadMediaFromFS", fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return c.UploadMedia(ctx, uploadType, uploadFilename(name), f)
+}
+
+// openFS stats and opens a regular file from fsys. The returned reader is
+// limited to the size reported by [fs.Stat]. Caller must close it.
+func openFS(op string, fsys fs.FS, name string) (io.ReadCloser, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, &Error{Kind: ErrFetch, Op: op, Message: err.Error(), Err: err}
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fetchError(op, 0, fmt.Sprintf("%s: not a regular file", name))
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, &Error{Kind: ErrFetch, Op: op, Message: err.Error(), Err: err}
+	}
+	return readCloser{io.NopCloser(io.LimitReader(f, info.Size())), f}, nil
+}
+
+// uploadFilename returns the base name of a local or fs.FS path for use as
+// the upload filename. Directory components are stripped to avoid leaking
+// local paths.
+func uploadFilename(name string) string {
+	base := filepath.Base(filepath.FromSlash(name))
+	if base == "." || base == string(filepath.Separator) {
+		return "file"
+	}
+	return base
 }
 
 // UploadPhotoFromURL fetches an image from a URL and uploads it as a photo.
@@ -109,7 +186,7 @@ func (c *Client) UploadPhotoFromURL(ctx context.Context, imageURL string) (*Phot
 	ctx, cancel := c.ensureTimeout(ctx)
 	defer cancel()
 
-	body, filename, err := c.fetchURL(ctx, "UploadPhotoFromURL", imageURL)
+	body, filename, err := c.fetchURL(ctx, "UploadPhotoFromURL", imageURL, maxFetchSize)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +207,7 @@ func (c *Client) UploadMediaFromURL(ctx context.Context, uploadType UploadType,
 	ctx, cancel := c.ensureTimeout(ctx)
 	defer cancel()
 
-	body, filename, err := c.fetchURL(ctx, "UploadMediaFromURL", fileURL)
+	body, filename, err := c.fetchURL(ctx, "UploadMediaFromURL", fileURL, maxFetchSize)
 	if err != nil {
 		return nil, err
 	}
@@ -142,12 +219,13 @@ func (c *Client) UploadMediaFromURL(ctx context.Context, uploadType UploadType,
 	return c.UploadMedia(ctx, uploadType, filename, body)
 }
 
-// maxFetchSize is the maximum number of bytes fetchURL will read (50 MB).
+// maxFetchSize is the maximum number of bytes the FromURL helpers will read (50 MB).
 const maxFetchSize = 50 << 20
 
 // fetchURL downloads content from the URL. Caller must close the body.
+// At most limit bytes are read from the response.
 // Only http and https schemes are allowed.
-func (c *Client) fetchURL(ctx context.Context, op, rawURL string) (io.ReadCloser, string, error) {
+func (c *Client) fetchURL(ctx context.Context, op, rawURL string, limit int64) (io.ReadCloser, string, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, "", networkError(op, fmt.Errorf("parse URL: %w", err))
@@ -177,14 +255,14 @@ func (c *Client) fetchURL(ctx context.Context, op, rawURL string) (io.ReadCloser
 		return nil, "", fetchError(op, resp.StatusCode, fmt.Sprintf("fetch %s: %s", rawURL, http.StatusText(resp.StatusCode)))
 	}
 
-	limited := io.NopCloser(io.LimitReader(resp.Body, maxFetchSize))
+	limited := io.NopCloser(io.LimitReader(resp.Body, limit))
 	body := readCloser{limited, resp.Body}
 	return body, extractFilename(resp, rawURL), nil
 }
 
 // readCloser combines a limited reader with the original closer.
 type readCloser struct {
-	io.ReadCloser        // limited reader (reads up to maxFetchSize)
+	io.ReadCloser        // limited reader
 	underlying    io.Closer // original resp.Body
 }
 
@@ -3,12 +3,14 @@ package maxigo
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -279,6 +281,124 @@ func TestUploadMediaUnmarshalError(t *testing.T) {
 	}
 }
 
+// fakeUploadServer mimics the Max upload flow: POST /uploads returns an upload
+// URL (and a token for video and audio), the upload server replies with
+// uploadBody. Test helper.
+func fakeUploadServer(t *testing.T, endpointToken *string, uploadBody string) *Client {
+	t.Helper()
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/uploads":
+			writeJSON(t, w, UploadEndpoint{URL: "http://" + r.Host + "/do-upload", Token: endpointToken})
+		case "/do-upload":
+			if _, _, err := r.FormFile("data"); err != nil {
+				t.Errorf("form file: %v", err)
+			}
+			_, _ = w.Write([]byte(uploadBody))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	return c
+}
+
+func TestUploadMediaEndpointToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		uploadType    UploadType
+		endpointToken *string
+		uploadBody    string
+		wantToken     string
+		wantKind      ErrorKind
+		wantErr       bool
+	}{
+		{
+			name:          "video with endpoint token and empty response",
+			uploadType:    UploadVideo,
+			endpointToken: strPtr("video-endpoint-tok"),
+			uploadBody:    "",
+			wantToken:     "video-endpoint-tok",
+		},
+		{
+			name:          "video endpoint token wins over response",
+			uploadType:    UploadVideo,
+			endpointToken: strPtr("video-endpoint-tok"),
+			uploadBody:    `{"token":"video-body-tok"}`,
+			wantToken:     "video-endpoint-tok",
+		},
+		{
+			name:          "audio with endpoint token and non-JSON response",
+			uploadType:    UploadAudio,
+			endpointToken: strPtr("audio-endpoint-tok"),
+			uploadBody:    "<retval>1</retval>",
+			wantToken:     "audio-endpoint-tok",
+		},
+		{
+			name:       "audio without endpoint token falls back to response",
+			uploadType: UploadAudio,
+			uploadBody: `{"token":"audio-body-tok"}`,
+			wantToken:  "audio-body-tok",
+		},
+		{
+			name:          "file ignores endpoint token",
+			uploadType:    UploadFile,
+			endpointToken: strPtr("ignored"),
+			uploadBody:    `{"token":"file-body-tok"}`,
+			wantToken:     "file-body-tok",
+		},
+		{
+			name:       "file with empty response",
+			uploadType: UploadFile,
+			uploadBody: "  ",
+			wantErr:    true,
+			wantKind:   ErrDecode,
+		},
+		{
+			name:       "video without any token",
+			uploadType: UploadVideo,
+			uploadBody: "",
+			wantErr:    true,
+			wantKind:   ErrDecode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fakeUploadServer(t, tt.endpointToken, tt.uploadBody)
+
+			result, err := c.UploadMedia(context.Background(), tt.uploadType, "media.bin", strings.NewReader("data"))
+			if tt.wantErr {
+				var e *Error
+				if !errors.As(err, &e) {
+					t.Fatalf("expected *Error, got %T (%v)", err, err)
+				}
+				if e.Kind != tt.wantKind {
+					t.Errorf("Kind = %v, want %v", e.Kind, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Token != tt.wantToken {
+				t.Errorf("Token = %q, want %q", result.Token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestUploadPhotoIgnoresEndpointToken(t *testing.T) {
+	c := fakeUploadServer(t, strPtr("ignored"), `{"photos":{"default":{"token":"image-tok"}}}`)
+
+	result, err := c.UploadPhoto(context.Background(), "photo.jpg", strings.NewReader("data"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Photos["default"].Token != "image-tok" {
+		t.Errorf("token = %q, want %q", result.Photos["default"].Token, "image-tok")
+	}
+}
+
 func TestUploadPhotoFromFile(t *testing.T) {
 	tmp := filepath.Join(t.TempDir(), "test-image.jpg")
 	if err := os.WriteFile(tmp, []byte("fake image data"), 0o644); err != nil {
@@ -358,6 +478,124 @@ func TestUploadMediaFromFile(t *testing.T) {
 	}
 }
 
+func TestUploadPhotoFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/logo.png": &fstest.MapFile{Data: []byte("fake image data")},
+	}
+
+	var requestCount atomic.Int32
+	var uploadedFilename, uploadedData string
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		count := requestCount.Add(1)
+		if count == 1 {
+			writeJSON(t, w, UploadEndpoint{URL: "http://" + r.Host + "/do-upload"})
+			return
+		}
+		if f, fh, err := r.FormFile("data"); err == nil {
+			uploadedFilename = fh.Filename
+			data, _ := io.ReadAll(f)
+			uploadedData = string(data)
+			_ = f.Close()
+		}
+		writeJSON(t, w, PhotoTokens{
+			Photos: map[string]PhotoToken{"default": {Token: "tok-from-fs"}},
+		})
+	})
+
+	result, err := c.UploadPhotoFromFS(context.Background(), fsys, "assets/logo.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Photos["default"].Token != "tok-from-fs" {
+		t.Errorf("token = %q, want %q", result.Photos["default"].Token, "tok-from-fs")
+	}
+	if uploadedFilename != "logo.png" {
+		t.Errorf("filename = %q, want %q", uploadedFilename, "logo.png")
+	}
+	if uploadedData != "fake image data" {
+		t.Errorf("data = %q, want %q", uploadedData, "fake image data")
+	}
+}
+
+func TestUploadMediaFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"clip.mp4": &fstest.MapFile{Data: []byte("fake video data")},
+	}
+
+	var requestCount atomic.Int32
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		count := requestCount.Add(1)
+		if count == 1 {
+			writeJSON(t, w, UploadEndpoint{URL: "http://" + r.Host + "/do-upload"})
+			return
+		}
+		writeJSON(t, w, UploadedInfo{Token: "media-from-fs"})
+	})
+
+	result, err := c.UploadMediaFromFS(context.Background(), UploadVideo, fsys, "clip.mp4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Token != "media-from-fs" {
+		t.Errorf("Token = %q, want %q", result.Token, "media-from-fs")
+	}
+}
+
+func TestUploadFromFSErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/file.txt": &fstest.MapFile{Data: []byte("x")},
+	}
+	c, _ := testClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		t.Fatal("no HTTP request should be made")
+	})
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"photo not found", func() error {
+			_, err := c.UploadPhotoFromFS(context.Background(), fsys, "missing.png")
+			return err
+		}},
+		{"media not found", func() error {
+			_, err := c.UploadMediaFromFS(context.Background(), UploadFile, fsys, "missing.txt")
+			return err
+		}},
+		{"directory", func() error {
+			_, err := c.UploadMediaFromFS(context.Background(), UploadFile, fsys, "dir")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *Error, got %T", err)
+			}
+			if e.Kind != ErrFetch {
+				t.Errorf("Kind = %v, want ErrFetch", e.Kind)
+			}
+		})
+	}
+}
+
+func TestUploadFilename(t *testing.T) {
+	tests := map[string]string{
+		"photo.jpg":            "photo.jpg",
+		"assets/img/logo.png":  "logo.png",
+		"/var/data/report.pdf": "report.pdf",
+		".":                    "file",
+		"":                     "file",
+	}
+	for in, want := range tests {
+		if got := uploadFilename(in); got != want {
+			t.Errorf("uploadFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestUploadPhotoFromURL(t *testing.T) {
 	var requestCount atomic.Int32
 	c, srv := testClient(t, func(w http.ResponseWriter, r *http.Request) {
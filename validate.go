@@ -0,0 +1,322 @@
+package maxigo
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxBotCommands is the maximum number of bot commands (see [BotInfo.Commands]).
+const maxBotCommands = 32
+
+// maxBotDescriptionLength is the maximum bot description length.
+const maxBotDescriptionLength = 16000
+
+// knownPermissions lists all [ChatAdminPermission] values.
+var knownPermissions = map[ChatAdminPermission]bool{
+	PermReadAllMessages:       true,
+	PermAddRemoveMembers:      true,
+	PermAddAdmins:             true,
+	PermChangeChatInfo:        true,
+	PermPinMessage:            true,
+	PermWrite:                 true,
+	PermCanCall:               true,
+	PermEditLink:              true,
+	PermPostEditDeleteMessage: true,
+	PermEditMessage:           true,
+	PermDeleteMessage:         true,
+}
+
+// Validate checks a message body before it is sent: it must have text,
+// attachments or a forwarded message, the text must fit [MaxTextLength], the format must be known,
+// and keyboards and buttons must be within Max limits.
+//
+// The returned error is an [*Error] with Kind [ErrValidation] and the path
+// of the invalid field in Error.Field.
+func (b *NewMessageBody) Validate() error {
+	return finishValidation("Validate", b.validate(true))
+}
+
+// Validate checks a single button: its text, type-specific fields
+// (callback payload, link URL, etc.) and their length limits.
+func (b Button) Validate() error {
+	return finishValidation("Validate", b.validate())
+}
+
+// Validate checks that a callback answer has a message or a notification
+// and that the message is valid.
+func (a *CallbackAnswer) Validate() error {
+	return finishValidation("Validate", a.validate())
+}
+
+// Validate checks that a chat patch changes at least one field.
+func (p *ChatPatch) Validate() error {
+	return finishValidation("Validate", p.validate())
+}
+
+// Validate checks that a bot patch changes at least one field and that
+// commands and description are within limits.
+func (p *BotPatch) Validate() error {
+	return finishValidation("Validate", p.validate())
+}
+
+// Validate checks that the list has admins with user IDs and known permissions.
+func (l *ChatAdminsList) Validate() error {
+	return finishValidation("Validate", l.validate())
+}
+
+// validate checks the body. If requireContent is false (edits and callback
+// answers), a body without text and attachments is allowed.
+func (b *NewMessageBody) validate(requireContent bool) *Error {
+	if b == nil {
+		if requireContent {
+			return invalid("", "message body is required")
+		}
+		return nil
+	}
+
+	forward := b.Link != nil && b.Link.Type == LinkForward
+	if requireContent && b.Text.Value == "" && len(b.Attachments) == 0 && !forward {
+		return invalid("text", "message must have text or attachments")
+	}
+	if n := textLength(b.Text.Value); n > MaxTextLength {
+		return invalid("text", "is %d characters, limit is %d", n, MaxTextLength)
+	}
+	if b.Format.Set && b.Format.Value != FormatMarkdown && b.Format.Value != FormatHTML {
+		return invalid("format", "unknown format %q", b.Format.Value)
+	}
+	if b.Link != nil {
+		if b.Link.Type != LinkReply && b.Link.Type != LinkForward {
+			return invalid("link.type", "unknown link type %q", b.Link.Type)
+		}
+		if b.Link.MID == "" {
+			return invalid("link.mid", "must not be empty")
+		}
+	}
+	for i, att := range b.Attachments {
+		if err := att.validate(); err != nil {
+			return prefixField(err, fmt.Sprintf("attachments[%d]", i))
+		}
+	}
+	return nil
+}
+
+func (a AttachmentRequest) validate() *Error {
+	switch a.Type {
+	case "":
+		return invalid("type", "must not be empty")
+	case "inline_keyboard":
+		var rows [][]Button
+		switch kb := a.Payload.(type) {
+		case Keyboard:
+			rows = kb.Buttons
+		case *Keyboard:
+			if kb != nil {
+				rows = kb.Buttons
+			}
+		}
+		if err := validateButtons(rows); err != nil {
+			return prefixField(err, "payload")
+		}
+	case "location":
+		if a.Latitude < -90 || a.Latitude > 90 {
+			return invalid("latitude", "must be between -90 and 90")
+		}
+		if a.Longitude < -180 || a.Longitude > 180 {
+			return invalid("longitude", "must be between -180 and 180")
+		}
+	case "reply_keyboard":
+		if len(a.Buttons) == 0 {
+			return invalid("buttons", "must not be empty")
+		}
+	default:
+		if a.Payload == nil {
+			return invalid("payload", "must not be empty")
+		}
+	}
+	return nil
+}
+
+// validateButtons checks an inline keyboard layout against Max limits.
+func validateButtons(rows [][]Button) *Error {
+	if len(rows) == 0 {
+		return invalid("buttons", "keyboard has no buttons")
+	}
+	if len(rows) > MaxKeyboardRows {
+		return invalid("buttons", "has %d rows, limit is %d", len(rows), MaxKeyboardRows)
+	}
+
+	total := 0
+	for i, row := range rows {
+		total += len(row)
+
+		limit := MaxButtonsPerRow
+		for _, b := range row {
+			if isWideButton(b.Type) {
+				limit = MaxWideButtonsPerRow
+				break
+			}
+		}
+		if len(row) == 0 {
+			return invalid(fmt.Sprintf("buttons[%d]", i), "row is empty")
+		}
+		if len(row) > limit {
+			return invalid(fmt.Sprintf("buttons[%d]", i), "has %d buttons, limit is %d", len(row), limit)
+		}
+
+		for j, b := range row {
+			if err := b.validate(); err != nil {
+				return prefixField(err, fmt.Sprintf("buttons[%d][%d]", i, j))
+			}
+		}
+	}
+	if total > MaxKeyboardButtons {
+		return invalid("buttons", "has %d buttons, limit is %d", total, MaxKeyboardButtons)
+	}
+	return nil
+}
+
+func (b Button) validate() *Error {
+	if b.Text == "" {
+		return invalid("text", "must not be empty")
+	}
+	if n := textLength(b.Text); n > MaxButtonTextLength {
+		return invalid("text", "is %d characters, limit is %d", n, MaxButtonTextLength)
+	}
+
+	switch b.Type {
+	case "callback":
+		if b.Payload == "" {
+			return invalid("payload", "must not be empty for callback buttons")
+		}
+		if n := textLength(b.Payload); n > MaxCallbackPayloadLength {
+			return invalid("payload", "is %d characters, limit is %d", n, MaxCallbackPayloadLength)
+		}
+	case "link":
+		if b.URL == "" {
+			return invalid("url", "must not be empty for link buttons")
+		}
+		if u, err := url.Parse(b.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalid("url", "must be an absolute http or https URL")
+		}
+	case "chat":
+		if b.ChatTitle == "" {
+			return invalid("chat_title", "must not be empty for chat buttons")
+		}
+	case "open_app":
+		if b.WebApp == "" {
+			return invalid("web_app", "must not be empty for open_app buttons")
+		}
+	case "request_contact", "request_geo_location", "message":
+	case "":
+		return invalid("type", "must not be empty")
+	default:
+		return invalid("type", "unknown button type %q", b.Type)
+	}
+	return nil
+}
+
+func (a *CallbackAnswer) validate() *Error {
+	if a == nil {
+		return invalid("", "answer is required")
+	}
+	if a.Message == nil && !a.Notification.Set {
+		return invalid("", "either message or notification must be set")
+	}
+	if a.Notification.Set && a.Notification.Value == "" {
+		return invalid("notification", "must not be empty")
+	}
+	if err := a.Message.validate(false); err != nil {
+		return prefixField(err, "message")
+	}
+	return nil
+}
+
+func (p *ChatPatch) validate() *Error {
+	if p == nil || (p.Icon == nil && !p.Title.Set && !p.Pin.Set && !p.Notify.Set) {
+		return invalid("", "nothing to update")
+	}
+	if p.Title.Set && strings.TrimSpace(p.Title.Value) == "" {
+		return invalid("title", "must not be empty")
+	}
+	if p.Icon != nil && !p.Icon.URL.Set && !p.Icon.Token.Set && len(p.Icon.Photos) == 0 {
+		return invalid("icon", "must have url, token or photos")
+	}
+	return nil
+}
+
+func (p *BotPatch) validate() *Error {
+	if p == nil || (!p.Name.Set && !p.FirstName.Set && !p.Description.Set && p.Commands == nil && p.Photo == nil) {
+		return invalid("", "nothing to update")
+	}
+	if p.FirstName.Set && strings.TrimSpace(p.FirstName.Value) == "" {
+		return invalid("first_name", "must not be empty")
+	}
+	if n := textLength(p.Description.Value); n > maxBotDescriptionLength {
+		return invalid("description", "is %d characters, limit is %d", n, maxBotDescriptionLength)
+	}
+	if len(p.Commands) > maxBotCommands {
+		return invalid("commands", "has %d commands, limit is %d", len(p.Commands), maxBotCommands)
+	}
+	for i, cmd := range p.Commands {
+		if cmd.Name == "" {
+			return invalid(fmt.Sprintf("commands[%d].name", i), "must not be empty")
+		}
+	}
+	if p.Photo != nil && !p.Photo.URL.Set && !p.Photo.Token.Set && len(p.Photo.Photos) == 0 {
+		return invalid("photo", "must have url, token or photos")
+	}
+	return nil
+}
+
+func (l *ChatAdminsList) validate() *Error {
+	if l == nil || len(l.Admins) == 0 {
+		return invalid("admins", "must not be empty")
+	}
+	for i, admin := range l.Admins {
+		if admin.UserID == 0 {
+			return invalid(fmt.Sprintf("admins[%d].user_id", i), "must be set")
+		}
+		for j, perm := range admin.Permissions {
+			if !knownPermissions[perm] {
+				return invalid(fmt.Sprintf("admins[%d].permissions[%d]", i, j), "unknown permission %q", perm)
+			}
+		}
+	}
+	return nil
+}
+
+// invalid returns a validation error for field. Op is set by finishValidation.
+func invalid(field, format string, args ...any) *Error {
+	return validationError("", field, fmt.Sprintf(format, args...))
+}
+
+// prefixField prepends prefix to the error's field path.
+func prefixField(err *Error, prefix string) *Error {
+	switch {
+	case err.Field == "":
+		err.Field = prefix
+	case strings.HasPrefix(err.Field, "["):
+		err.Field = prefix + err.Field
+	default:
+		err.Field = prefix + "." + err.Field
+	}
+	return err
+}
+
+// finishValidation sets Op and converts a nil *Error into a nil error.
+func finishValidation(op string, err *Error) error {
+	if err == nil {
+		return nil
+	}
+	err.Op = op
+	return err
+}
+
+// validateRequest runs check when request validation is enabled via [WithValidation].
+func (c *Client) validateRequest(op string, check func() *Error) error {
+	if !c.validate {
+		return nil
+	}
+	return finishValidation(op, check())
+}
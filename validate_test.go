@@ -0,0 +1,223 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewMessageBodyValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      *NewMessageBody
+		wantField string // empty means valid
+	}{
+		{"text", &NewMessageBody{Text: Some("hi")}, ""},
+		{"attachment only", &NewMessageBody{Attachments: []AttachmentRequest{NewInlineKeyboardAttachment([][]Button{{NewCallbackButton("a", "b")}})}}, ""},
+		{"forward only", &NewMessageBody{Link: &NewMessageLink{Type: LinkForward, MID: "mid.1"}}, ""},
+		{"empty", &NewMessageBody{}, "text"},
+		{"reply only", &NewMessageBody{Link: &NewMessageLink{Type: LinkReply, MID: "mid.1"}}, "text"},
+		{"empty text", &NewMessageBody{Text: Some("")}, "text"},
+		{"too long", &NewMessageBody{Text: Some(strings.Repeat("x", MaxTextLength+1))}, "text"},
+		{"unknown format", &NewMessageBody{Text: Some("hi"), Format: Some(TextFormat("bbcode"))}, "format"},
+		{"empty link mid", &NewMessageBody{Text: Some("hi"), Link: &NewMessageLink{Type: LinkReply}}, "link.mid"},
+		{"attachment without type", &NewMessageBody{Attachments: []AttachmentRequest{{}}}, "attachments[0].type"},
+		{"attachment without payload", &NewMessageBody{Attachments: []AttachmentRequest{{Type: "image"}}}, "attachments[0].payload"},
+		{
+			"bad location",
+			&NewMessageBody{Attachments: []AttachmentRequest{NewLocationAttachment(91, 0)}},
+			"attachments[0].latitude",
+		},
+		{
+			"nested button",
+			&NewMessageBody{
+				Text: Some("pick"),
+				Attachments: []AttachmentRequest{
+					NewInlineKeyboardAttachment([][]Button{
+						{NewCallbackButton("a", "a")},
+						{NewCallbackButton("b", "b"), NewCallbackButton("c", "c"), NewLinkButton("d", "ftp://example.com")},
+					}),
+				},
+			},
+			"attachments[0].payload.buttons[1][2].url",
+		},
+		{
+			"keyboard row",
+			&NewMessageBody{Attachments: []AttachmentRequest{NewInlineKeyboardAttachment([][]Button{make([]Button, MaxButtonsPerRow+1)})}},
+			"attachments[0].payload.buttons[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkValidation(t, tt.body.Validate(), tt.wantField)
+		})
+	}
+
+	var nilBody *NewMessageBody
+	if err := nilBody.Validate(); err == nil {
+		t.Error("expected error for nil body")
+	}
+}
+
+func TestButtonValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		button    Button
+		wantField string
+	}{
+		{"callback", NewCallbackButton("Yes", "yes"), ""},
+		{"link", NewLinkButton("Site", "https://example.com"), ""},
+		{"contact", NewRequestContactButton("Share"), ""},
+		{"empty text", NewCallbackButton("", "yes"), "text"},
+		{"long text", NewCallbackButton(strings.Repeat("x", MaxButtonTextLength+1), "yes"), "text"},
+		{"empty payload", NewCallbackButton("Yes", ""), "payload"},
+		{"long payload", NewCallbackButton("Yes", strings.Repeat("p", MaxCallbackPayloadLength+1)), "payload"},
+		{"empty url", NewLinkButton("Site", ""), "url"},
+		{"non-http url", NewLinkButton("Site", "tg://resolve"), "url"},
+		{"relative url", NewLinkButton("Site", "/path"), "url"},
+		{"chat without title", Button{Type: "chat", Text: "Chat"}, "chat_title"},
+		{"app without web app", Button{Type: "open_app", Text: "App"}, "web_app"},
+		{"no type", Button{Text: "x"}, "type"},
+		{"unknown type", Button{Type: "teleport", Text: "x"}, "type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkValidation(t, tt.button.Validate(), tt.wantField)
+		})
+	}
+}
+
+func TestCallbackAnswerValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		answer    *CallbackAnswer
+		wantField string
+	}{
+		{"notification", &CallbackAnswer{Notification: Some("Done")}, ""},
+		{"message", &CallbackAnswer{Message: &NewMessageBody{Text: Some("Updated")}}, ""},
+		{"edit keeps attachments", &CallbackAnswer{Message: &NewMessageBody{}}, ""},
+		{"empty notification", &CallbackAnswer{Notification: Some("")}, "notification"},
+		{"invalid message", &CallbackAnswer{Message: &NewMessageBody{Text: Some(strings.Repeat("x", MaxTextLength+1))}}, "message.text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkValidation(t, tt.answer.Validate(), tt.wantField)
+		})
+	}
+
+	var e *Error
+	if err := (&CallbackAnswer{}).Validate(); !errors.As(err, &e) || e.Kind != ErrValidation {
+		t.Errorf("err = %v, want validation error for empty answer", err)
+	}
+}
+
+func TestPatchValidate(t *testing.T) {
+	t.Run("chat patch", func(t *testing.T) {
+		checkValidation(t, (&ChatPatch{Title: Some("New")}).Validate(), "")
+		checkValidation(t, (&ChatPatch{Title: Some(" ")}).Validate(), "title")
+		checkValidation(t, (&ChatPatch{Icon: &PhotoAttachmentRequestPayload{}}).Validate(), "icon")
+
+		err := (&ChatPatch{}).Validate()
+		var e *Error
+		if !errors.As(err, &e) || e.Message != "nothing to update" {
+			t.Errorf("err = %v, want nothing to update", err)
+		}
+	})
+
+	t.Run("bot patch", func(t *testing.T) {
+		checkValidation(t, (&BotPatch{FirstName: Some("Bot")}).Validate(), "")
+		checkValidation(t, (&BotPatch{Commands: []BotCommand{}}).Validate(), "")
+		checkValidation(t, (&BotPatch{FirstName: Some("")}).Validate(), "first_name")
+		checkValidation(t, (&BotPatch{Description: Some(strings.Repeat("x", maxBotDescriptionLength+1))}).Validate(), "description")
+		checkValidation(t, (&BotPatch{Commands: make([]BotCommand, maxBotCommands+1)}).Validate(), "commands")
+		checkValidation(t, (&BotPatch{Commands: []BotCommand{{Name: "start"}, {}}}).Validate(), "commands[1].name")
+
+		if err := (&BotPatch{}).Validate(); err == nil {
+			t.Error("expected error for empty patch")
+		}
+	})
+
+	t.Run("admins", func(t *testing.T) {
+		checkValidation(t, (&ChatAdminsList{Admins: []ChatAdmin{{UserID: 1, Permissions: []ChatAdminPermission{PermWrite}}}}).Validate(), "")
+		checkValidation(t, (&ChatAdminsList{}).Validate(), "admins")
+		checkValidation(t, (&ChatAdminsList{Admins: []ChatAdmin{{UserID: 1}, {}}}).Validate(), "admins[1].user_id")
+		checkValidation(t, (&ChatAdminsList{Admins: []ChatAdmin{{UserID: 1, Permissions: []ChatAdminPermission{PermWrite, "fly"}}}}).Validate(), "admins[0].permissions[1]")
+	})
+}
+
+func TestWithValidation(t *testing.T) {
+	t.Run("rejects before sending", func(t *testing.T) {
+		c, _ := testClientWithOpts(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("request should not be sent")
+		}, WithValidation())
+
+		_, err := c.SendMessage(context.Background(), 1, &NewMessageBody{})
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("expected *Error, got %T", err)
+		}
+		if e.Kind != ErrValidation || e.Op != "SendMessage" || e.Field != "text" {
+			t.Errorf("err = %+v, want validation error for SendMessage text", e)
+		}
+
+		_, err = c.EditChat(context.Background(), 1, &ChatPatch{})
+		if !errors.As(err, &e) || e.Op != "EditChat" {
+			t.Errorf("err = %v, want EditChat validation error", err)
+		}
+	})
+
+	t.Run("edit allows empty body", func(t *testing.T) {
+		c, _ := testClientWithOpts(t, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, SimpleQueryResult{Success: true})
+		}, WithValidation())
+
+		if _, err := c.EditMessage(context.Background(), "mid", &NewMessageBody{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		sent := false
+		c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+			sent = true
+			writeJSON(t, w, sendMessageResult{})
+		})
+
+		if _, err := c.SendMessage(context.Background(), 1, &NewMessageBody{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !sent {
+			t.Error("request was not sent")
+		}
+	})
+}
+
+// checkValidation asserts that err is nil when wantField is empty, or a
+// validation error for wantField otherwise.
+func checkValidation(t *testing.T, err error, wantField string) {
+	t.Helper()
+	if wantField == "" {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, got %T (%v)", err, err)
+	}
+	if e.Kind != ErrValidation {
+		t.Errorf("Kind = %v, want ErrValidation", e.Kind)
+	}
+	if e.Field != wantField {
+		t.Errorf("Field = %q, want %q (%v)", e.Field, wantField, err)
+	}
+	if e.Op != "Validate" {
+		t.Errorf("Op = %q, want Validate", e.Op)
+	}
+}
@@ -0,0 +1,498 @@
+package maxigo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Widgets are inline keyboards that keep their state in callback payloads.
+// Each widget renders its keyboard with a Keyboard method and processes
+// presses with Handle. Handle answers navigation presses itself by replacing
+// the keyboard via [Client.AnswerCallback], and answers presses on
+// informational buttons (page indicator, calendar header, blank days) by
+// re-sending the keyboard unchanged. When it returns a final value (done is
+// true), the callback is left unanswered so that the caller can answer it,
+// e.g. with a notification or a new message.
+//
+// Widget callbacks are routed by ID: use Match to check whether a callback
+// belongs to the widget. Set Key to sign payloads (see [CallbackCodecOpts.Key]).
+//
+// When a keyboard is replaced, the message text is re-sent as markdown
+// rendered from its markup; other attachments of the message are removed.
+
+// widgetState is the payload of every widget button.
+type widgetState struct {
+	Op  string `json:"o"`
+	N   int    `json:"n,omitempty"`
+	Sel []byte `json:"s,omitempty"`
+}
+
+// Widget operations.
+const (
+	widgetNoop   = "x" // informational button, keyboard re-sent as is
+	widgetPage   = "p" // show page N
+	widgetItem   = "i" // item N selected
+	widgetToggle = "t" // toggle option N
+	widgetDone   = "d" // finish selection
+	widgetYes    = "y"
+	widgetNo     = "n"
+	widgetMonth  = "m" // show month N (months since year 0)
+	widgetDay    = "s" // day N selected (YYYYMMDD)
+)
+
+func widgetCodec(id string, key []byte) *CallbackCodec[widgetState] {
+	return NewCallbackCodec[widgetState](id, CallbackCodecOpts{Key: key})
+}
+
+func widgetMatch(id string, upd *MessageCallbackUpdate) bool {
+	return upd != nil && widgetCodec(id, nil).Match(upd.Callback.Payload)
+}
+
+// updateWidget replaces the keyboard of the message the callback came from.
+func (c *Client) updateWidget(ctx context.Context, upd *MessageCallbackUpdate, rows [][]Button) error {
+	body := &NewMessageBody{Attachments: []AttachmentRequest{NewInlineKeyboardAttachment(rows)}}
+	if upd.Message != nil && upd.Message.Body.Text != nil {
+		body.Text = Some(upd.Message.Body.Render(FormatMarkdown))
+		body.Format = Some(FormatMarkdown)
+	}
+	_, err := c.AnswerCallback(ctx, upd.Callback.CallbackID, &CallbackAnswer{Message: body})
+	return err
+}
+
+// answerNoop answers a press on an informational button by re-sending the
+// keyboard of the message unchanged, which stops the loading indicator in
+// the Max client.
+func (c *Client) answerNoop(ctx context.Context, upd *MessageCallbackUpdate) error {
+	if upd.Message != nil {
+		atts, err := upd.Message.Body.ParseAttachments()
+		if err != nil {
+			return err
+		}
+		for _, att := range atts {
+			if kb, ok := att.(*InlineKeyboardAttachment); ok {
+				return c.updateWidget(ctx, upd, kb.Payload.Buttons)
+			}
+		}
+	}
+	return fmt.Errorf("%w: callback message has no keyboard", ErrInvalidPayload)
+}
+
+// PagedList is a list of selectable items shown page by page with
+// previous/next buttons.
+type PagedList struct {
+	// ID identifies the widget in callback payloads.
+	ID string
+	// Items are the button labels.
+	Items []string
+	// PageSize is the number of items per page (default 5).
+	PageSize int
+	// Columns is the number of item buttons per row (default 1).
+	Columns int
+	// PrevText and NextText label the navigation buttons (default "‹" and "›").
+	PrevText, NextText string
+	// Key signs payloads. Nil disables signing.
+	Key []byte
+}
+
+// Pages returns the number of pages.
+func (l *PagedList) Pages() int {
+	size := l.pageSize()
+	return max((len(l.Items)+size-1)/size, 1)
+}
+
+// Keyboard renders the given page (0-based). Out-of-range pages are clamped.
+func (l *PagedList) Keyboard(page int) ([][]Button, error) {
+	codec := widgetCodec(l.ID, l.Key)
+	size := l.pageSize()
+	pages := l.Pages()
+	page = min(max(page, 0), pages-1)
+
+	kb := NewKeyboardBuilder().Columns(max(l.Columns, 1))
+	for i := page * size; i < min((page+1)*size, len(l.Items)); i++ {
+		b, err := codec.Button(l.Items[i], widgetState{Op: widgetItem, N: i})
+		if err != nil {
+			return nil, err
+		}
+		kb.Add(b)
+	}
+
+	if pages > 1 {
+		var nav []Button
+		if page > 0 {
+			b, err := codec.Button(cmp.Or(l.PrevText, "‹"), widgetState{Op: widgetPage, N: page - 1})
+			if err != nil {
+				return nil, err
+			}
+			nav = append(nav, b)
+		}
+		b, err := codec.Button(fmt.Sprintf("%d/%d", page+1, pages), widgetState{Op: widgetNoop})
+		if err != nil {
+			return nil, err
+		}
+		nav = append(nav, b)
+		if page < pages-1 {
+			b, err := codec.Button(cmp.Or(l.NextText, "›"), widgetState{Op: widgetPage, N: page + 1})
+			if err != nil {
+				return nil, err
+			}
+			nav = append(nav, b)
+		}
+		kb.Row(nav...)
+	}
+	return kb.Buttons(), nil
+}
+
+// Match reports whether the callback belongs to this list.
+func (l *PagedList) Match(upd *MessageCallbackUpdate) bool {
+	return widgetMatch(l.ID, upd)
+}
+
+// Handle processes a button press. Page buttons update the message and
+// return done == false. When an item is pressed, its index is returned with
+// done == true.
+func (l *PagedList) Handle(ctx context.Context, c *Client, upd *MessageCallbackUpdate) (index int, done bool, err error) {
+	state, err := widgetCodec(l.ID, l.Key).DecodeUpdate(upd)
+	if err != nil {
+		return -1, false, err
+	}
+	switch state.Op {
+	case widgetPage:
+		rows, err := l.Keyboard(state.N)
+		if err != nil {
+			return -1, false, err
+		}
+		return -1, false, c.updateWidget(ctx, upd, rows)
+	case widgetItem:
+		if state.N < 0 || state.N >= len(l.Items) {
+			return -1, false, fmt.Errorf("%w: item %d out of range", ErrInvalidPayload, state.N)
+		}
+		return state.N, true, nil
+	case widgetNoop:
+		return -1, false, c.answerNoop(ctx, upd)
+	}
+	return -1, false, fmt.Errorf("%w: unknown operation %q", ErrInvalidPayload, state.Op)
+}
+
+func (l *PagedList) pageSize() int {
+	if l.PageSize <= 0 {
+		return 5
+	}
+	return l.PageSize
+}
+
+// Checklist is a multi-select list of options with a done button.
+type Checklist struct {
+	// ID identifies the widget in callback payloads.
+	ID string
+	// Options are the option labels.
+	Options []string
+	// Columns is the number of option buttons per row (default 1).
+	Columns int
+	// Checked and Unchecked are prepended to option labels (default "☑ " and "☐ ").
+	Checked, Unchecked string
+	// DoneText labels the done button (default "Done").
+	DoneText string
+	// Key signs payloads. Nil disables signing.
+	Key []byte
+}
+
+// Keyboard renders the checklist with the given options (indexes) checked.
+func (l *Checklist) Keyboard(selected []int) ([][]Button, error) {
+	return l.keyboard(l.mask(selected))
+}
+
+// Match reports whether the callback belongs to this checklist.
+func (l *Checklist) Match(upd *MessageCallbackUpdate) bool {
+	return widgetMatch(l.ID, upd)
+}
+
+// Handle processes a button press. Toggling an option updates the message
+// and returns the new selection with done == false. The done button returns
+// the final selection with done == true. Selections are sorted indexes.
+func (l *Checklist) Handle(ctx context.Context, c *Client, upd *MessageCallbackUpdate) (selected []int, done bool, err error) {
+	state, err := widgetCodec(l.ID, l.Key).DecodeUpdate(upd)
+	if err != nil {
+		return nil, false, err
+	}
+	mask := l.mask(nil)
+	copy(mask, state.Sel)
+
+	switch state.Op {
+	case widgetToggle:
+		if state.N < 0 || state.N >= len(l.Options) {
+			return nil, false, fmt.Errorf("%w: option %d out of range", ErrInvalidPayload, state.N)
+		}
+		mask[state.N/8] ^= 1 << (state.N % 8)
+		rows, err := l.keyboard(mask)
+		if err != nil {
+			return nil, false, err
+		}
+		return l.indexes(mask), false, c.updateWidget(ctx, upd, rows)
+	case widgetDone:
+		return l.indexes(mask), true, nil
+	case widgetNoop:
+		return l.indexes(mask), false, c.answerNoop(ctx, upd)
+	}
+	return nil, false, fmt.Errorf("%w: unknown operation %q", ErrInvalidPayload, state.Op)
+}
+
+func (l *Checklist) keyboard(mask []byte) ([][]Button, error) {
+	codec := widgetCodec(l.ID, l.Key)
+	kb := NewKeyboardBuilder().Columns(max(l.Columns, 1))
+	for i, opt := range l.Options {
+		mark := cmp.Or(l.Unchecked, "☐ ")
+		if mask[i/8]&(1<<(i%8)) != 0 {
+			mark = cmp.Or(l.Checked, "☑ ")
+		}
+		b, err := codec.Button(mark+opt, widgetState{Op: widgetToggle, N: i, Sel: mask})
+		if err != nil {
+			return nil, err
+		}
+		kb.Add(b)
+	}
+	done, err := codec.Button(cmp.Or(l.DoneText, "Done"), widgetState{Op: widgetDone, Sel: mask})
+	if err != nil {
+		return nil, err
+	}
+	return kb.Row(done).Buttons(), nil
+}
+
+// mask converts option indexes to a bit set. Out-of-range indexes are ignored.
+func (l *Checklist) mask(selected []int) []byte {
+	mask := make([]byte, (len(l.Options)+7)/8)
+	for _, i := range selected {
+		if i >= 0 && i < len(l.Options) {
+			mask[i/8] |= 1 << (i % 8)
+		}
+	}
+	return mask
+}
+
+func (l *Checklist) indexes(mask []byte) []int {
+	selected := []int{}
+	for i := range l.Options {
+		if mask[i/8]&(1<<(i%8)) != 0 {
+			selected = append(selected, i)
+		}
+	}
+	return selected
+}
+
+// Confirmation is a yes/no prompt.
+type Confirmation struct {
+	// ID identifies the widget in callback payloads.
+	ID string
+	// YesText and NoText label the buttons (default "Yes" and "No").
+	YesText, NoText string
+	// Key signs payloads. Nil disables signing.
+	Key []byte
+}
+
+// Keyboard renders the prompt as one row with positive and negative buttons.
+func (p *Confirmation) Keyboard() ([][]Button, error) {
+	codec := widgetCodec(p.ID, p.Key)
+	yes, err := codec.Button(cmp.Or(p.YesText, "Yes"), widgetState{Op: widgetYes})
+	if err != nil {
+		return nil, err
+	}
+	no, err := codec.Button(cmp.Or(p.NoText, "No"), widgetState{Op: widgetNo})
+	if err != nil {
+		return nil, err
+	}
+	yes.Intent = IntentPositive
+	no.Intent = IntentNegative
+	return [][]Button{{yes, no}}, nil
+}
+
+// Match reports whether the callback belongs to this prompt.
+func (p *Confirmation) Match(upd *MessageCallbackUpdate) bool {
+	return widgetMatch(p.ID, upd)
+}
+
+// Handle returns the user's answer. The callback is not answered.
+func (p *Confirmation) Handle(ctx context.Context, c *Client, upd *MessageCallbackUpdate) (confirmed bool, err error) {
+	state, err := widgetCodec(p.ID, p.Key).DecodeUpdate(upd)
+	if err != nil {
+		return false, err
+	}
+	switch state.Op {
+	case widgetYes:
+		return true, nil
+	case widgetNo:
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: unknown operation %q", ErrInvalidPayload, state.Op)
+}
+
+// DatePicker is a month calendar. Weeks start on Monday.
+type DatePicker struct {
+	// ID identifies the widget in callback payloads.
+	ID string
+	// Min and Max limit selectable dates (inclusive). Zero means unbounded.
+	Min, Max time.Time
+	// Location is used for returned dates (default UTC).
+	Location *time.Location
+	// MonthNames overrides English month names (12 entries, January first).
+	MonthNames []string
+	// WeekdayNames overrides the weekday header (7 entries, Monday first).
+	WeekdayNames []string
+	// Key signs payloads. Nil disables signing.
+	Key []byte
+}
+
+var defaultWeekdayNames = []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+
+// Keyboard renders the month containing the given date.
+func (d *DatePicker) Keyboard(month time.Time) ([][]Button, error) {
+	codec := widgetCodec(d.ID, d.Key)
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, d.location())
+	index := first.Year()*12 + int(first.Month()) - 1
+
+	noop := func(text string) (Button, error) {
+		return codec.Button(text, widgetState{Op: widgetNoop})
+	}
+
+	var rows [][]Button
+
+	var header []Button
+	if d.Min.IsZero() || first.After(d.dayOf(d.Min)) {
+		b, err := codec.Button("‹", widgetState{Op: widgetMonth, N: index - 1})
+		if err != nil {
+			return nil, err
+		}
+		header = append(header, b)
+	}
+	title, err := noop(d.monthName(first.Month()) + " " + strconv.Itoa(first.Year()))
+	if err != nil {
+		return nil, err
+	}
+	header = append(header, title)
+	next := first.AddDate(0, 1, 0)
+	if d.Max.IsZero() || !next.After(d.dayOf(d.Max)) {
+		b, err := codec.Button("›", widgetState{Op: widgetMonth, N: index + 1})
+		if err != nil {
+			return nil, err
+		}
+		header = append(header, b)
+	}
+	rows = append(rows, header)
+
+	weekdays := d.WeekdayNames
+	if len(weekdays) != 7 {
+		weekdays = defaultWeekdayNames
+	}
+	var names []Button
+	for _, name := range weekdays {
+		b, err := noop(name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, b)
+	}
+	rows = append(rows, names)
+
+	offset := (int(first.Weekday()) + 6) % 7 // Monday is 0
+	var week []Button
+	for i := 0; i < offset; i++ {
+		b, err := noop(" ")
+		if err != nil {
+			return nil, err
+		}
+		week = append(week, b)
+	}
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		var b Button
+		var err error
+		if d.allowed(day) {
+			n := day.Year()*10000 + int(day.Month())*100 + day.Day()
+			b, err = codec.Button(strconv.Itoa(day.Day()), widgetState{Op: widgetDay, N: n})
+		} else {
+			b, err = noop("·")
+		}
+		if err != nil {
+			return nil, err
+		}
+		week = append(week, b)
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = nil
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			b, err := noop(" ")
+			if err != nil {
+				return nil, err
+			}
+			week = append(week, b)
+		}
+		rows = append(rows, week)
+	}
+	return rows, nil
+}
+
+// Match reports whether the callback belongs to this date picker.
+func (d *DatePicker) Match(upd *MessageCallbackUpdate) bool {
+	return widgetMatch(d.ID, upd)
+}
+
+// Handle processes a button press. Month navigation updates the message and
+// returns done == false. When a day is pressed, the date (midnight in
+// [DatePicker.Location]) is returned with done == true.
+func (d *DatePicker) Handle(ctx context.Context, c *Client, upd *MessageCallbackUpdate) (date time.Time, done bool, err error) {
+	state, err := widgetCodec(d.ID, d.Key).DecodeUpdate(upd)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	switch state.Op {
+	case widgetMonth:
+		month := time.Date(state.N/12, time.Month(state.N%12+1), 1, 0, 0, 0, 0, d.location())
+		rows, err := d.Keyboard(month)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return time.Time{}, false, c.updateWidget(ctx, upd, rows)
+	case widgetDay:
+		date := time.Date(state.N/10000, time.Month(state.N/100%100), state.N%100, 0, 0, 0, 0, d.location())
+		if !d.allowed(date) {
+			return time.Time{}, false, fmt.Errorf("%w: date %s out of range", ErrInvalidPayload, date.Format(time.DateOnly))
+		}
+		return date, true, nil
+	case widgetNoop:
+		return time.Time{}, false, c.answerNoop(ctx, upd)
+	}
+	return time.Time{}, false, fmt.Errorf("%w: unknown operation %q", ErrInvalidPayload, state.Op)
+}
+
+func (d *DatePicker) location() *time.Location {
+	if d.Location == nil {
+		return time.UTC
+	}
+	return d.Location
+}
+
+// dayOf returns midnight of t's date in the picker's location.
+func (d *DatePicker) dayOf(t time.Time) time.Time {
+	t = t.In(d.location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, d.location())
+}
+
+func (d *DatePicker) allowed(day time.Time) bool {
+	if !d.Min.IsZero() && day.Before(d.dayOf(d.Min)) {
+		return false
+	}
+	if !d.Max.IsZero() && day.After(d.dayOf(d.Max)) {
+		return false
+	}
+	return true
+}
+
+func (d *DatePicker) monthName(m time.Month) string {
+	if len(d.MonthNames) == 12 {
+		return d.MonthNames[m-1]
+	}
+	return m.String()
+}
@@ -0,0 +1,119 @@
+package maxigo_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/maxigo-bot/maxigo-client"
+	"github.com/maxigo-bot/maxigo-client/maxigotest"
+)
+
+// pressNoop sends rows to a user of a fake server, presses the button at
+// rows[i][j] as that user and returns the received callback update.
+func pressNoop(t *testing.T, rows [][]maxigo.Button, i, j int) (*maxigotest.Server, *maxigo.Client, *maxigo.MessageCallbackUpdate) {
+	t.Helper()
+	srv := maxigotest.NewServer()
+	t.Cleanup(srv.Close)
+	c := srv.Client(maxigo.WithValidation())
+	ctx := context.Background()
+	alice := srv.As(srv.AddUser("Alice").UserID)
+
+	msg, err := c.SendMessageToUser(ctx, alice.User().UserID, &maxigo.NewMessageBody{
+		Text:        maxigo.Some("Pick one"),
+		Attachments: []maxigo.AttachmentRequest{maxigo.NewInlineKeyboardAttachment(rows)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alice.PressButton(msg.Body.MID, rows[i][j].Payload); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.GetUpdates(ctx, maxigo.GetUpdatesOpts{Timeout: 1})
+	if err != nil || len(list.Updates) != 1 {
+		t.Fatalf("GetUpdates = %+v, %v", list, err)
+	}
+	var upd maxigo.MessageCallbackUpdate
+	if err := json.Unmarshal(list.Updates[0], &upd); err != nil {
+		t.Fatal(err)
+	}
+	return srv, c, &upd
+}
+
+// checkNoopAnswer checks that the callback was answered with the keyboard
+// unchanged.
+func checkNoopAnswer(t *testing.T, srv *maxigotest.Server, rows [][]maxigo.Button) {
+	t.Helper()
+	answers := srv.Answers()
+	if len(answers) != 1 || answers[0].Answer.Message == nil {
+		t.Fatalf("answers = %+v, want one keyboard answer", answers)
+	}
+	atts := answers[0].Answer.Message.Attachments
+	if len(atts) != 1 {
+		t.Fatalf("answer attachments = %d, want 1", len(atts))
+	}
+	kb, ok := atts[0].Payload.(maxigo.Keyboard)
+	if !ok || !slices.EqualFunc(kb.Buttons, rows, slices.Equal) {
+		t.Errorf("answer keyboard = %+v, want unchanged", atts[0].Payload)
+	}
+}
+
+func TestPagedListNoopServer(t *testing.T) {
+	list := &maxigo.PagedList{ID: "fruit", Items: []string{"apple", "banana", "cherry"}, PageSize: 2}
+	rows, err := list.Keyboard(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, c, upd := pressNoop(t, rows, 2, 0) // page indicator "1/2"
+
+	index, done, err := list.Handle(context.Background(), c, upd)
+	if err != nil || done || index != -1 {
+		t.Fatalf("Handle = %d, %v, %v, want -1, false, nil", index, done, err)
+	}
+	checkNoopAnswer(t, srv, rows)
+}
+
+func TestChecklistNoopServer(t *testing.T) {
+	list := &maxigo.Checklist{ID: "tags", Options: []string{"a", "b"}}
+	rows, err := list.Keyboard([]int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Checklists have no informational buttons; forge one with the
+	// widget payload format.
+	codec := maxigo.NewCallbackCodec[struct {
+		Op string `json:"o"`
+	}]("tags", maxigo.CallbackCodecOpts{})
+	info, err := codec.Button("Tags", struct {
+		Op string `json:"o"`
+	}{"x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows = append([][]maxigo.Button{{info}}, rows...)
+	srv, c, upd := pressNoop(t, rows, 0, 0)
+
+	selected, done, err := list.Handle(context.Background(), c, upd)
+	if err != nil || done || len(selected) != 0 {
+		t.Fatalf("Handle = %v, %v, %v, want none, false, nil", selected, done, err)
+	}
+	checkNoopAnswer(t, srv, rows)
+}
+
+func TestDatePickerNoopServer(t *testing.T) {
+	picker := &maxigo.DatePicker{ID: "date"}
+	rows, err := picker.Keyboard(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pos := range [][2]int{{0, 1}, {2, 0}} { // month header, blank day
+		srv, c, upd := pressNoop(t, rows, pos[0], pos[1])
+		date, done, err := picker.Handle(context.Background(), c, upd)
+		if err != nil || done || !date.IsZero() {
+			t.Fatalf("Handle %v = %v, %v, %v, want zero, false, nil", pos, date, done, err)
+		}
+		checkNoopAnswer(t, srv, rows)
+	}
+}
@@ -0,0 +1,196 @@
+package maxigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// widgetAnswer is the part of a callback answer that widgets send.
+type widgetAnswer struct {
+	Message struct {
+		Text        string `json:"text"`
+		Format      string `json:"format"`
+		Attachments []struct {
+			Type    string   `json:"type"`
+			Payload Keyboard `json:"payload"`
+		} `json:"attachments"`
+	} `json:"message"`
+}
+
+// widgetServer records keyboards sent via AnswerCallback.
+func widgetServer(t *testing.T) (*Client, *[][][]Button) {
+	t.Helper()
+	var sent [][][]Button
+	c, _ := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/answers" {
+			t.Errorf("path = %q, want /answers", r.URL.Path)
+		}
+		var answer widgetAnswer
+		readJSON(t, r, &answer)
+		if answer.Message.Text != "Pick \\*one\\*" || answer.Message.Format != "markdown" {
+			t.Errorf("text = %q (%s), want escaped original text", answer.Message.Text, answer.Message.Format)
+		}
+		if len(answer.Message.Attachments) != 1 {
+			t.Fatalf("attachments = %d, want 1", len(answer.Message.Attachments))
+		}
+		sent = append(sent, answer.Message.Attachments[0].Payload.Buttons)
+		writeJSON(t, w, SimpleQueryResult{Success: true})
+	})
+	return c, &sent
+}
+
+// press returns the callback update for pressing rows[i][j].
+func press(rows [][]Button, i, j int) *MessageCallbackUpdate {
+	return &MessageCallbackUpdate{
+		Callback: Callback{CallbackID: "cb", Payload: rows[i][j].Payload},
+		Message:  &Message{Body: MessageBody{Text: strPtr("Pick *one*")}},
+	}
+}
+
+func buttonTexts(rows [][]Button) [][]string {
+	texts := make([][]string, len(rows))
+	for i, row := range rows {
+		for _, b := range row {
+			texts[i] = append(texts[i], b.Text)
+		}
+	}
+	return texts
+}
+
+func TestPagedList(t *testing.T) {
+	c, sent := widgetServer(t)
+	list := &PagedList{ID: "fruit", Items: []string{"apple", "banana", "cherry", "date", "elder"}, PageSize: 2, Key: []byte("k")}
+
+	rows, err := list.Keyboard(0)
+	if err != nil {
+		t.Fatalf("Keyboard: %v", err)
+	}
+	want := [][]string{{"apple"}, {"banana"}, {"1/3", "›"}}
+	if got := buttonTexts(rows); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("page 0 = %v, want %v", got, want)
+	}
+
+	upd := press(rows, 2, 1)
+	if !list.Match(upd) {
+		t.Fatal("Match = false")
+	}
+	if _, done, err := list.Handle(context.Background(), c, upd); err != nil || done {
+		t.Fatalf("next: done = %v, err = %v", done, err)
+	}
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d keyboards, want 1", len(*sent))
+	}
+	rows = (*sent)[0]
+	want = [][]string{{"cherry"}, {"date"}, {"‹", "2/3", "›"}}
+	if got := buttonTexts(rows); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("page 1 = %v, want %v", got, want)
+	}
+
+	index, done, err := list.Handle(context.Background(), c, press(rows, 1, 0))
+	if err != nil || !done || index != 3 {
+		t.Errorf("select = %d, %v, %v, want 3, true, nil", index, done, err)
+	}
+	if len(*sent) != 1 {
+		t.Error("final selection should not answer the callback")
+	}
+}
+
+func TestChecklist(t *testing.T) {
+	c, sent := widgetServer(t)
+	list := &Checklist{ID: "tags", Options: []string{"a", "b", "c"}}
+
+	rows, err := list.Keyboard([]int{2})
+	if err != nil {
+		t.Fatalf("Keyboard: %v", err)
+	}
+	want := [][]string{{"☐ a"}, {"☐ b"}, {"☑ c"}, {"Done"}}
+	if got := buttonTexts(rows); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("keyboard = %v, want %v", got, want)
+	}
+
+	selected, done, err := list.Handle(context.Background(), c, press(rows, 0, 0))
+	if err != nil || done || !slices.Equal(selected, []int{0, 2}) {
+		t.Fatalf("toggle = %v, %v, %v, want [0 2], false, nil", selected, done, err)
+	}
+	rows = (*sent)[0]
+	if rows[0][0].Text != "☑ a" {
+		t.Errorf("toggled text = %q, want ☑ a", rows[0][0].Text)
+	}
+
+	selected, done, err = list.Handle(context.Background(), c, press(rows, 3, 0))
+	if err != nil || !done || !slices.Equal(selected, []int{0, 2}) {
+		t.Errorf("done = %v, %v, %v, want [0 2], true, nil", selected, done, err)
+	}
+}
+
+func TestConfirmation(t *testing.T) {
+	p := &Confirmation{ID: "del", YesText: "Delete"}
+	rows, err := p.Keyboard()
+	if err != nil {
+		t.Fatalf("Keyboard: %v", err)
+	}
+	if rows[0][0].Text != "Delete" || rows[0][0].Intent != IntentPositive || rows[0][1].Text != "No" {
+		t.Errorf("keyboard = %+v", rows)
+	}
+
+	for j, want := range []bool{true, false} {
+		got, err := p.Handle(context.Background(), nil, press(rows, 0, j))
+		if err != nil || got != want {
+			t.Errorf("button %d = %v, %v, want %v", j, got, err, want)
+		}
+	}
+
+	other := &Confirmation{ID: "ban"}
+	if other.Match(press(rows, 0, 0)) {
+		t.Error("Match = true for another widget")
+	}
+	if _, err := other.Handle(context.Background(), nil, press(rows, 0, 0)); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("err = %v, want ErrInvalidPayload", err)
+	}
+}
+
+func TestDatePicker(t *testing.T) {
+	c, sent := widgetServer(t)
+	picker := &DatePicker{
+		ID:  "date",
+		Min: time.Date(2026, 5, 10, 15, 0, 0, 0, time.UTC),
+		Max: time.Date(2026, 6, 20, 0, 0, 0, 0, time.UTC),
+	}
+
+	rows, err := picker.Keyboard(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Keyboard: %v", err)
+	}
+	// May 2026 starts on Friday: header, weekdays and 5 weeks.
+	if len(rows) != 7 {
+		t.Fatalf("rows = %d, want 7", len(rows))
+	}
+	if got := buttonTexts(rows[:1]); !slices.Equal(got[0], []string{"May 2026", "›"}) {
+		t.Errorf("header = %v, want no previous month before Min", got[0])
+	}
+	week := buttonTexts(rows[2:3])[0]
+	if !slices.Equal(week, []string{" ", " ", " ", " ", "·", "·", "·"}) {
+		t.Errorf("first week = %v", week)
+	}
+	if err := (&NewMessageBody{Attachments: []AttachmentRequest{NewInlineKeyboardAttachment(rows)}}).Validate(); err != nil {
+		t.Errorf("keyboard is invalid: %v", err)
+	}
+
+	if _, done, err := picker.Handle(context.Background(), c, press(rows, 0, 1)); err != nil || done {
+		t.Fatalf("next month: done = %v, err = %v", done, err)
+	}
+	june := (*sent)[0]
+	if got := buttonTexts(june[:1]); !slices.Equal(got[0], []string{"‹", "June 2026"}) {
+		t.Errorf("header = %v, want no next month after Max", got[0])
+	}
+
+	// June 1, 2026 is a Monday.
+	date, done, err := picker.Handle(context.Background(), c, press(june, 2, 0))
+	if err != nil || !done || !date.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("select = %v, %v, %v, want 2026-06-01", date, done, err)
+	}
+}